@@ -0,0 +1,292 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+var (
+	lastScrapeTimestampOpts = prometheus.GaugeOpts{
+		Namespace: "mysql",
+		Subsystem: "exporter",
+		Name:      "last_scrape_timestamp_seconds",
+		Help:      "Timestamp of the last completed background scrape of the target.",
+	}
+	// staleUpOpts describes the mysql_up metric served instead of the
+	// scraped metrics once they exceeded the staleness limit.
+	staleUpOpts = prometheus.GaugeOpts{
+		Namespace: "mysql",
+		Name:      "up",
+		Help:      "Whether the MySQL server is up.",
+	}
+)
+
+// newCollectorFunc returns a collector scraping a target once.
+type newCollectorFunc func(ctx context.Context) (prometheus.Collector, error)
+
+// backgroundTarget identifies a target scraped in the background.
+type backgroundTarget struct {
+	authModule string
+	module     string
+	target     string
+	// collect is the sorted, comma separated list of collect[] parameters.
+	collect string
+	// options is the sorted, URL encoded list of option parameters.
+	options string
+}
+
+func newBackgroundTarget(authModule, module, target string, collectParams []string, optionParams map[string]string) backgroundTarget {
+	options := url.Values{}
+	for name, value := range optionParams {
+		options.Set(name, value)
+	}
+	return backgroundTarget{
+		authModule: authModule,
+		module:     module,
+		target:     target,
+		collect:    strings.Join(slices.Sorted(slices.Values(collectParams)), ","),
+		options:    options.Encode(),
+	}
+}
+
+// backgroundScraper scrapes targets on a fixed interval in the background,
+// so that requests are served the result of the last completed scrape
+// instead of querying the target on every request.
+//
+// The targets of the config are scraped in the background from the load of
+// the config on, until they are removed from it. If interval is set, other
+// targets are scraped in the background from the first time they are
+// requested until they were not requested for longer than the staleness
+// limit.
+type backgroundScraper struct {
+	// interval of the scrapes of requested targets, 0 if only the targets of
+	// the config are scraped in the background.
+	interval     time.Duration
+	maxStaleness time.Duration
+	logger       *slog.Logger
+
+	mu      sync.Mutex
+	scrapes map[backgroundTarget]*backgroundScrape
+}
+
+// backgroundScrape holds the state of a target scraped in the background.
+type backgroundScrape struct {
+	// scraped is closed once the first scrape of the target completed.
+	scraped  chan struct{}
+	cancel   context.CancelFunc
+	interval time.Duration
+	// configured is set for the targets of the config, which are scraped
+	// whether they are requested or not.
+	configured bool
+
+	mu          sync.RWMutex
+	families    []*dto.MetricFamily
+	timestamp   time.Time
+	lastRequest time.Time
+}
+
+// configuredTarget is a target of the config scraped in the background.
+type configuredTarget struct {
+	interval     time.Duration
+	newCollector newCollectorFunc
+}
+
+func newBackgroundScraper(interval, maxStaleness time.Duration, logger *slog.Logger) *backgroundScraper {
+	return &backgroundScraper{
+		interval:     interval,
+		maxStaleness: maxStaleness,
+		logger:       logger,
+		scrapes:      make(map[backgroundTarget]*backgroundScrape),
+	}
+}
+
+// startLocked starts scraping the target in the background on the interval
+// and returns its state. b.mu must be held.
+func (b *backgroundScraper) startLocked(t backgroundTarget, interval time.Duration, configured bool, newCollector newCollectorFunc) *backgroundScrape {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &backgroundScrape{
+		scraped:     make(chan struct{}),
+		cancel:      cancel,
+		interval:    interval,
+		configured:  configured,
+		lastRequest: time.Now(),
+	}
+	b.scrapes[t] = s
+	go b.run(ctx, t, s, newCollector)
+	return s
+}
+
+// sync scrapes the targets of the config in the background, restarting the
+// scrapes of targets whose interval changed, and stops the scrapes of the
+// targets removed from the config.
+func (b *backgroundScraper) sync(targets map[backgroundTarget]configuredTarget) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for t, s := range b.scrapes {
+		c, ok := targets[t]
+		if ok && s.configured && s.interval == c.interval {
+			continue
+		}
+		if ok || s.configured {
+			b.logger.Debug("Stopping background scrapes of reconfigured target", "auth_module", t.authModule, "module", t.module, "target", t.target)
+			s.cancel()
+			delete(b.scrapes, t)
+		}
+	}
+	for t, c := range targets {
+		if _, ok := b.scrapes[t]; !ok {
+			b.startLocked(t, c.interval, true, c.newCollector)
+		}
+	}
+}
+
+// stop stops all background scrapes.
+func (b *backgroundScraper) stop() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for t, s := range b.scrapes {
+		s.cancel()
+		delete(b.scrapes, t)
+	}
+}
+
+// staleness returns the maximum age of the results of the scrape served on
+// requests, which is at least its interval.
+func (b *backgroundScraper) staleness(s *backgroundScrape) time.Duration {
+	return max(b.maxStaleness, s.interval)
+}
+
+func (b *backgroundScraper) run(ctx context.Context, t backgroundTarget, s *backgroundScrape, newCollector newCollectorFunc) {
+	logger := b.logger.With("auth_module", t.authModule, "module", t.module, "target", t.target)
+	logger.Debug("Starting background scrapes", "interval", s.interval)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	var once sync.Once
+	for {
+		b.scrape(ctx, s, newCollector, logger)
+		once.Do(func() { close(s.scraped) })
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if s.configured {
+			continue
+		}
+
+		s.mu.RLock()
+		idle := time.Since(s.lastRequest)
+		s.mu.RUnlock()
+		if idle > b.staleness(s) {
+			logger.Debug("Stopping background scrapes of idle target", "idle", idle)
+			b.mu.Lock()
+			if b.scrapes[t] == s {
+				delete(b.scrapes, t)
+			}
+			b.mu.Unlock()
+			s.cancel()
+			return
+		}
+	}
+}
+
+func (b *backgroundScraper) scrape(ctx context.Context, s *backgroundScrape, newCollector newCollectorFunc, logger *slog.Logger) {
+	// A scrape must complete before the next one is due.
+	ctx, cancel := context.WithTimeout(ctx, s.interval)
+	defer cancel()
+
+	c, err := newCollector(ctx)
+	if err != nil {
+		logger.Error("Error creating collector for background scrape", "err", err)
+		return
+	}
+	registry := prometheus.NewRegistry()
+	if err := registry.Register(c); err != nil {
+		logger.Error("Error registering collector for background scrape", "err", err)
+		return
+	}
+	families, err := registry.Gather()
+	if err != nil {
+		// Gather returns as many metrics as possible even on errors.
+		logger.Error("Error gathering metrics in background scrape", "err", err)
+	}
+	if ctx.Err() != nil && len(families) == 0 {
+		return
+	}
+
+	s.mu.Lock()
+	s.families = families
+	s.timestamp = time.Now()
+	s.mu.Unlock()
+}
+
+// gatherer returns a prometheus.Gatherer serving the result of the last
+// background scrape of the target, and false if the target is not scraped in
+// the background. The first request of a target waits for its first scrape
+// to complete or ctx to be done.
+func (b *backgroundScraper) gatherer(ctx context.Context, t backgroundTarget, newCollector newCollectorFunc) (prometheus.Gatherer, bool) {
+	b.mu.Lock()
+	s, ok := b.scrapes[t]
+	if !ok && b.interval > 0 {
+		s, ok = b.startLocked(t, b.interval, false, newCollector), true
+	}
+	b.mu.Unlock()
+	if !ok {
+		return nil, false
+	}
+	s.mu.Lock()
+	s.lastRequest = time.Now()
+	s.mu.Unlock()
+
+	select {
+	case <-s.scraped:
+	case <-ctx.Done():
+	}
+
+	g := prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+		s.mu.RLock()
+		families, timestamp := s.families, s.timestamp
+		s.mu.RUnlock()
+
+		registry := prometheus.NewRegistry()
+		registry.MustRegister(prometheus.NewGaugeFunc(lastScrapeTimestampOpts, func() float64 {
+			if timestamp.IsZero() {
+				return 0
+			}
+			return float64(timestamp.UnixNano()) / 1e9
+		}))
+		if timestamp.IsZero() || time.Since(timestamp) > b.staleness(s) {
+			// Do not serve outdated metrics, report the target as down instead.
+			registry.MustRegister(prometheus.NewGaugeFunc(staleUpOpts, func() float64 { return 0 }))
+			return registry.Gather()
+		}
+		return prometheus.Gatherers{
+			prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) { return families, nil }),
+			registry,
+		}.Gather()
+	})
+	return g, true
+}
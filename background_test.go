@@ -0,0 +1,264 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/common/promslog"
+	"github.com/prometheus/mysqld_exporter/collector"
+	"github.com/prometheus/mysqld_exporter/config"
+)
+
+func gatherValues(t *testing.T, g prometheus.Gatherer) map[string]float64 {
+	t.Helper()
+	families, err := g.Gather()
+	if err != nil {
+		t.Fatalf("unexpected error gathering metrics: %v", err)
+	}
+	values := map[string]float64{}
+	for _, mf := range families {
+		for _, m := range mf.GetMetric() {
+			switch mf.GetType() {
+			case dto.MetricType_GAUGE:
+				values[mf.GetName()] = m.GetGauge().GetValue()
+			case dto.MetricType_COUNTER:
+				values[mf.GetName()] = m.GetCounter().GetValue()
+			}
+		}
+	}
+	return values
+}
+
+func TestBackgroundScraper(t *testing.T) {
+	var scrapes atomic.Int64
+	newCollector := func(context.Context) (prometheus.Collector, error) {
+		scrapes.Add(1)
+		c := prometheus.NewCounter(prometheus.CounterOpts{Name: "test_scrapes_total", Help: "Scrapes."})
+		c.Add(float64(scrapes.Load()))
+		return c, nil
+	}
+
+	b := newBackgroundScraper(10*time.Millisecond, time.Hour, promslog.NewNopLogger())
+	defer b.stop()
+
+	target := newBackgroundTarget("client", "", "server1:3306", []string{"global_status", "binlog_size"}, nil)
+	g, ok := b.gatherer(context.Background(), target, newCollector)
+	if !ok {
+		t.Fatal("expected the target to be scraped in the background")
+	}
+	values := gatherValues(t, g)
+	if values["test_scrapes_total"] < 1 {
+		t.Fatalf("expected the first scrape to be served, got %v", values)
+	}
+	if values["mysql_exporter_last_scrape_timestamp_seconds"] == 0 {
+		t.Fatalf("expected last scrape timestamp to be set, got %v", values)
+	}
+
+	// Requests are served from the background scrapes.
+	time.Sleep(50 * time.Millisecond)
+	g, _ = b.gatherer(context.Background(), newBackgroundTarget("client", "", "server1:3306", []string{"binlog_size", "global_status"}, nil), newCollector)
+	values = gatherValues(t, g)
+	if values["test_scrapes_total"] < 2 {
+		t.Fatalf("expected background scrapes, got %v", values)
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.scrapes) != 1 {
+		t.Fatalf("expected a single background scrape, got %d", len(b.scrapes))
+	}
+}
+
+func TestBackgroundScraperStaleness(t *testing.T) {
+	b := newBackgroundScraper(time.Hour, time.Hour, promslog.NewNopLogger())
+	defer b.stop()
+
+	target := newBackgroundTarget("client", "", "", nil, nil)
+	newCollector := func(context.Context) (prometheus.Collector, error) {
+		up := prometheus.NewGauge(prometheus.GaugeOpts{Name: "mysql_up", Help: "Whether the MySQL server is up."})
+		up.Set(1)
+		return up, nil
+	}
+	g, _ := b.gatherer(context.Background(), target, newCollector)
+	if up := gatherValues(t, g)["mysql_up"]; up != 1 {
+		t.Fatalf("expected the scraped metrics to be served, got mysql_up %v", up)
+	}
+
+	// Pretend the last scrape happened long ago.
+	b.mu.Lock()
+	s := b.scrapes[target]
+	b.mu.Unlock()
+	s.mu.Lock()
+	s.timestamp = time.Now().Add(-2 * time.Hour)
+	s.mu.Unlock()
+
+	g, _ = b.gatherer(context.Background(), target, newCollector)
+	values := gatherValues(t, g)
+	if up, ok := values["mysql_up"]; !ok || up != 0 {
+		t.Fatalf("expected mysql_up 0 for stale results, got %v", values)
+	}
+}
+
+func TestNewBackgroundTarget(t *testing.T) {
+	a := newBackgroundTarget("client", "", "db1:3306", nil, map[string]string{"heartbeat.utc": "true", "perf_schema.eventsstatements.limit": "50"})
+	b := newBackgroundTarget("client", "", "db1:3306", nil, map[string]string{"perf_schema.eventsstatements.limit": "50", "heartbeat.utc": "true"})
+	if a != b {
+		t.Fatalf("expected equal targets, got %+v and %+v", a, b)
+	}
+	if c := newBackgroundTarget("client", "", "db1:3306", nil, map[string]string{"perf_schema.eventsstatements.limit": "10"}); a == c {
+		t.Fatalf("expected targets with other options to differ, got %+v", c)
+	}
+}
+
+func TestApplySettings(t *testing.T) {
+	logger := promslog.NewNopLogger()
+	scraperCache = collector.NewScraperCache(nil)
+	instanceCache = collector.NewInstanceCache(0)
+	scrapeErrors = collector.NewScrapeErrors(0)
+	c.SetConfig(&config.Config{})
+	interval := model.Duration(time.Hour)
+	exporterConfig.SetConfig(&config.ExporterConfig{Exporter: config.ExporterSettings{BackgroundScrapeInterval: &interval}})
+	defer func() {
+		if b := backgroundScrapes.Swap(nil); b != nil {
+			b.stop()
+		}
+		exporterConfig.SetConfig(&config.ExporterConfig{})
+		scraperCache, instanceCache, scrapeErrors = nil, nil, nil
+	}()
+	scrapers := func() []collector.Scraper { return nil }
+
+	applySettings(scrapers, logger)
+	b := backgroundScrapes.Load()
+	if b == nil || b.interval != time.Hour || b.maxStaleness != time.Hour {
+		t.Fatalf("expected background scrapes every hour, got %+v", b)
+	}
+
+	// Unchanged settings keep the background scrapes running.
+	applySettings(scrapers, logger)
+	if backgroundScrapes.Load() != b {
+		t.Fatal("expected the background scraper to be kept")
+	}
+
+	interval = model.Duration(time.Minute)
+	applySettings(scrapers, logger)
+	if n := backgroundScrapes.Load(); n == b || n.interval != time.Minute {
+		t.Fatalf("expected a new background scraper every minute, got %+v", n)
+	}
+	b.mu.Lock()
+	stopped := len(b.scrapes) == 0
+	b.mu.Unlock()
+	if !stopped {
+		t.Fatal("expected the old background scrapes to be stopped")
+	}
+
+	exporterConfig.SetConfig(&config.ExporterConfig{})
+	applySettings(scrapers, logger)
+	if b := backgroundScrapes.Load(); b != nil {
+		t.Fatalf("expected no background scrapes without an interval, got %+v", b)
+	}
+
+	// Targets with a scrape interval are scraped from the load of the config
+	// on, and until they are removed from it.
+	primary := newBackgroundTarget("client.primary", "", "db1:3306", nil, nil)
+	replica := newBackgroundTarget("client", "statements", "db2:3306", []string{"global_status"}, nil)
+	exporterConfig.SetConfig(&config.ExporterConfig{
+		Targets: []config.Target{
+			{Name: "primary", Address: "db1:3306", AuthModule: "client.primary", ScrapeInterval: model.Duration(time.Hour)},
+			{Name: "replica", Address: "db2:3306", AuthModule: "client", Module: "statements", ScrapeInterval: model.Duration(time.Minute)},
+			{Name: "other", Address: "db3:3306", AuthModule: "client"},
+		},
+		Modules: map[string]config.Module{"statements": {Collectors: []string{"global_status"}}},
+	})
+	applySettings(scrapers, logger)
+	b = backgroundScrapes.Load()
+	if b == nil || b.interval != 0 {
+		t.Fatalf("expected background scrapes of the configured targets only, got %+v", b)
+	}
+	scrapeIntervals := func() map[backgroundTarget]time.Duration {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		intervals := map[backgroundTarget]time.Duration{}
+		for t, s := range b.scrapes {
+			intervals[t] = s.interval
+		}
+		return intervals
+	}
+	if diff := cmp.Diff(map[backgroundTarget]time.Duration{primary: time.Hour, replica: time.Minute}, scrapeIntervals(), cmp.AllowUnexported(backgroundTarget{})); diff != "" {
+		t.Fatalf("background scrapes mismatch (-want +got):\n%s", diff)
+	}
+	if _, ok := b.gatherer(context.Background(), newBackgroundTarget("client", "", "db3:3306", nil, nil), nil); ok {
+		t.Fatal("expected requested targets not to be scraped in the background")
+	}
+
+	exporterConfig.SetConfig(&config.ExporterConfig{
+		Targets: []config.Target{
+			{Name: "primary", Address: "db1:3306", AuthModule: "client.primary", ScrapeInterval: model.Duration(time.Hour)},
+		},
+	})
+	applySettings(scrapers, logger)
+	if backgroundScrapes.Load() != b {
+		t.Fatal("expected the background scraper to be kept")
+	}
+	if diff := cmp.Diff(map[backgroundTarget]time.Duration{primary: time.Hour}, scrapeIntervals(), cmp.AllowUnexported(backgroundTarget{})); diff != "" {
+		t.Fatalf("background scrapes mismatch after removing a target (-want +got):\n%s", diff)
+	}
+}
+
+func TestBackgroundScraperConfiguredTargets(t *testing.T) {
+	var scrapes atomic.Int64
+	newCollector := func(context.Context) (prometheus.Collector, error) {
+		scrapes.Add(1)
+		return prometheus.NewGauge(prometheus.GaugeOpts{Name: "test_up", Help: "Up."}), nil
+	}
+	// Requested targets would be stopped right away.
+	b := newBackgroundScraper(0, 0, promslog.NewNopLogger())
+	defer b.stop()
+
+	target := newBackgroundTarget("client", "", "db1:3306", nil, nil)
+	b.sync(map[backgroundTarget]configuredTarget{target: {interval: 10 * time.Millisecond, newCollector: newCollector}})
+	// Configured targets are scraped without being requested, and are not
+	// stopped once idle.
+	time.Sleep(100 * time.Millisecond)
+	if n := scrapes.Load(); n < 3 {
+		t.Fatalf("expected repeated background scrapes, got %d", n)
+	}
+	b.mu.Lock()
+	s := b.scrapes[target]
+	b.mu.Unlock()
+	if s == nil {
+		t.Fatal("expected the configured target to be scraped")
+	}
+
+	// A changed interval restarts the scrapes, removed targets are stopped.
+	b.sync(map[backgroundTarget]configuredTarget{target: {interval: time.Hour, newCollector: newCollector}})
+	b.mu.Lock()
+	restarted := b.scrapes[target]
+	b.mu.Unlock()
+	if restarted == s || restarted.interval != time.Hour {
+		t.Fatalf("expected the scrapes to be restarted every hour, got %+v", restarted)
+	}
+	b.sync(nil)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.scrapes) != 0 {
+		t.Fatalf("expected the scrapes of removed targets to be stopped, got %d", len(b.scrapes))
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/blang/semver/v4"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -70,10 +71,18 @@ func (ScrapeBinlogSize) Version() float64 {
 	return 5.1
 }
 
+// VersionRanges of the servers from which scraper is available.
+func (ScrapeBinlogSize) VersionRanges() map[string]semver.Range {
+	return map[string]semver.Range{
+		FlavorMySQL:   semver.MustParseRange(">=5.1.0"),
+		FlavorMariaDB: semver.MustParseRange(">=5.1.0"),
+	}
+}
+
 // Scrape collects data from database connection and sends it over channel as prometheus metric.
-func (ScrapeBinlogSize) Scrape(ctx context.Context, instance *instance, ch chan<- prometheus.Metric, logger *slog.Logger) error {
+func (ScrapeBinlogSize) Scrape(ctx context.Context, instance *Instance, ch chan<- prometheus.Metric, logger *slog.Logger) error {
 	var logBin uint8
-	db := instance.getDB()
+	db := instance.DB()
 	err := db.QueryRowContext(ctx, logbinQuery).Scan(&logBin)
 	if err != nil {
 		return err
@@ -139,4 +148,4 @@ func (ScrapeBinlogSize) Scrape(ctx context.Context, instance *instance, ch chan<
 }
 
 // check interface
-var _ Scraper = ScrapeBinlogSize{}
+var _ VersionRangesScraper = ScrapeBinlogSize{}
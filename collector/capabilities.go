@@ -0,0 +1,175 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SQL queries discovering the capabilities of a server.
+const (
+	capabilitiesTablesQuery = `
+		SELECT LOWER(CONCAT(table_schema, '.', table_name))
+		  FROM information_schema.tables
+		  WHERE table_schema IN ('information_schema', 'performance_schema', 'sys')
+		`
+	capabilitiesPluginsQuery   = `SELECT LOWER(plugin_name) FROM information_schema.plugins WHERE plugin_status = 'ACTIVE'`
+	capabilitiesConsumersQuery = `SELECT LOWER(name) FROM performance_schema.setup_consumers WHERE enabled = 'YES'`
+	capabilitiesEnginesQuery   = `SELECT LOWER(engine) FROM information_schema.engines WHERE support IN ('YES', 'DEFAULT')`
+)
+
+// capabilitiesTTL is how long the discovered capabilities of a server are
+// used before they are discovered again, e.g. after a plugin was installed or
+// a consumer was enabled.
+const capabilitiesTTL = 10 * time.Minute
+
+// Requirements are the features of the server a scraper needs. All names are
+// case insensitive.
+type Requirements struct {
+	// Tables of the information_schema, performance_schema or sys schema, as
+	// `<schema>.<table>`.
+	Tables []string
+	// Plugins which must be active.
+	Plugins []string
+	// Consumers of the performance_schema which must be enabled.
+	Consumers []string
+	// Engines which must be supported.
+	Engines []string
+}
+
+// RequirementsScraper is implemented by scrapers which need features not
+// available on all servers. Such scrapers are skipped on servers without
+// the features instead of failing.
+type RequirementsScraper interface {
+	Scraper
+
+	// Requirements returns the features of the server the scraper needs.
+	Requirements() Requirements
+}
+
+// Capabilities are the features of a server. A nil set means that the
+// features of its kind could not be discovered.
+type Capabilities struct {
+	Tables    map[string]bool
+	Plugins   map[string]bool
+	Consumers map[string]bool
+	Engines   map[string]bool
+}
+
+// capabilitiesCache holds the capabilities of an instance once discovered,
+// until they are older than capabilitiesTTL.
+type capabilitiesCache struct {
+	mu           sync.Mutex
+	capabilities *Capabilities
+	discovered   time.Time
+	// now returns the current time, it is replaced in tests.
+	now func() time.Time
+}
+
+// get returns the cached capabilities, discovering them if they are missing
+// or expired. They are only cached if ctx is not done before the discovery
+// completed.
+func (c *capabilitiesCache) get(ctx context.Context, db *sql.DB, logger *slog.Logger) *Capabilities {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	if c.now != nil {
+		now = c.now()
+	}
+	if c.capabilities != nil && now.Sub(c.discovered) < capabilitiesTTL {
+		return c.capabilities
+	}
+	capabilities := discoverCapabilities(ctx, db, logger)
+	if ctx.Err() == nil {
+		c.capabilities = capabilities
+		c.discovered = now
+	}
+	return capabilities
+}
+
+// discoverCapabilities queries the features of the server. Features which
+// cannot be queried are logged and left unknown.
+func discoverCapabilities(ctx context.Context, db *sql.DB, logger *slog.Logger) *Capabilities {
+	c := &Capabilities{}
+	for _, kind := range []struct {
+		name  string
+		query string
+		set   *map[string]bool
+	}{
+		{"tables", capabilitiesTablesQuery, &c.Tables},
+		{"plugins", capabilitiesPluginsQuery, &c.Plugins},
+		{"consumers", capabilitiesConsumersQuery, &c.Consumers},
+		{"engines", capabilitiesEnginesQuery, &c.Engines},
+	} {
+		names, err := queryNames(ctx, db, kind.query)
+		if err != nil {
+			logger.Warn("Error discovering server capabilities, not skipping collectors requiring them", "capabilities", kind.name, "err", err)
+			continue
+		}
+		*kind.set = names
+	}
+	return c
+}
+
+func queryNames(ctx context.Context, db *sql.DB, query string) (map[string]bool, error) {
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	names := map[string]bool{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names[name] = true
+	}
+	return names, rows.Err()
+}
+
+// Missing returns a description of the first requirement the server does
+// not meet, or an empty string if it meets all of them or they are unknown.
+func (c *Capabilities) Missing(r Requirements) string {
+	if c == nil {
+		return ""
+	}
+	for _, kind := range []struct {
+		name      string
+		available map[string]bool
+		required  []string
+	}{
+		{"table", c.Tables, r.Tables},
+		{"plugin", c.Plugins, r.Plugins},
+		{"consumer", c.Consumers, r.Consumers},
+		{"engine", c.Engines, r.Engines},
+	} {
+		if kind.available == nil {
+			continue
+		}
+		for _, name := range kind.required {
+			if !kind.available[strings.ToLower(name)] {
+				return fmt.Sprintf("%s %s", kind.name, name)
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,143 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/blang/semver/v4"
+	"github.com/prometheus/common/promslog"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestInstanceCapabilities(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+	inst := &Instance{
+		db:                db,
+		flavor:            FlavorPercona,
+		version:           semver.MustParse("5.7.44"),
+		versionMajorMinor: 5.7,
+		capabilities:      &capabilitiesCache{},
+	}
+
+	mock.ExpectQuery(sanitizeQuery(capabilitiesTablesQuery)).WillReturnRows(
+		sqlmock.NewRows([]string{"name"}).
+			AddRow("information_schema.user_statistics").
+			AddRow("performance_schema.events_statements_summary_by_digest"))
+	mock.ExpectQuery(sanitizeQuery(capabilitiesPluginsQuery)).WillReturnRows(
+		sqlmock.NewRows([]string{"name"}).AddRow("innodb"))
+	mock.ExpectQuery(sanitizeQuery(capabilitiesConsumersQuery)).WillReturnError(errors.New("performance_schema is disabled"))
+	mock.ExpectQuery(sanitizeQuery(capabilitiesEnginesQuery)).WillReturnRows(
+		sqlmock.NewRows([]string{"name"}).AddRow("innodb").AddRow("myisam"))
+
+	convey.Convey("Capabilities are shared by the copies of the instance", t, func() {
+		capabilities := inst.Capabilities(context.Background(), promslog.NewNopLogger())
+		convey.So(capabilities.Tables, convey.ShouldContainKey, "information_schema.user_statistics")
+		convey.So(capabilities.Consumers, convey.ShouldBeNil)
+		convey.So(inst.withOptions(DefaultOptions()).Capabilities(context.Background(), promslog.NewNopLogger()), convey.ShouldEqual, capabilities)
+
+		convey.Convey("Scrapers without their requirements are not applicable", func() {
+			convey.So(reasonOf(skipReason(ScrapeUserStat{}, inst, capabilities)), convey.ShouldEqual, "")
+			convey.So(reasonOf(skipReason(ScrapeQueryResponseTime{}, inst, capabilities)), convey.ShouldEqual, skipReasonNotApplicable)
+			convey.So(reasonOf(skipReason(ScrapeEngineTokudbStatus{}, inst, capabilities)), convey.ShouldEqual, skipReasonNotApplicable)
+			// Consumers are unknown.
+			convey.So(reasonOf(skipReason(ScrapePerfEventsStatements{}, inst, capabilities)), convey.ShouldEqual, "")
+			convey.So(reasonOf(skipReason(ScrapeEngineTokudbStatus{}, inst, nil)), convey.ShouldEqual, "")
+
+			_, detail := skipReason(ScrapeQueryResponseTime{}, inst, capabilities)
+			convey.So(detail, convey.ShouldEqual, "table information_schema.query_response_time is missing")
+			_, detail = skipReason(ScrapeEngineTokudbStatus{}, inst, capabilities)
+			convey.So(detail, convey.ShouldEqual, "engine tokudb is missing")
+
+			// The table of an inactive plugin is not enough.
+			withTable := &Capabilities{Tables: map[string]bool{"information_schema.query_response_time": true}, Plugins: capabilities.Plugins}
+			_, detail = skipReason(ScrapeQueryResponseTime{}, inst, withTable)
+			convey.So(detail, convey.ShouldEqual, "plugin query_response_time is missing")
+		})
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestInstanceCapabilitiesExpire(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+	now := time.Unix(1700000000, 0)
+	inst := &Instance{db: db, capabilities: &capabilitiesCache{now: func() time.Time { return now }}}
+
+	expectDiscovery := func(plugins ...string) {
+		rows := sqlmock.NewRows([]string{"name"})
+		for _, plugin := range plugins {
+			rows.AddRow(plugin)
+		}
+		mock.ExpectQuery(sanitizeQuery(capabilitiesTablesQuery)).WillReturnRows(sqlmock.NewRows([]string{"name"}))
+		mock.ExpectQuery(sanitizeQuery(capabilitiesPluginsQuery)).WillReturnRows(rows)
+		mock.ExpectQuery(sanitizeQuery(capabilitiesConsumersQuery)).WillReturnRows(sqlmock.NewRows([]string{"name"}))
+		mock.ExpectQuery(sanitizeQuery(capabilitiesEnginesQuery)).WillReturnRows(sqlmock.NewRows([]string{"name"}))
+	}
+	expectDiscovery()
+	expectDiscovery("query_response_time")
+
+	convey.Convey("Capabilities are discovered again once expired", t, func() {
+		logger := promslog.NewNopLogger()
+		convey.So(inst.Capabilities(context.Background(), logger).Plugins, convey.ShouldBeEmpty)
+
+		now = now.Add(capabilitiesTTL - time.Second)
+		convey.So(inst.Capabilities(context.Background(), logger).Plugins, convey.ShouldBeEmpty)
+
+		now = now.Add(time.Second)
+		convey.So(inst.Capabilities(context.Background(), logger).Plugins, convey.ShouldContainKey, "query_response_time")
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
+
+func TestCapabilitiesMissing(t *testing.T) {
+	capabilities := &Capabilities{
+		Tables:  map[string]bool{"sys.x$user_summary": true},
+		Engines: map[string]bool{"innodb": true},
+	}
+	tests := []struct {
+		requirements Requirements
+		want         string
+	}{
+		{Requirements{}, ""},
+		{Requirements{Tables: []string{"SYS.X$USER_SUMMARY"}, Engines: []string{"InnoDB"}}, ""},
+		{Requirements{Tables: []string{"information_schema.query_response_time"}}, "table information_schema.query_response_time"},
+		{Requirements{Engines: []string{"rocksdb"}}, "engine rocksdb"},
+		{Requirements{Plugins: []string{"query_response_time"}}, ""},
+	}
+	convey.Convey("Missing requirements", t, func() {
+		for _, tt := range tests {
+			convey.So(capabilities.Missing(tt.requirements), convey.ShouldEqual, tt.want)
+		}
+	})
+}
@@ -0,0 +1,312 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Phases of opening a connection.
+const (
+	connectPhaseTCP  = "tcp_connect"
+	connectPhaseTLS  = "tls_handshake"
+	connectPhaseAuth = "auth"
+)
+
+var (
+	connectPhaseDurationDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, exporter, "connect_phase_duration_seconds"),
+		"Duration of the phases of opening the connection to the MySQL server, if the scrape connected: tcp_connect includes the resolution of the host name, auth includes the detection of the version.",
+		[]string{"phase"}, nil,
+	)
+	connectTLSInfoDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, exporter, "connect_tls_info"),
+		"The TLS version and cipher suite negotiated with the MySQL server, if the scrape connected with TLS.",
+		[]string{"version", "cipher"}, nil,
+	)
+)
+
+var errInvalidServerHello = errors.New("invalid ServerHello")
+
+// connectTimings records when the phases of opening a connection started and
+// ended, along with the negotiated TLS parameters.
+type connectTimings struct {
+	mu         sync.Mutex
+	dialStart  time.Time
+	dialEnd    time.Time
+	tlsStart   time.Time
+	tlsEnd     time.Time
+	tlsVersion uint16
+	tlsCipher  uint16
+}
+
+// connectTimingsKey is the context key of the connectTimings of the
+// connection opened with the context.
+type connectTimingsKey struct{}
+
+func withConnectTimings(ctx context.Context, timings *connectTimings) context.Context {
+	return context.WithValue(ctx, connectTimingsKey{}, timings)
+}
+
+// targetAddrsKey is the context key of the addresses the TCP connections to
+// the target are restricted to.
+type targetAddrsKey struct{}
+
+// WithTargetAddrs returns a context in which the connection pools to the
+// target are opened to the first reachable of the addresses, with the port
+// of the address of the DSN, instead of resolving its host name again. The
+// addresses are the ones the host name was resolved to when the target was
+// checked against the access rules, so that it can not resolve to other
+// addresses in the meantime. They are bound to the connection pool, which
+// keeps using them for the connections it opens in the background.
+func WithTargetAddrs(ctx context.Context, addrs []netip.Addr) context.Context {
+	return context.WithValue(ctx, targetAddrsKey{}, addrs)
+}
+
+// targetAddrsFromContext returns the target addresses of ctx and whether it
+// has any, see WithTargetAddrs.
+func targetAddrsFromContext(ctx context.Context) ([]netip.Addr, bool) {
+	addrs, ok := ctx.Value(targetAddrsKey{}).([]netip.Addr)
+	return addrs, ok
+}
+
+// dialer opens the connections of a connector. TCP keep-alives are enabled
+// like the driver does for the connections it dials itself, as it does not
+// see the TCP connection behind a timedConn.
+type dialer struct {
+	// addrs are the addresses the TCP connections are restricted to if
+	// pinned, see WithTargetAddrs.
+	addrs  []netip.Addr
+	pinned bool
+}
+
+// dial dials the address, connecting to the target addresses if pinned.
+func (d dialer) dial(ctx context.Context, network, addr string) (net.Conn, error) {
+	netDialer := net.Dialer{KeepAliveConfig: net.KeepAliveConfig{Enable: true}}
+	if !d.pinned {
+		return netDialer.DialContext(ctx, network, addr)
+	}
+	if network != "tcp" {
+		return nil, fmt.Errorf("connections over %s can not be restricted to the target addresses", network)
+	}
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	if len(d.addrs) == 0 {
+		return nil, fmt.Errorf("no addresses to connect to %s", addr)
+	}
+	var errs []error
+	for _, ip := range d.addrs {
+		conn, err := netDialer.DialContext(ctx, network, net.JoinHostPort(ip.String(), port))
+		if err == nil {
+			return conn, nil
+		}
+		errs = append(errs, err)
+	}
+	return nil, errors.Join(errs...)
+}
+
+// dialTimed dials the address, timing the connection if ctx has
+// connectTimings. The timings are reset by each dial, so that they are the
+// timings of the last connection opened with ctx.
+func (d dialer) dialTimed(ctx context.Context, network, addr string) (net.Conn, error) {
+	timings, ok := ctx.Value(connectTimingsKey{}).(*connectTimings)
+	if !ok {
+		return d.dial(ctx, network, addr)
+	}
+	start := time.Now()
+	conn, err := d.dial(ctx, network, addr)
+	if err != nil {
+		return nil, err
+	}
+	timings.mu.Lock()
+	timings.dialStart, timings.dialEnd = start, time.Now()
+	timings.tlsStart, timings.tlsEnd = time.Time{}, time.Time{}
+	timings.tlsVersion, timings.tlsCipher = 0, 0
+	timings.mu.Unlock()
+	return &timedConn{Conn: conn, timings: timings}, nil
+}
+
+// Records of the TLS protocol.
+const (
+	tlsRecordHeaderLen            = 5
+	tlsRecordTypeHandshake        = 22
+	tlsRecordTypeApplication      = 23
+	tlsHandshakeTypeServerHello   = 2
+	tlsExtensionSupportedVersions = 43
+	tlsMaxServerHelloLen          = tlsRecordHeaderLen + 1<<14
+)
+
+// timedConn observes the packets of the driver while it opens the
+// connection. The driver asks the server for TLS with an SSL request packet,
+// writes the TLS handshake on the connection and then the application data
+// of the authentication, so the handshake takes from the SSL request to the
+// first application data record. The negotiated version and cipher suite are
+// read from the ServerHello.
+type timedConn struct {
+	net.Conn
+	timings *connectTimings
+
+	// done is set once the connection is authenticating.
+	done      bool
+	tls       bool
+	helloDone bool
+	hello     []byte
+}
+
+func (c *timedConn) Write(b []byte) (int, error) {
+	if !c.done {
+		switch {
+		case !c.tls && isSSLRequest(b):
+			n, err := c.Conn.Write(b)
+			c.tls = true
+			c.timings.mu.Lock()
+			c.timings.tlsStart = time.Now()
+			c.timings.mu.Unlock()
+			return n, err
+		case c.tls && len(b) > 0 && b[0] == tlsRecordTypeApplication:
+			c.timings.mu.Lock()
+			c.timings.tlsEnd = time.Now()
+			c.timings.mu.Unlock()
+			c.done = true
+			c.hello = nil
+		case !c.tls:
+			c.done = true
+		}
+	}
+	return c.Conn.Write(b)
+}
+
+func (c *timedConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	if c.tls && !c.done && !c.helloDone {
+		c.hello = append(c.hello, b[:n]...)
+		version, cipher, ok, parseErr := parseServerHello(c.hello)
+		switch {
+		case ok:
+			c.timings.mu.Lock()
+			c.timings.tlsVersion, c.timings.tlsCipher = version, cipher
+			c.timings.mu.Unlock()
+			fallthrough
+		case parseErr != nil, len(c.hello) >= tlsMaxServerHelloLen:
+			c.helloDone = true
+			c.hello = nil
+		}
+	}
+	return n, err
+}
+
+// SyscallConn returns the raw connection, which the driver uses to check
+// whether the connection is still alive before reusing it.
+func (c *timedConn) SyscallConn() (syscall.RawConn, error) {
+	sysConn, ok := c.Conn.(syscall.Conn)
+	if !ok {
+		return nil, errors.ErrUnsupported
+	}
+	return sysConn.SyscallConn()
+}
+
+// isSSLRequest returns whether b is the SSL request packet of the MySQL
+// protocol, a packet with a payload of 32 bytes.
+func isSSLRequest(b []byte) bool {
+	return len(b) == 4+32 && b[0] == 32 && b[1] == 0 && b[2] == 0
+}
+
+// parseServerHello returns the TLS version and cipher suite of the
+// ServerHello starting the data read from the server. It returns false
+// without error if more data is needed.
+func parseServerHello(data []byte) (version, cipher uint16, ok bool, err error) {
+	if len(data) < tlsRecordHeaderLen {
+		return 0, 0, false, nil
+	}
+	if data[0] != tlsRecordTypeHandshake {
+		return 0, 0, false, errInvalidServerHello
+	}
+	recordLen := int(binary.BigEndian.Uint16(data[3:5]))
+	if len(data) < tlsRecordHeaderLen+recordLen {
+		return 0, 0, false, nil
+	}
+	hello := data[tlsRecordHeaderLen : tlsRecordHeaderLen+recordLen]
+	// Handshake type, length, legacy version, random and session ID length.
+	if len(hello) < 4+2+32+1 || hello[0] != tlsHandshakeTypeServerHello {
+		return 0, 0, false, errInvalidServerHello
+	}
+	version = binary.BigEndian.Uint16(hello[4:6])
+	hello = hello[4+2+32:]
+	sessionIDLen := int(hello[0])
+	// Session ID, cipher suite and compression method.
+	if len(hello) < 1+sessionIDLen+2+1 {
+		return 0, 0, false, errInvalidServerHello
+	}
+	hello = hello[1+sessionIDLen:]
+	cipher = binary.BigEndian.Uint16(hello[:2])
+	hello = hello[3:]
+	if len(hello) < 2 {
+		return version, cipher, true, nil
+	}
+	extensions := hello[2:]
+	for len(extensions) >= 4 {
+		extType := binary.BigEndian.Uint16(extensions[:2])
+		extLen := int(binary.BigEndian.Uint16(extensions[2:4]))
+		if len(extensions) < 4+extLen {
+			return 0, 0, false, errInvalidServerHello
+		}
+		// TLS 1.3 negotiates the version in the supported_versions
+		// extension.
+		if extType == tlsExtensionSupportedVersions && extLen == 2 {
+			version = binary.BigEndian.Uint16(extensions[4:6])
+		}
+		extensions = extensions[4+extLen:]
+	}
+	return version, cipher, true, nil
+}
+
+// collect sends the durations of the phases of the connection, which took
+// until end, and the negotiated TLS parameters. It sends nothing if no
+// connection was opened.
+func (t *connectTimings) collect(end time.Time, trace *Trace, ch chan<- prometheus.Metric) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.dialStart.IsZero() {
+		return
+	}
+	tcp := t.dialEnd.Sub(t.dialStart)
+	ch <- prometheus.MustNewConstMetric(connectPhaseDurationDesc, prometheus.GaugeValue, tcp.Seconds(), connectPhaseTCP)
+	authStart := t.dialEnd
+	if !t.tlsEnd.IsZero() {
+		handshake := t.tlsEnd.Sub(t.tlsStart)
+		version, cipher := tls.VersionName(t.tlsVersion), tls.CipherSuiteName(t.tlsCipher)
+		ch <- prometheus.MustNewConstMetric(connectPhaseDurationDesc, prometheus.GaugeValue, handshake.Seconds(), connectPhaseTLS)
+		ch <- prometheus.MustNewConstMetric(connectTLSInfoDesc, prometheus.GaugeValue, 1, version, cipher)
+		trace.Printf("TCP connect took %s, TLS handshake (%s, %s) took %s", tcp, version, cipher, handshake)
+		authStart = t.tlsEnd
+	} else {
+		trace.Printf("TCP connect took %s", tcp)
+	}
+	auth := end.Sub(authStart)
+	ch <- prometheus.MustNewConstMetric(connectPhaseDurationDesc, prometheus.GaugeValue, auth.Seconds(), connectPhaseAuth)
+	trace.Printf("Authentication and version detection took %s", auth)
+}
@@ -0,0 +1,236 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"math/big"
+	"net"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+// selfSignedCertificate returns a certificate for 127.0.0.1.
+func selfSignedCertificate(t *testing.T) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+// fakeServer accepts a connection, reads the first packet of the client and
+// then either echoes the connection in plain text or with TLS if the packet
+// is an SSL request, like a MySQL server.
+func fakeServer(t *testing.T, config *tls.Config) net.Listener {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		packet := make([]byte, 4+32)
+		if _, err := io.ReadFull(conn, packet); err != nil {
+			return
+		}
+		var rw io.ReadWriter = conn
+		if isSSLRequest(packet) {
+			tlsConn := tls.Server(conn, config)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			rw = tlsConn
+		}
+		_, _ = io.Copy(rw, rw)
+	}()
+	return listener
+}
+
+// collectPhases returns the phases of the collected durations and the
+// labels of the TLS info, if any.
+func collectPhases(timings *connectTimings) (phases []string, tlsInfo map[string]string) {
+	ch := make(chan prometheus.Metric, 10)
+	timings.collect(time.Now(), nil, ch)
+	close(ch)
+	for m := range ch {
+		var metric dto.Metric
+		if err := m.Write(&metric); err != nil {
+			panic(err)
+		}
+		labels := map[string]string{}
+		for _, l := range metric.GetLabel() {
+			labels[l.GetName()] = l.GetValue()
+		}
+		if m.Desc() == connectTLSInfoDesc {
+			tlsInfo = labels
+			continue
+		}
+		phases = append(phases, labels["phase"])
+	}
+	return phases, tlsInfo
+}
+
+func TestConnectTimings(t *testing.T) {
+	sslRequest := append([]byte{32, 0, 0, 1}, make([]byte, 32)...)
+
+	convey.Convey("Connections with TLS", t, func() {
+		listener := fakeServer(t, &tls.Config{Certificates: []tls.Certificate{selfSignedCertificate(t)}})
+		defer listener.Close()
+
+		timings := &connectTimings{}
+		conn, err := dialer{}.dialTimed(withConnectTimings(context.Background(), timings), "tcp", listener.Addr().String())
+		convey.So(err, convey.ShouldBeNil)
+		defer conn.Close()
+		_, err = conn.Write(sslRequest)
+		convey.So(err, convey.ShouldBeNil)
+		tlsConn := tls.Client(conn, &tls.Config{InsecureSkipVerify: true, MaxVersion: tls.VersionTLS12})
+		convey.So(tlsConn.Handshake(), convey.ShouldBeNil)
+		convey.So(timings.tlsEnd.IsZero(), convey.ShouldBeTrue)
+		_, err = tlsConn.Write([]byte("auth"))
+		convey.So(err, convey.ShouldBeNil)
+
+		state := tlsConn.ConnectionState()
+		convey.So(timings.tlsVersion, convey.ShouldEqual, tls.VersionTLS12)
+		convey.So(timings.tlsCipher, convey.ShouldEqual, state.CipherSuite)
+		convey.So(timings.tlsStart.Before(timings.tlsEnd), convey.ShouldBeTrue)
+
+		phases, tlsInfo := collectPhases(timings)
+		convey.So(phases, convey.ShouldResemble, []string{connectPhaseTCP, connectPhaseTLS, connectPhaseAuth})
+		convey.So(tlsInfo, convey.ShouldResemble, map[string]string{"version": "TLS 1.2", "cipher": tls.CipherSuiteName(state.CipherSuite)})
+	})
+
+	convey.Convey("TLS 1.3 is read from the supported versions", t, func() {
+		listener := fakeServer(t, &tls.Config{Certificates: []tls.Certificate{selfSignedCertificate(t)}})
+		defer listener.Close()
+
+		timings := &connectTimings{}
+		conn, err := dialer{}.dialTimed(withConnectTimings(context.Background(), timings), "tcp", listener.Addr().String())
+		convey.So(err, convey.ShouldBeNil)
+		defer conn.Close()
+		_, err = conn.Write(sslRequest)
+		convey.So(err, convey.ShouldBeNil)
+		tlsConn := tls.Client(conn, &tls.Config{InsecureSkipVerify: true})
+		convey.So(tlsConn.Handshake(), convey.ShouldBeNil)
+		_, err = tlsConn.Write([]byte("auth"))
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(timings.tlsVersion, convey.ShouldEqual, tls.VersionTLS13)
+		convey.So(timings.tlsCipher, convey.ShouldEqual, tlsConn.ConnectionState().CipherSuite)
+	})
+
+	convey.Convey("Connections without TLS", t, func() {
+		listener := fakeServer(t, nil)
+		defer listener.Close()
+
+		timings := &connectTimings{}
+		conn, err := dialer{}.dialTimed(withConnectTimings(context.Background(), timings), "tcp", listener.Addr().String())
+		convey.So(err, convey.ShouldBeNil)
+		defer conn.Close()
+		_, err = conn.Write([]byte{4, 0, 0, 1, 'a', 'u', 't', 'h'})
+		convey.So(err, convey.ShouldBeNil)
+
+		phases, tlsInfo := collectPhases(timings)
+		convey.So(phases, convey.ShouldResemble, []string{connectPhaseTCP, connectPhaseAuth})
+		convey.So(tlsInfo, convey.ShouldBeNil)
+	})
+
+	convey.Convey("Connections are not timed without timings in the context", t, func() {
+		listener := fakeServer(t, nil)
+		defer listener.Close()
+
+		conn, err := dialer{}.dialTimed(context.Background(), "tcp", listener.Addr().String())
+		convey.So(err, convey.ShouldBeNil)
+		defer conn.Close()
+		_, ok := conn.(*net.TCPConn)
+		convey.So(ok, convey.ShouldBeTrue)
+
+		phases, _ := collectPhases(&connectTimings{})
+		convey.So(phases, convey.ShouldBeEmpty)
+	})
+}
+
+func TestTargetAddrs(t *testing.T) {
+	localhost := []netip.Addr{netip.MustParseAddr("127.0.0.1")}
+
+	convey.Convey("Connections are opened to the target addresses instead of the host", t, func() {
+		listener := fakeServer(t, nil)
+		defer listener.Close()
+		_, port, err := net.SplitHostPort(listener.Addr().String())
+		convey.So(err, convey.ShouldBeNil)
+
+		conn, err := dialer{addrs: localhost, pinned: true}.dialTimed(context.Background(), "tcp", net.JoinHostPort("db.invalid", port))
+		convey.So(err, convey.ShouldBeNil)
+		defer conn.Close()
+		convey.So(conn.RemoteAddr().String(), convey.ShouldEqual, listener.Addr().String())
+	})
+
+	convey.Convey("Connections fail without target addresses", t, func() {
+		_, err := dialer{pinned: true}.dialTimed(context.Background(), "tcp", "127.0.0.1:3306")
+		convey.So(err, convey.ShouldNotBeNil)
+		_, err = dialer{addrs: localhost, pinned: true}.dialTimed(context.Background(), "unix", "/run/mysqld/mysqld.sock")
+		convey.So(err, convey.ShouldNotBeNil)
+	})
+
+	convey.Convey("Connections fail if no target address is reachable", t, func() {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		convey.So(err, convey.ShouldBeNil)
+		addr := listener.Addr().String()
+		listener.Close()
+		_, port, err := net.SplitHostPort(addr)
+		convey.So(err, convey.ShouldBeNil)
+
+		_, err = dialer{addrs: localhost, pinned: true}.dialTimed(context.Background(), "tcp", net.JoinHostPort("db.invalid", port))
+		convey.So(err, convey.ShouldNotBeNil)
+	})
+
+}
+
+func TestParseServerHello(t *testing.T) {
+	convey.Convey("Invalid and partial ServerHellos", t, func() {
+		_, _, ok, err := parseServerHello([]byte{22, 3, 3})
+		convey.So(ok, convey.ShouldBeFalse)
+		convey.So(err, convey.ShouldBeNil)
+		_, _, ok, err = parseServerHello([]byte{22, 3, 3, 0, 50, 2})
+		convey.So(ok, convey.ShouldBeFalse)
+		convey.So(err, convey.ShouldBeNil)
+		_, _, _, err = parseServerHello([]byte{21, 3, 3, 0, 2, 2, 40})
+		convey.So(err, convey.ShouldEqual, errInvalidServerHello)
+		_, _, _, err = parseServerHello([]byte{22, 3, 3, 0, 4, 2, 0, 0, 0})
+		convey.So(err, convey.ShouldEqual, errInvalidServerHello)
+	})
+}
@@ -0,0 +1,71 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// mysqlErrAccessDenied is the MySQL error code of a login with a wrong user
+// or password.
+const mysqlErrAccessDenied = 1045
+
+var secondaryPasswordInUseDesc = prometheus.NewDesc(
+	prometheus.BuildFQName(namespace, exporter, "secondary_password_in_use"),
+	"Whether the exporter connects to the MySQL server with the secondary password, as the primary password was denied.",
+	nil, nil,
+)
+
+// CredentialFallbacks remembers the targets which the exporter connects to
+// with the fallback DSN, as the server denied access with the primary DSN.
+// It is safe for concurrent use.
+type CredentialFallbacks struct {
+	mu      sync.Mutex
+	targets map[string]bool
+}
+
+// NewCredentialFallbacks returns a new CredentialFallbacks.
+func NewCredentialFallbacks() *CredentialFallbacks {
+	return &CredentialFallbacks{targets: make(map[string]bool)}
+}
+
+// inUse returns whether the fallback DSN is used for the target of the
+// primary DSN.
+func (f *CredentialFallbacks) inUse(dsn string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.targets[dsn]
+}
+
+// set records whether the fallback DSN is used for the target of the
+// primary DSN.
+func (f *CredentialFallbacks) set(dsn string, inUse bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if inUse {
+		f.targets[dsn] = true
+	} else {
+		delete(f.targets, dsn)
+	}
+}
+
+// isAccessDenied returns whether the server denied the login.
+func isAccessDenied(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlErrAccessDenied
+}
@@ -0,0 +1,159 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/common/promslog"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestSecondaryPasswordFallback(t *testing.T) {
+	const (
+		primaryDSN   = "exporter:new@tcp(server1:3306)/"
+		secondaryDSN = "exporter:old@tcp(server1:3306)/"
+	)
+	ctx := context.Background()
+
+	// newCache returns an instance cache connecting to a server accepting
+	// the given password, and the list of attempted passwords.
+	newCache := func(password string) (*InstanceCache, *[]string) {
+		var attempts []string
+		cache, _, _ := newTestInstanceCache(t, time.Minute)
+		open := cache.open
+		cache.open = func(ctx context.Context, dsn string, maxOpenConns int) (*Instance, error) {
+			cfg, err := mysql.ParseDSN(dsn)
+			if err != nil {
+				return nil, err
+			}
+			attempts = append(attempts, cfg.Passwd)
+			if cfg.Passwd != password {
+				return nil, &mysql.MySQLError{Number: 1045, Message: "Access denied for user 'exporter'"}
+			}
+			return open(ctx, dsn, maxOpenConns)
+		}
+		return cache, &attempts
+	}
+	newExporter := func(cache *InstanceCache, fallbacks *CredentialFallbacks) *Exporter {
+		return New(ctx, primaryDSN, nil, promslog.NewNopLogger(),
+			SetInstanceCache(cache, "client"),
+			SetFallbackDSN(secondaryDSN, fallbacks),
+		)
+	}
+
+	convey.Convey("Primary password is used if accepted", t, func() {
+		cache, attempts := newCache("new")
+		fallbacks := NewCredentialFallbacks()
+		_, dsn, release, err := newExporter(cache, fallbacks).connect(ctx)
+		convey.So(err, convey.ShouldBeNil)
+		release()
+		convey.So(strings.HasPrefix(dsn, primaryDSN), convey.ShouldBeTrue)
+		convey.So(*attempts, convey.ShouldResemble, []string{"new"})
+	})
+
+	convey.Convey("Secondary password is used and remembered if the primary is denied", t, func() {
+		cache, attempts := newCache("old")
+		fallbacks := NewCredentialFallbacks()
+		e := newExporter(cache, fallbacks)
+		_, dsn, release, err := e.connect(ctx)
+		convey.So(err, convey.ShouldBeNil)
+		release()
+		convey.So(dsn, convey.ShouldEqual, e.fallbackDSN)
+		convey.So(*attempts, convey.ShouldResemble, []string{"new", "old"})
+		convey.So(fallbacks.inUse(e.dsn), convey.ShouldBeTrue)
+
+		// The next scrape connects with the secondary password first.
+		*attempts = nil
+		cache.Close()
+		_, _, release, err = newExporter(cache, fallbacks).connect(ctx)
+		convey.So(err, convey.ShouldBeNil)
+		release()
+		convey.So(*attempts, convey.ShouldResemble, []string{"old"})
+	})
+
+	convey.Convey("Primary password is used again once accepted", t, func() {
+		cache, attempts := newCache("new")
+		fallbacks := NewCredentialFallbacks()
+		e := newExporter(cache, fallbacks)
+		fallbacks.set(e.dsn, true)
+		_, dsn, release, err := e.connect(ctx)
+		convey.So(err, convey.ShouldBeNil)
+		release()
+		convey.So(dsn, convey.ShouldEqual, e.dsn)
+		convey.So(*attempts, convey.ShouldResemble, []string{"old", "new"})
+		convey.So(fallbacks.inUse(e.dsn), convey.ShouldBeFalse)
+	})
+
+	convey.Convey("Both passwords denied", t, func() {
+		cache, attempts := newCache("other")
+		_, _, _, err := newExporter(cache, NewCredentialFallbacks()).connect(ctx)
+		convey.So(isAccessDenied(err), convey.ShouldBeTrue)
+		convey.So(*attempts, convey.ShouldResemble, []string{"new", "old"})
+	})
+
+	convey.Convey("Other errors do not fall back", t, func() {
+		cache, attempts := newCache("new")
+		open := cache.open
+		cache.open = func(ctx context.Context, dsn string, maxOpenConns int) (*Instance, error) {
+			if _, err := open(ctx, dsn, maxOpenConns); err != nil {
+				return nil, err
+			}
+			return nil, errors.New("connection refused")
+		}
+		_, _, _, err := newExporter(cache, NewCredentialFallbacks()).connect(ctx)
+		convey.So(err, convey.ShouldNotBeNil)
+		convey.So(*attempts, convey.ShouldResemble, []string{"new"})
+	})
+}
+
+func TestSecondaryPasswordInUseMetric(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+	mock.ExpectPing()
+
+	cache := NewInstanceCache(time.Minute)
+	cache.open = func(_ context.Context, dsn string, _ int) (*Instance, error) {
+		if !strings.Contains(dsn, ":old@") {
+			return nil, &mysql.MySQLError{Number: 1045, Message: "Access denied"}
+		}
+		return &Instance{db: db}, nil
+	}
+	e := New(context.Background(), "exporter:new@tcp(server1:3306)/", nil, promslog.NewNopLogger(),
+		SetInstanceCache(cache, "client"),
+		SetFallbackDSN("exporter:old@tcp(server1:3306)/", NewCredentialFallbacks()),
+	)
+
+	expected := `
+# HELP mysql_exporter_secondary_password_in_use Whether the exporter connects to the MySQL server with the secondary password, as the primary password was denied.
+# TYPE mysql_exporter_secondary_password_in_use gauge
+mysql_exporter_secondary_password_in_use 1
+# HELP mysql_up Whether the MySQL server is up.
+# TYPE mysql_up gauge
+mysql_up 1
+`
+	if err := testutil.CollectAndCompare(e, strings.NewReader(expected), "mysql_exporter_secondary_password_in_use", "mysql_up"); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,289 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Scrape user-defined queries.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blang/semver/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	"go.yaml.in/yaml/v2"
+)
+
+const (
+	// Subsystem.
+	custom = "custom"
+	// customQueryPrefix prefixes the names of the custom query scrapers.
+	customQueryPrefix = "custom_query."
+)
+
+// CustomQueryFile is the format of the file defining custom queries.
+type CustomQueryFile struct {
+	Queries []*CustomQuery `yaml:"queries"`
+}
+
+// CustomQuery is a user-defined query exported as metrics.
+type CustomQuery struct {
+	// Name identifies the query, it must be unique within the file.
+	Name string `yaml:"name"`
+	// Help describes the query.
+	Help string `yaml:"help"`
+	// Query is the SQL statement, every row of its result is exported.
+	Query string `yaml:"query"`
+	// Labels are the columns exported as labels of all metrics.
+	Labels []string `yaml:"labels"`
+	// Metrics are the columns exported as metrics.
+	Metrics []CustomQueryMetric `yaml:"metrics"`
+	// MinVersion is the minimum server version to run the query on.
+	MinVersion string `yaml:"min_version"`
+	// Flavor limits the query to servers of the flavor, one of the Flavor
+	// constants. The query runs on all flavors if empty, the MySQL flavor
+	// includes Percona Server.
+	Flavor string `yaml:"flavor"`
+	// Timeout limits the run time of the query, in addition to the query
+	// timeout of the exporter.
+	Timeout model.Duration `yaml:"timeout"`
+
+	minVersion semver.Version
+	descs      []*prometheus.Desc
+}
+
+// CustomQueryMetric is a column of a custom query exported as metric.
+type CustomQueryMetric struct {
+	// Name of the metric, exported with the `mysql_custom_` prefix.
+	Name string `yaml:"name"`
+	// Help of the metric.
+	Help string `yaml:"help"`
+	// Column holding the value of the metric.
+	Column string `yaml:"column"`
+	// Type of the metric, either gauge (default) or counter.
+	Type string `yaml:"type"`
+}
+
+// LoadCustomQueries reads the custom queries from the YAML file and returns
+// a scraper for each of them.
+func LoadCustomQueries(filename string) ([]ScrapeCustomQuery, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var file CustomQueryFile
+	if err := yaml.UnmarshalStrict(content, &file); err != nil {
+		return nil, fmt.Errorf("error parsing custom queries file %q: %w", filename, err)
+	}
+	names, metricNames := map[string]bool{}, map[string]bool{}
+	scrapers := make([]ScrapeCustomQuery, 0, len(file.Queries))
+	for i, query := range file.Queries {
+		if err := query.init(); err != nil {
+			return nil, fmt.Errorf("error in custom query %d of %q: %w", i, filename, err)
+		}
+		if names[query.Name] {
+			return nil, fmt.Errorf("duplicate custom query %q in %q", query.Name, filename)
+		}
+		names[query.Name] = true
+		for _, metric := range query.Metrics {
+			if metricNames[metric.Name] {
+				return nil, fmt.Errorf("duplicate custom metric %q in %q", metric.Name, filename)
+			}
+			metricNames[metric.Name] = true
+		}
+		scrapers = append(scrapers, ScrapeCustomQuery{query: query})
+	}
+	return scrapers, nil
+}
+
+// init validates the query and creates the descriptors of its metrics.
+func (q *CustomQuery) init() error {
+	if !model.LegacyValidation.IsValidMetricName(q.Name) {
+		return fmt.Errorf("invalid name %q", q.Name)
+	}
+	if q.Query == "" {
+		return fmt.Errorf("query %q has no SQL", q.Name)
+	}
+	if len(q.Metrics) == 0 {
+		return fmt.Errorf("query %q has no metrics", q.Name)
+	}
+	for i, label := range q.Labels {
+		switch {
+		case !model.LegacyValidation.IsValidLabelName(label):
+			return fmt.Errorf("query %q has invalid label column %q", q.Name, label)
+		case strings.HasPrefix(label, model.ReservedLabelPrefix):
+			return fmt.Errorf("query %q has label column %q with the reserved prefix %q", q.Name, label, model.ReservedLabelPrefix)
+		case slices.Contains(q.Labels[:i], label):
+			return fmt.Errorf("query %q has duplicate label column %q", q.Name, label)
+		}
+	}
+	switch q.Flavor {
+	case "", FlavorMySQL, FlavorMariaDB, FlavorPercona:
+	default:
+		return fmt.Errorf("query %q has unknown flavor %q", q.Name, q.Flavor)
+	}
+	if q.MinVersion != "" {
+		minVersion, err := semver.ParseTolerant(q.MinVersion)
+		if err != nil {
+			return fmt.Errorf("query %q has invalid min_version %q: %w", q.Name, q.MinVersion, err)
+		}
+		q.minVersion = minVersion
+	}
+
+	q.descs = make([]*prometheus.Desc, len(q.Metrics))
+	for i, metric := range q.Metrics {
+		if !model.LegacyValidation.IsValidMetricName(metric.Name) {
+			return fmt.Errorf("query %q has invalid metric name %q", q.Name, metric.Name)
+		}
+		if metric.Column == "" {
+			return fmt.Errorf("metric %q of query %q has no column", metric.Name, q.Name)
+		}
+		switch metric.Type {
+		case "", "gauge", "counter":
+		default:
+			return fmt.Errorf("metric %q of query %q has unknown type %q", metric.Name, q.Name, metric.Type)
+		}
+		help := metric.Help
+		if help == "" {
+			help = fmt.Sprintf("Column %s of the custom query %s.", metric.Column, q.Name)
+		}
+		q.descs[i] = prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, custom, metric.Name),
+			help, q.Labels, nil,
+		)
+		// Reject what would make every scrape of the query fail.
+		if err := q.descs[i].Err(); err != nil {
+			return fmt.Errorf("metric %q of query %q: %w", metric.Name, q.Name, err)
+		}
+	}
+	return nil
+}
+
+// ScrapeCustomQuery collects from a custom query. It is named
+// `custom_query.<name>` after the name of the query.
+type ScrapeCustomQuery struct {
+	query *CustomQuery
+}
+
+// Name of the Scraper. Should be unique.
+func (s ScrapeCustomQuery) Name() string {
+	return customQueryPrefix + s.query.Name
+}
+
+// Help describes the role of the Scraper.
+func (s ScrapeCustomQuery) Help() string {
+	if s.query.Help != "" {
+		return s.query.Help
+	}
+	return "Collect from the custom query " + s.query.Name
+}
+
+// Version of MySQL from which scraper is available.
+func (s ScrapeCustomQuery) Version() float64 {
+	version, _ := strconv.ParseFloat(fmt.Sprintf("%d.%d", s.query.minVersion.Major, s.query.minVersion.Minor), 64)
+	return version
+}
+
+// VersionRanges of the servers from which scraper is available.
+func (s ScrapeCustomQuery) VersionRanges() map[string]semver.Range {
+	versionRange := func(v semver.Version) bool { return v.GTE(s.query.minVersion) }
+	if s.query.Flavor != "" {
+		return map[string]semver.Range{s.query.Flavor: versionRange}
+	}
+	return map[string]semver.Range{
+		FlavorMySQL:   versionRange,
+		FlavorMariaDB: versionRange,
+		FlavorPercona: versionRange,
+	}
+}
+
+// Scrape collects data from database connection and sends it over channel as prometheus metric.
+func (s ScrapeCustomQuery) Scrape(ctx context.Context, instance *Instance, ch chan<- prometheus.Metric, logger *slog.Logger) error {
+	q := s.query
+	if q.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(q.Timeout))
+		defer cancel()
+	}
+
+	rows, err := instance.DB().QueryContext(ctx, q.Query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	labelIndexes := make([]int, len(q.Labels))
+	for i, label := range q.Labels {
+		if labelIndexes[i] = slices.Index(columns, label); labelIndexes[i] == -1 {
+			return fmt.Errorf("label column %q not found in the result of custom query %q", label, q.Name)
+		}
+	}
+	valueIndexes := make([]int, len(q.Metrics))
+	for i, metric := range q.Metrics {
+		if valueIndexes[i] = slices.Index(columns, metric.Column); valueIndexes[i] == -1 {
+			return fmt.Errorf("value column %q not found in the result of custom query %q", metric.Column, q.Name)
+		}
+	}
+
+	scanArgs := make([]any, len(columns))
+	for i := range scanArgs {
+		scanArgs[i] = &sql.RawBytes{}
+	}
+	labelValues := make([]string, len(q.Labels))
+	for rows.Next() {
+		if err := rows.Scan(scanArgs...); err != nil {
+			return err
+		}
+		for i, index := range labelIndexes {
+			labelValues[i] = string(*scanArgs[index].(*sql.RawBytes))
+		}
+		for i, metric := range q.Metrics {
+			raw := *scanArgs[valueIndexes[i]].(*sql.RawBytes)
+			if raw == nil {
+				// Skip NULL values.
+				continue
+			}
+			value, ok := parseStatus(raw)
+			if !ok {
+				logger.Debug("Skipping unparsable value of custom query", "query", q.Name, "column", metric.Column, "value", string(raw))
+				continue
+			}
+			valueType := prometheus.GaugeValue
+			if metric.Type == "counter" {
+				valueType = prometheus.CounterValue
+			}
+			// Label values are not valid UTF-8 e.g. for binary columns.
+			m, err := prometheus.NewConstMetric(q.descs[i], valueType, value, labelValues...)
+			if err != nil {
+				return fmt.Errorf("metric %q of custom query %q: %w", metric.Name, q.Name, err)
+			}
+			ch <- m
+		}
+	}
+	return rows.Err()
+}
+
+// check interface
+var _ VersionRangesScraper = ScrapeCustomQuery{}
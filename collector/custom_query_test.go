@@ -0,0 +1,167 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/blang/semver/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/promslog"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+const customQueriesYAML = `
+queries:
+  - name: queue
+    query: SELECT queue, COUNT(*) AS size, UNIX_TIMESTAMP(MAX(created)) AS newest FROM jobs GROUP BY queue
+    labels: [queue]
+    metrics:
+      - name: queue_size
+        column: size
+      - name: queue_newest_timestamp_seconds
+        help: Creation time of the newest job.
+        column: newest
+  - name: flags
+    query: SELECT enabled FROM app_config
+    min_version: 8.0.22
+    flavor: mysql
+    timeout: 5s
+    metrics:
+      - name: app_enabled
+        column: enabled
+        type: counter
+`
+
+func writeCustomQueries(t *testing.T, content string) string {
+	t.Helper()
+	filename := filepath.Join(t.TempDir(), "queries.yml")
+	if err := os.WriteFile(filename, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
+
+func TestLoadCustomQueries(t *testing.T) {
+	convey.Convey("Valid custom queries", t, func() {
+		scrapers, err := LoadCustomQueries(writeCustomQueries(t, customQueriesYAML))
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(scrapers, convey.ShouldHaveLength, 2)
+		convey.So(scrapers[0].Name(), convey.ShouldEqual, "custom_query.queue")
+		convey.So(scrapers[0].Version(), convey.ShouldEqual, 0)
+		convey.So(scrapers[1].Version(), convey.ShouldEqual, 8.0)
+	})
+
+	convey.Convey("Invalid custom queries", t, func() {
+		for _, content := range []string{
+			"queries:\n  - name: q\n    metrics: [{name: m, column: c}]\n",
+			"queries:\n  - name: q\n    query: SELECT 1\n",
+			"queries:\n  - name: q-1\n    query: SELECT 1\n    metrics: [{name: m, column: c}]\n",
+			"queries:\n  - name: q\n    query: SELECT 1\n    metrics: [{name: m, column: c, type: histogram}]\n",
+			"queries:\n  - name: q\n    query: SELECT 1\n    flavor: oracle\n    metrics: [{name: m, column: c}]\n",
+			"queries:\n  - name: q\n    query: SELECT 1\n    unknown: true\n    metrics: [{name: m, column: c}]\n",
+			"queries:\n  - name: q\n    query: SELECT 1\n    metrics: [{name: m, column: c}]\n  - name: q\n    query: SELECT 1\n    metrics: [{name: n, column: c}]\n",
+			"queries:\n  - name: q\n    query: SELECT 1\n    metrics: [{name: m, column: c}]\n  - name: r\n    query: SELECT 1\n    metrics: [{name: m, column: c}]\n",
+		} {
+			_, err := LoadCustomQueries(writeCustomQueries(t, content))
+			convey.So(err, convey.ShouldNotBeNil)
+		}
+	})
+}
+
+func TestLoadCustomQueriesLabels(t *testing.T) {
+	tests := []struct {
+		name    string
+		labels  string
+		wantErr string
+	}{
+		{name: "valid", labels: "[queue, state]"},
+		{name: "invalid", labels: "[queue-name]", wantErr: `invalid label column "queue-name"`},
+		{name: "duplicate", labels: "[queue, state, queue]", wantErr: `duplicate label column "queue"`},
+		{name: "reserved prefix", labels: "[__name__]", wantErr: `label column "__name__" with the reserved prefix "__"`},
+		{name: "reserved prefix of other labels", labels: "[__queue]", wantErr: `label column "__queue" with the reserved prefix "__"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := "queries:\n  - name: q\n    query: SELECT 1\n    labels: " + tt.labels + "\n    metrics: [{name: m, column: c}]\n"
+			_, err := LoadCustomQueries(writeCustomQueries(t, content))
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("unexpected error: %s", err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Fatalf("expected error %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestScrapeCustomQuery(t *testing.T) {
+	scrapers, err := LoadCustomQueries(writeCustomQueries(t, customQueriesYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("error opening a stub database connection: %s", err)
+	}
+	defer db.Close()
+	inst := &Instance{db: db, flavor: FlavorMariaDB, version: semver.MustParse("10.11.0")}
+
+	columns := []string{"queue", "size", "newest"}
+	rows := sqlmock.NewRows(columns).
+		AddRow("mail", "12", "1700000000").
+		AddRow("sms", "0", nil)
+	mock.ExpectQuery(sanitizeQuery(scrapers[0].query.Query)).WillReturnRows(rows)
+
+	ch := make(chan prometheus.Metric)
+	go func() {
+		if err = scrapers[0].Scrape(context.Background(), inst, ch, promslog.NewNopLogger()); err != nil {
+			t.Errorf("error calling function on test: %s", err)
+		}
+		close(ch)
+	}()
+
+	metricExpected := []MetricResult{
+		{labels: labelMap{"queue": "mail"}, value: 12, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"queue": "mail"}, value: 1700000000, metricType: dto.MetricType_GAUGE},
+		{labels: labelMap{"queue": "sms"}, value: 0, metricType: dto.MetricType_GAUGE},
+	}
+	convey.Convey("Metrics comparison", t, func() {
+		for _, expect := range metricExpected {
+			got := readMetric(<-ch)
+			convey.So(got, convey.ShouldResemble, expect)
+		}
+		_, ok := <-ch
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+
+	convey.Convey("Flavor and version of the queries", t, func() {
+		convey.So(reasonOf(skipReason(scrapers[0], inst, nil)), convey.ShouldEqual, "")
+		convey.So(reasonOf(skipReason(scrapers[1], inst, nil)), convey.ShouldEqual, skipReasonFlavor)
+		convey.So(reasonOf(skipReason(scrapers[1], &Instance{flavor: FlavorPercona, version: semver.MustParse("8.0.21")}, nil)), convey.ShouldEqual, skipReasonVersion)
+		convey.So(reasonOf(skipReason(scrapers[1], &Instance{flavor: FlavorPercona, version: semver.MustParse("8.0.22")}, nil)), convey.ShouldEqual, "")
+	})
+
+	// Ensure all SQL queries were executed
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled exceptions: %s", err)
+	}
+}
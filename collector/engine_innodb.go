@@ -22,6 +22,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/blang/semver/v4"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -57,9 +58,17 @@ func (ScrapeEngineInnodbStatus) Version() float64 {
 	return 5.1
 }
 
+// VersionRanges of the servers from which scraper is available.
+func (ScrapeEngineInnodbStatus) VersionRanges() map[string]semver.Range {
+	return map[string]semver.Range{
+		FlavorMySQL:   semver.MustParseRange(">=5.1.0"),
+		FlavorMariaDB: semver.MustParseRange(">=5.1.0"),
+	}
+}
+
 // Scrape collects data from database connection and sends it over channel as prometheus metric.
-func (ScrapeEngineInnodbStatus) Scrape(ctx context.Context, instance *instance, ch chan<- prometheus.Metric, logger *slog.Logger) error {
-	db := instance.getDB()
+func (ScrapeEngineInnodbStatus) Scrape(ctx context.Context, instance *Instance, ch chan<- prometheus.Metric, logger *slog.Logger) error {
+	db := instance.DB()
 	rows, err := db.QueryContext(ctx, engineInnodbStatusQuery)
 	if err != nil {
 		return err
@@ -102,4 +111,4 @@ func (ScrapeEngineInnodbStatus) Scrape(ctx context.Context, instance *instance,
 }
 
 // check interface
-var _ Scraper = ScrapeEngineInnodbStatus{}
+var _ VersionRangesScraper = ScrapeEngineInnodbStatus{}
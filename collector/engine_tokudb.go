@@ -21,6 +21,7 @@ import (
 	"log/slog"
 	"strings"
 
+	"github.com/blang/semver/v4"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -49,9 +50,23 @@ func (ScrapeEngineTokudbStatus) Version() float64 {
 	return 5.6
 }
 
+// VersionRanges of the servers from which scraper is available.
+// MariaDB shipped the TokuDB engine from 10.0 to 10.5.
+func (ScrapeEngineTokudbStatus) VersionRanges() map[string]semver.Range {
+	return map[string]semver.Range{
+		FlavorMySQL:   semver.MustParseRange(">=5.6.0"),
+		FlavorMariaDB: semver.MustParseRange(">=10.0.0"),
+	}
+}
+
+// Requirements of the server for the scraper.
+func (ScrapeEngineTokudbStatus) Requirements() Requirements {
+	return Requirements{Engines: []string{"tokudb"}}
+}
+
 // Scrape collects data from database connection and sends it over channel as prometheus metric.
-func (ScrapeEngineTokudbStatus) Scrape(ctx context.Context, instance *instance, ch chan<- prometheus.Metric, logger *slog.Logger) error {
-	db := instance.getDB()
+func (ScrapeEngineTokudbStatus) Scrape(ctx context.Context, instance *Instance, ch chan<- prometheus.Metric, logger *slog.Logger) error {
+	db := instance.DB()
 	tokudbRows, err := db.QueryContext(ctx, engineTokudbStatusQuery)
 	if err != nil {
 		return err
@@ -96,4 +111,5 @@ func sanitizeTokudbMetric(metricName string) string {
 }
 
 // check interface
-var _ Scraper = ScrapeEngineTokudbStatus{}
+var _ RequirementsScraper = ScrapeEngineTokudbStatus{}
+var _ VersionRangesScraper = ScrapeEngineTokudbStatus{}
@@ -46,7 +46,7 @@ func TestScrapeEngineTokudbStatus(t *testing.T) {
 		t.Fatalf("error opening a stub database connection: %s", err)
 	}
 	defer db.Close()
-	inst := &instance{db: db}
+	inst := &Instance{db: db}
 
 	columns := []string{"Type", "Name", "Status"}
 	rows := sqlmock.NewRows(columns).
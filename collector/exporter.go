@@ -14,9 +14,13 @@
 package collector
 
 import (
+	"cmp"
 	"context"
 	"fmt"
 	"log/slog"
+	"maps"
+	"net/url"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -58,6 +62,31 @@ var (
 		"Collector time duration.",
 		[]string{"collector"}, nil,
 	)
+	mysqlScrapeCollectorSkipped = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, exporter, "collector_skipped"),
+		"mysqld_exporter: Whether a collector was skipped as it does not support the server.",
+		[]string{"collector", "reason"}, nil,
+	)
+)
+
+// lowPriorityDeadline is the share of the time until the deadline of a scrape
+// after which scrapers with a negative priority are cancelled.
+const lowPriorityDeadline = 0.9
+
+// defaultScraperPriorities are the priorities of the cheap scrapers
+// essential to monitor a server, so that they run first. Other scrapers
+// have the priority 0 by default.
+var defaultScraperPriorities = map[string]int{
+	globalStatus:    10,
+	globalVariables: 10,
+	slaveStatus:     10,
+}
+
+// Reasons for skipping a collector.
+const (
+	skipReasonVersion       = "version"
+	skipReasonFlavor        = "flavor"
+	skipReasonNotApplicable = "not_applicable"
 )
 
 // Verify if Exporter implements prometheus.Collector
@@ -69,13 +98,28 @@ type Exporter struct {
 	logger   *slog.Logger
 	dsn      string
 	scrapers []Scraper
-	instance *instance
+	options  Options
+
+	instanceCache *InstanceCache
+	authModule    string
+	scraperCache  *ScraperCache
+	scrapeErrors  *ScrapeErrors
+
+	// fallbackDSN is tried if the server denies access with dsn.
+	fallbackDSN         string
+	credentialFallbacks *CredentialFallbacks
+
+	// trace records the steps of the scrape, if set.
+	trace *Trace
 
 	enableLockWaitTimeout bool
 	lockWaitTimeout       int
 	slowLogFilter         bool
 	queryTimeout          time.Duration
 	maxOpenConns          int
+	scrapeConcurrency     int
+	scraperPriorities     map[string]int
+	sessionSettings       map[string]string
 }
 
 type ExporterOpt func(*Exporter)
@@ -114,6 +158,92 @@ func SetMaxOpenConns(n int) ExporterOpt {
 	}
 }
 
+// SetScrapeConcurrency sets the maximum number of scrapers running at the
+// same time within a scrape. Zero defaults to the maximum number of open
+// connections.
+func SetScrapeConcurrency(n int) ExporterOpt {
+	return func(e *Exporter) {
+		e.scrapeConcurrency = n
+	}
+}
+
+// SetScraperPriorities sets the priorities of the scrapers by name,
+// overriding their default priority. Scrapers with a higher priority run
+// first, and scrapers with a negative priority are cancelled first when the
+// scrape is about to time out.
+func SetScraperPriorities(priorities map[string]int) ExporterOpt {
+	return func(e *Exporter) {
+		e.scraperPriorities = priorities
+	}
+}
+
+// SetSessionSettings sets MySQL session variables on the connections, by
+// name. The values are SQL expressions, so strings must be quoted.
+func SetSessionSettings(settings map[string]string) ExporterOpt {
+	return func(e *Exporter) {
+		e.sessionSettings = settings
+	}
+}
+
+// SetInstanceCache makes the exporter reuse the connection to the target
+// kept in the cache instead of connecting on every scrape. The auth module
+// is recorded along with the cached connection so that it can be
+// invalidated when the configuration of the auth module changes.
+func SetInstanceCache(cache *InstanceCache, authModule string) ExporterOpt {
+	return func(e *Exporter) {
+		e.instanceCache = cache
+		e.authModule = authModule
+	}
+}
+
+// SetScraperCache sets the cache for the metrics of scrapers with a minimum
+// interval. Within the interval, the metrics of the last run of the scraper
+// are served instead of running it again.
+func SetScraperCache(cache *ScraperCache) ExporterOpt {
+	return func(e *Exporter) {
+		e.scraperCache = cache
+	}
+}
+
+// SetOptions sets the tunables passed to the scrapers. Without it, the
+// scrapers use DefaultOptions.
+func SetOptions(options Options) ExporterOpt {
+	return func(e *Exporter) {
+		e.options = options
+	}
+}
+
+// SetScrapeErrors sets where the connect errors and the errors of the
+// scrapers are counted, so that the counts persist across exporters scraping
+// the same target with the same auth module. Without it, the errors are
+// counted per exporter.
+func SetScrapeErrors(scrapeErrors *ScrapeErrors, authModule string) ExporterOpt {
+	return func(e *Exporter) {
+		e.scrapeErrors = scrapeErrors
+		e.authModule = authModule
+	}
+}
+
+// SetFallbackDSN sets the DSN to connect with if the server denies access
+// with the DSN of the exporter, e.g. with the secondary password during a
+// password rotation. Which of the DSNs is accepted by the target is
+// remembered in fallbacks, so that it is tried first on the next scrape.
+func SetFallbackDSN(dsn string, fallbacks *CredentialFallbacks) ExporterOpt {
+	return func(e *Exporter) {
+		e.fallbackDSN = dsn
+		e.credentialFallbacks = fallbacks
+	}
+}
+
+// SetTrace traces the steps of the scrape, the queries of the scrapers and
+// their results. The scrape connects anew and runs all scrapers, bypassing
+// the instance cache and the scraper cache.
+func SetTrace(trace *Trace) ExporterOpt {
+	return func(e *Exporter) {
+		e.trace = trace
+	}
+}
+
 // withQueryTimeoutContext derives a context bounded by the configured query timeout.
 // When the timeout is disabled (0), it returns the parent context and a no-op
 // cancel so callers can unconditionally `defer cancel()`.
@@ -130,12 +260,18 @@ func New(ctx context.Context, dsn string, scrapers []Scraper, logger *slog.Logge
 		ctx:          ctx,
 		logger:       logger,
 		scrapers:     scrapers,
+		options:      DefaultOptions(),
+		scrapeErrors: NewScrapeErrors(0),
 		maxOpenConns: 2,
 	}
 
 	for _, opt := range opts {
 		opt(e)
 	}
+	if e.trace != nil {
+		e.instanceCache = nil
+		e.scraperCache = nil
+	}
 
 	// Setup extra params for the DSN
 	dsnParams := []string{}
@@ -149,16 +285,29 @@ func New(ctx context.Context, dsn string, scrapers []Scraper, logger *slog.Logge
 		dsnParams = append(dsnParams, sessionSettingsParam)
 	}
 
+	for _, name := range slices.Sorted(maps.Keys(e.sessionSettings)) {
+		dsnParams = append(dsnParams, name+"="+url.QueryEscape(e.sessionSettings[name]))
+	}
+
+	e.dsn = addDSNParams(dsn, dsnParams)
+	if e.fallbackDSN != "" {
+		e.fallbackDSN = addDSNParams(e.fallbackDSN, dsnParams)
+		if e.credentialFallbacks == nil {
+			e.credentialFallbacks = NewCredentialFallbacks()
+		}
+	}
+
+	return e
+}
+
+// addDSNParams appends the params to the DSN.
+func addDSNParams(dsn string, params []string) string {
 	if strings.Contains(dsn, "?") {
 		dsn = dsn + "&"
 	} else {
 		dsn = dsn + "?"
 	}
-	dsn += strings.Join(dsnParams, "&")
-
-	e.dsn = dsn
-
-	return e
+	return dsn + strings.Join(params, "&")
 }
 
 // Describe implements prometheus.Collector.
@@ -166,12 +315,22 @@ func (e *Exporter) Describe(ch chan<- *prometheus.Desc) {
 	ch <- mysqlUp
 	ch <- mysqlScrapeDurationSeconds
 	ch <- mysqlScrapeCollectorSuccess
+	ch <- mysqlScrapeCollectorSkipped
+	ch <- collectorErrorsDesc
+	ch <- collectorLastErrorDesc
+	ch <- connectErrorsDesc
+	ch <- lastConnectErrorDesc
+	ch <- scraperCacheAgeDesc
+	ch <- secondaryPasswordInUseDesc
+	ch <- connectPhaseDurationDesc
+	ch <- connectTLSInfoDesc
 }
 
 // Collect implements prometheus.Collector.
 func (e *Exporter) Collect(ch chan<- prometheus.Metric) {
 	up := e.scrape(e.ctx, ch)
 	ch <- prometheus.MustNewConstMetric(mysqlUp, prometheus.GaugeValue, up)
+	e.scrapeErrors.collect(e.scrapeTarget(), ch)
 }
 
 // scrape collects metrics from the target, returns an up metric value.
@@ -179,48 +338,298 @@ func (e *Exporter) scrape(ctx context.Context, ch chan<- prometheus.Metric) floa
 	var err error
 	scrapeTime := time.Now()
 	versionCtx, versionCancel := e.withQueryTimeoutContext(ctx)
-	instance, err := newInstance(versionCtx, e.dsn, e.maxOpenConns)
+	timings := &connectTimings{}
+	instance, dsn, release, err := e.connect(withConnectTimings(versionCtx, timings))
+	connectTime := time.Now()
 	versionCancel()
 	if err != nil {
 		e.logger.Error("Error opening connection to database", "err", err)
+		e.trace.Printf("Connecting failed: %s", err)
+		e.scrapeErrors.recordConnect(e.scrapeTarget(), err)
 		return 0.0
 	}
-	defer instance.Close()
-	e.instance = instance
+	defer release()
 
 	pingCtx, pingCancel := e.withQueryTimeoutContext(ctx)
 	defer pingCancel()
 	if err := instance.Ping(pingCtx); err != nil {
 		e.logger.Error("Error pinging mysqld", "err", err)
+		e.trace.Printf("Ping failed: %s", err)
+		e.scrapeErrors.recordConnect(e.scrapeTarget(), err)
+		if e.instanceCache != nil {
+			e.instanceCache.discard(instance)
+		}
 		return 0.0
 	}
+	e.scrapeErrors.recordConnect(e.scrapeTarget(), nil)
+	if e.fallbackDSN != "" {
+		inUse := 0.0
+		if dsn == e.fallbackDSN {
+			inUse = 1
+		}
+		ch <- prometheus.MustNewConstMetric(secondaryPasswordInUseDesc, prometheus.GaugeValue, inUse)
+	}
 
 	ch <- prometheus.MustNewConstMetric(mysqlScrapeDurationSeconds, prometheus.GaugeValue, time.Since(scrapeTime).Seconds(), "connection")
+	e.trace.Printf("Connected in %s", time.Since(scrapeTime))
+	timings.collect(connectTime, e.trace, ch)
+
+	instance = instance.withOptions(e.options)
+
+	var capabilities *Capabilities
+	if slices.ContainsFunc(e.scrapers, func(s Scraper) bool { _, ok := s.(RequirementsScraper); return ok }) {
+		capabilitiesCtx, capabilitiesCancel := e.withQueryTimeoutContext(ctx)
+		capabilities = instance.Capabilities(capabilitiesCtx, e.logger)
+		capabilitiesCancel()
+	}
 
-	version := instance.versionMajorMinor
+	var scrapers []Scraper
+	for _, scraper := range e.scrapers {
+		if reason, detail := skipReason(scraper, instance, capabilities); reason != "" {
+			e.logger.Debug("Skipping collector", "collector", scraper.Name(), "reason", reason, "detail", detail)
+			e.trace.Printf("[%s] Skipped: %s, %s", scraper.Name(), reason, detail)
+			ch <- prometheus.MustNewConstMetric(mysqlScrapeCollectorSkipped, prometheus.GaugeValue, 1, "collect."+scraper.Name(), reason)
+			continue
+		}
+		scrapers = append(scrapers, scraper)
+	}
+	e.runScrapers(ctx, scrapeTime, scrapers, instance, ch)
+	return 1.0
+}
+
+// priority returns the priority of the scraper.
+func (e *Exporter) priority(scraper Scraper) int {
+	if priority, ok := e.scraperPriorities[scraper.Name()]; ok {
+		return priority
+	}
+	return defaultScraperPriorities[scraper.Name()]
+}
+
+// runScrapers runs the scrapers by descending priority, with at most the
+// scrape concurrency of them at the same time. One of the slots is reserved
+// for the scrapers with a positive priority, so that they always find one.
+// If ctx has a deadline, the scrapers with a negative priority are cancelled
+// once lowPriorityDeadline of the time from the start of the scrape to the
+// deadline passed, so that their queries do not delay the others. Scrapers
+// still waiting for a slot once their context is done are not run and
+// reported as failed.
+func (e *Exporter) runScrapers(ctx context.Context, scrapeTime time.Time, scrapers []Scraper, instance *Instance, ch chan<- prometheus.Metric) {
+	scrapers = slices.Clone(scrapers)
+	slices.SortStableFunc(scrapers, func(a, b Scraper) int {
+		return cmp.Compare(e.priority(b), e.priority(a))
+	})
+
+	lowPriorityCtx := ctx
+	if deadline, ok := ctx.Deadline(); ok {
+		var cancel context.CancelFunc
+		lowPriorityCtx, cancel = context.WithDeadline(ctx, scrapeTime.Add(time.Duration(float64(deadline.Sub(scrapeTime))*lowPriorityDeadline)))
+		defer cancel()
+	}
+
+	concurrency := e.scrapeConcurrency
+	if concurrency <= 0 {
+		concurrency = e.maxOpenConns
+	}
+	concurrency = max(concurrency, 1)
+	reserved := min(concurrency-1, 1)
+	slots := make(chan struct{}, concurrency-reserved)
+	reservedSlots := make(chan struct{}, reserved)
 
 	var wg sync.WaitGroup
 	defer wg.Wait()
-	for _, scraper := range e.scrapers {
-		if version < scraper.Version() {
+	for _, scraper := range scrapers {
+		scraperCtx := ctx
+		if e.priority(scraper) < 0 {
+			scraperCtx = lowPriorityCtx
+		}
+		// Scrapers with a positive priority take the reserved slot first and
+		// may wait for any slot, the others only for the shared ones.
+		var slot, reservedSlot chan struct{}
+		if e.priority(scraper) > 0 {
+			reservedSlot = reservedSlots
+		}
+		if scraperCtx.Err() == nil {
+			select {
+			case reservedSlot <- struct{}{}:
+				slot = reservedSlot
+			default:
+				select {
+				case slots <- struct{}{}:
+					slot = slots
+				case reservedSlot <- struct{}{}:
+					slot = reservedSlot
+				case <-scraperCtx.Done():
+				}
+			}
+		}
+		if slot == nil {
+			e.reportNotRun(scraperCtx, scraper, ch)
 			continue
 		}
 
 		wg.Go(func() {
-			label := "collect." + scraper.Name()
-			scrapeTime := time.Now()
-			collectorSuccess := 1.0
-			scrapeCtx, cancel := e.withQueryTimeoutContext(ctx)
-			defer cancel()
-			if err := scraper.Scrape(scrapeCtx, instance, ch, e.logger.With("scraper", scraper.Name())); err != nil {
-				e.logger.Error("Error from scraper", "scraper", scraper.Name(), "target", e.getTargetFromDsn(), "err", err)
-				collectorSuccess = 0.0
+			defer func() { <-slot }()
+			e.runScraper(scraperCtx, scraper, instance, ch)
+		})
+	}
+}
+
+// reportNotRun reports the scraper as failed without running it, as its
+// context was done before it got a slot.
+func (e *Exporter) reportNotRun(ctx context.Context, scraper Scraper, ch chan<- prometheus.Metric) {
+	label := "collect." + scraper.Name()
+	err := fmt.Errorf("not run before the end of the scrape: %w", ctx.Err())
+	e.logger.Error("Error from scraper", "scraper", scraper.Name(), "target", e.getTargetFromDsn(), "err", err)
+	e.trace.Printf("[%s] Failed: %s", scraper.Name(), err)
+	e.scrapeErrors.record(e.scrapeTarget(), label, err)
+	ch <- prometheus.MustNewConstMetric(mysqlScrapeCollectorSuccess, prometheus.GaugeValue, 0, label)
+	ch <- prometheus.MustNewConstMetric(mysqlScrapeDurationSeconds, prometheus.GaugeValue, 0, label)
+}
+
+// skipReason returns why the scraper does not support the server of the
+// instance with the given capabilities, or an empty string if it does. The
+// detail describes the missing flavor, version or requirement.
+func skipReason(scraper Scraper, instance *Instance, capabilities *Capabilities) (reason, detail string) {
+	if s, ok := scraper.(VersionRangesScraper); ok {
+		ranges := s.VersionRanges()
+		versionRange, ok := ranges[instance.flavor]
+		if !ok && instance.flavor == FlavorPercona {
+			versionRange, ok = ranges[FlavorMySQL]
+		}
+		if !ok {
+			return skipReasonFlavor, "flavor " + instance.flavor + " is not supported"
+		}
+		if !versionRange(instance.version) {
+			return skipReasonVersion, "version " + instance.version.String() + " is not supported"
+		}
+	} else if instance.versionMajorMinor < scraper.Version() {
+		return skipReasonVersion, fmt.Sprintf("version %g is not supported", instance.versionMajorMinor)
+	}
+	if s, ok := scraper.(RequirementsScraper); ok {
+		if missing := capabilities.Missing(s.Requirements()); missing != "" {
+			return skipReasonNotApplicable, missing + " is missing"
+		}
+	}
+	return "", ""
+}
+
+// runScraper runs the scraper, or replays its cached metrics if it has a
+// minimum interval which did not pass yet.
+func (e *Exporter) runScraper(ctx context.Context, scraper Scraper, instance *Instance, ch chan<- prometheus.Metric) {
+	label := "collect." + scraper.Name()
+	interval := e.scraperCache.interval(scraper.Name())
+	if interval <= 0 {
+		collectorSuccess, duration := e.scrapeOnce(ctx, scraper, instance, ch)
+		ch <- prometheus.MustNewConstMetric(mysqlScrapeCollectorSuccess, prometheus.GaugeValue, collectorSuccess, label)
+		ch <- prometheus.MustNewConstMetric(mysqlScrapeDurationSeconds, prometheus.GaugeValue, duration.Seconds(), label)
+		return
+	}
+
+	entry := e.scraperCache.entry(e.dsn, fmt.Sprintf("%+v", e.options), scraper.Name())
+	defer entry.mu.Unlock()
+	if !entry.fresh(e.scraperCache.now(), interval) {
+		// Record the metrics of the scraper while passing them on.
+		recorded := make(chan prometheus.Metric)
+		var metrics []prometheus.Metric
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for m := range recorded {
+				metrics = append(metrics, m)
+				ch <- m
 			}
+		}()
+		collectorSuccess, duration := e.scrapeOnce(ctx, scraper, instance, recorded)
+		close(recorded)
+		<-done
+		if collectorSuccess == 0 {
+			// Failed scrapes are not cached, the scraper runs again on the next scrape.
 			ch <- prometheus.MustNewConstMetric(mysqlScrapeCollectorSuccess, prometheus.GaugeValue, collectorSuccess, label)
-			ch <- prometheus.MustNewConstMetric(mysqlScrapeDurationSeconds, prometheus.GaugeValue, time.Since(scrapeTime).Seconds(), label)
-		})
+			ch <- prometheus.MustNewConstMetric(mysqlScrapeDurationSeconds, prometheus.GaugeValue, duration.Seconds(), label)
+			return
+		}
+		entry.metrics = metrics
+		entry.duration = duration
+		entry.timestamp = e.scraperCache.now()
+	} else {
+		for _, m := range entry.metrics {
+			ch <- m
+		}
 	}
-	return 1.0
+	ch <- prometheus.MustNewConstMetric(mysqlScrapeCollectorSuccess, prometheus.GaugeValue, 1, label)
+	ch <- prometheus.MustNewConstMetric(mysqlScrapeDurationSeconds, prometheus.GaugeValue, entry.duration.Seconds(), label)
+	ch <- prometheus.MustNewConstMetric(scraperCacheAgeDesc, prometheus.GaugeValue, e.scraperCache.now().Sub(entry.timestamp).Seconds(), label)
+}
+
+// scrapeOnce runs the scraper, returning whether it succeeded and how long it took.
+func (e *Exporter) scrapeOnce(ctx context.Context, scraper Scraper, instance *Instance, ch chan<- prometheus.Metric) (float64, time.Duration) {
+	scrapeTime := time.Now()
+	collectorSuccess := 1.0
+	scrapeCtx, cancel := e.withQueryTimeoutContext(ctx)
+	defer cancel()
+	if e.trace != nil {
+		scrapeCtx = context.WithValue(scrapeCtx, traceScraperKey{}, scraper.Name())
+		e.trace.Printf("[%s] Started", scraper.Name())
+	}
+	err := scraper.Scrape(scrapeCtx, instance, ch, e.logger.With("scraper", scraper.Name()))
+	if err != nil {
+		e.logger.Error("Error from scraper", "scraper", scraper.Name(), "target", e.getTargetFromDsn(), "err", err)
+		collectorSuccess = 0.0
+		e.trace.Printf("[%s] Failed after %s: %s", scraper.Name(), time.Since(scrapeTime), err)
+	} else {
+		e.trace.Printf("[%s] Done in %s", scraper.Name(), time.Since(scrapeTime))
+	}
+	e.scrapeErrors.record(e.scrapeTarget(), "collect."+scraper.Name(), err)
+	return collectorSuccess, time.Since(scrapeTime)
+}
+
+// connect returns an instance connected to the target, either from the
+// instance cache or newly opened. The returned release function must be
+// called once the scrape is done.
+func (e *Exporter) connect(ctx context.Context) (*Instance, string, func(), error) {
+	if e.fallbackDSN == "" {
+		instance, release, err := e.connectDSN(ctx, e.dsn)
+		return instance, e.dsn, release, err
+	}
+
+	// Try the DSN which was accepted last first, and the other one if the
+	// server denies access with it.
+	dsn, otherDSN := e.dsn, e.fallbackDSN
+	if e.credentialFallbacks.inUse(e.dsn) {
+		dsn, otherDSN = otherDSN, dsn
+	}
+	instance, release, err := e.connectDSN(ctx, dsn)
+	if !isAccessDenied(err) {
+		return instance, dsn, release, err
+	}
+	instance, release, otherErr := e.connectDSN(ctx, otherDSN)
+	if otherErr != nil {
+		return nil, "", nil, fmt.Errorf("%w (with the other password: %w)", err, otherErr)
+	}
+	secondary := otherDSN == e.fallbackDSN
+	e.logger.Info("Access denied, connected with the other password", "secondary_password", secondary)
+	e.trace.Printf("Access denied, connected with the other password (secondary password: %t)", secondary)
+	e.credentialFallbacks.set(e.dsn, secondary)
+	return instance, otherDSN, release, nil
+}
+
+// connectDSN connects to the server of the DSN, reusing a cached connection
+// if possible.
+func (e *Exporter) connectDSN(ctx context.Context, dsn string) (*Instance, func(), error) {
+	if e.instanceCache != nil {
+		return e.instanceCache.get(ctx, dsn, e.maxOpenConns, e.authModule)
+	}
+	var instance *Instance
+	var err error
+	if e.trace != nil {
+		instance, err = newTracedInstance(ctx, dsn, e.maxOpenConns, e.trace)
+	} else {
+		instance, err = NewInstance(ctx, dsn, e.maxOpenConns)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return instance, func() { instance.Close() }, nil
 }
 
 func (e *Exporter) getTargetFromDsn() string {
@@ -232,3 +641,9 @@ func (e *Exporter) getTargetFromDsn() string {
 	}
 	return dsnConfig.Addr
 }
+
+// scrapeTarget returns the target and auth module the errors of the scrape
+// are counted for.
+func (e *Exporter) scrapeTarget() scrapeTarget {
+	return scrapeTarget{target: targetFromDsn(e.dsn), authModule: e.authModule}
+}
@@ -18,9 +18,11 @@ import (
 	"errors"
 	"log/slog"
 	"os"
+	"slices"
 	"testing"
 	"time"
 
+	"github.com/blang/semver/v4"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/common/promslog"
 	"github.com/smartystreets/goconvey/convey"
@@ -147,23 +149,241 @@ func TestExporterWithOpts(t *testing.T) {
 			)
 			convey.So(exporter.dsn, convey.ShouldEqual, "root@/mysql?parseTime=true&lock_wait_timeout=30&log_slow_filter=%27tmp_table_on_disk,filesort_on_disk%27")
 		})
+
+		convey.Convey("SetSessionSettings", func() {
+			exporter := New(
+				context.Background(),
+				dsn,
+				[]Scraper{},
+				promslog.NewNopLogger(),
+				SetSessionSettings(map[string]string{"time_zone": "'+00:00'", "max_execution_time": "1000"}),
+			)
+			convey.So(exporter.dsn, convey.ShouldEqual, "root@/mysql?max_execution_time=1000&time_zone=%27%2B00%3A00%27")
+		})
 	})
 }
 
 type mockScraper struct {
 	name     string
-	validate func(ctx context.Context, instance *instance) error
+	validate func(ctx context.Context, instance *Instance) error
+	emit     func(ch chan<- prometheus.Metric)
 }
 
 func (s *mockScraper) Name() string     { return s.name }
 func (s *mockScraper) Help() string     { return "mock scraper for testing" }
 func (s *mockScraper) Version() float64 { return 0 }
 
-func (s *mockScraper) Scrape(ctx context.Context, instance *instance, _ chan<- prometheus.Metric, _ *slog.Logger) error {
+func (s *mockScraper) Scrape(ctx context.Context, instance *Instance, ch chan<- prometheus.Metric, _ *slog.Logger) error {
+	var err error
 	if s.validate != nil {
-		return s.validate(ctx, instance)
+		err = s.validate(ctx, instance)
 	}
-	return nil
+	if s.emit != nil {
+		s.emit(ch)
+	}
+	return err
+}
+
+// reasonOf returns the reason of skipReason without its detail.
+func reasonOf(reason, _ string) string {
+	return reason
+}
+
+func TestSkipReason(t *testing.T) {
+	mysql57 := &Instance{flavor: FlavorMySQL, version: semver.MustParse("5.7.44"), versionMajorMinor: 5.7}
+	percona80 := &Instance{flavor: FlavorPercona, version: semver.MustParse("8.0.36"), versionMajorMinor: 8.0}
+	mariadb105 := &Instance{flavor: FlavorMariaDB, version: semver.MustParse("10.5.22"), versionMajorMinor: 10.5}
+	mariadb106 := &Instance{flavor: FlavorMariaDB, version: semver.MustParse("10.6.16"), versionMajorMinor: 10.6}
+
+	tests := []struct {
+		scraper  Scraper
+		instance *Instance
+		want     string
+	}{
+		{ScrapeGlobalStatus{}, mysql57, ""},
+		{ScrapeEngineTokudbStatus{}, &Instance{flavor: FlavorMySQL, versionMajorMinor: 5.5}, skipReasonVersion},
+		{ScrapePerfReplicationApplierStatsByWorker{}, mysql57, skipReasonVersion},
+		{ScrapePerfReplicationApplierStatsByWorker{}, percona80, ""},
+		{ScrapePerfReplicationApplierStatsByWorker{}, mariadb106, skipReasonFlavor},
+		{ScrapeSysUserSummary{}, mariadb105, skipReasonVersion},
+		{ScrapeSysUserSummary{}, mariadb106, ""},
+		// MariaDB 10.x is not taken for MySQL 10.x.
+		{ScrapeInfoSchemaInnodbTablespaces{}, mysql57, ""},
+		{ScrapeInfoSchemaInnodbTablespaces{}, &Instance{flavor: FlavorMariaDB, version: semver.MustParse("10.1.48"), versionMajorMinor: 10.1}, skipReasonVersion},
+		{ScrapeInfoSchemaInnodbTablespaces{}, mariadb105, ""},
+		{ScrapePerfEventsStatementsSum{}, percona80, ""},
+		{ScrapePerfEventsStatementsSum{}, &Instance{flavor: FlavorMariaDB, version: semver.MustParse("10.4.32"), versionMajorMinor: 10.4}, skipReasonVersion},
+		{ScrapePerfEventsStatementsSum{}, mariadb105, ""},
+		{ScrapeQueryResponseTime{}, &Instance{flavor: FlavorMariaDB, version: semver.MustParse("10.0.3"), versionMajorMinor: 10.0}, skipReasonVersion},
+		{ScrapeRocksDBPerfContext{}, &Instance{flavor: FlavorMariaDB, version: semver.MustParse("10.1.48"), versionMajorMinor: 10.1}, skipReasonVersion},
+		{ScrapeReplicaHost{}, mysql57, ""},
+		{ScrapeReplicaHost{}, mariadb106, skipReasonFlavor},
+	}
+	convey.Convey("Skip reasons", t, func() {
+		for _, tt := range tests {
+			convey.So(reasonOf(skipReason(tt.scraper, tt.instance, nil)), convey.ShouldEqual, tt.want)
+		}
+		_, detail := skipReason(ScrapeSysUserSummary{}, mariadb105, nil)
+		convey.So(detail, convey.ShouldEqual, "version 10.5.22 is not supported")
+		_, detail = skipReason(ScrapePerfReplicationApplierStatsByWorker{}, mariadb106, nil)
+		convey.So(detail, convey.ShouldEqual, "flavor mariadb is not supported")
+	})
+
+	convey.Convey("Built-in scrapers declare version ranges per flavor", t, func() {
+		for scraper := range Scrapers() {
+			_, ok := scraper.(VersionRangesScraper)
+			convey.So(ok, convey.ShouldBeTrue)
+		}
+	})
+}
+
+func TestRunScrapersPriorities(t *testing.T) {
+	type result struct {
+		name string
+		err  error
+	}
+	// The scrapers report when they start and block until they are released
+	// or their context is done, reporting the error of the context.
+	newScrapers := func(started chan<- string, release <-chan struct{}, done chan<- result) []Scraper {
+		var scrapers []Scraper
+		for _, name := range []string{"tables", "processlist", globalStatus, "user"} {
+			scrapers = append(scrapers, &mockScraper{name: name, validate: func(ctx context.Context, _ *Instance) error {
+				started <- name
+				var err error
+				select {
+				case <-release:
+				case <-ctx.Done():
+					err = ctx.Err()
+				}
+				done <- result{name: name, err: err}
+				return err
+			}})
+		}
+		return scrapers
+	}
+
+	run := func(ctx context.Context, scrapeTime time.Time, scrapers []Scraper, opts ...ExporterOpt) <-chan map[string]float64 {
+		exporter := New(ctx, dsn, scrapers, promslog.NewNopLogger(), opts...)
+		ch := make(chan prometheus.Metric)
+		go func() {
+			exporter.runScrapers(ctx, scrapeTime, scrapers, &Instance{}, ch)
+			close(ch)
+		}()
+		successes := make(chan map[string]float64, 1)
+		go func() {
+			success := map[string]float64{}
+			for m := range ch {
+				if m.Desc() == mysqlScrapeCollectorSuccess {
+					r := readMetric(m)
+					success[r.labels["collector"]] = r.value
+				}
+			}
+			successes <- success
+		}()
+		return successes
+	}
+
+	convey.Convey("Scrapers run by priority", t, func() {
+		started, release, done := make(chan string), make(chan struct{}), make(chan result, 4)
+		successes := run(context.Background(), time.Now(), newScrapers(started, release, done),
+			SetScrapeConcurrency(1), SetScraperPriorities(map[string]int{"tables": -10, "user": 5}))
+		var order []string
+		for range 4 {
+			order = append(order, <-started)
+			release <- struct{}{}
+		}
+		convey.So(order, convey.ShouldResemble, []string{globalStatus, "user", "processlist", "tables"})
+		convey.So(<-successes, convey.ShouldResemble, map[string]float64{
+			"collect.tables": 1, "collect.processlist": 1, "collect." + globalStatus: 1, "collect.user": 1,
+		})
+	})
+
+	convey.Convey("Low priority scrapers are cancelled first", t, func() {
+		started, release, done := make(chan string, 4), make(chan struct{}), make(chan result, 4)
+		// The scrape started long enough ago for the low priority deadline
+		// to pass shortly after the scrapers started, while the deadline of
+		// the scrape does not.
+		now := time.Now()
+		ctx, cancel := context.WithDeadline(context.Background(), now.Add(time.Hour))
+		defer cancel()
+		successes := run(ctx, now.Add(-9*time.Hour+2*time.Second), newScrapers(started, release, done),
+			SetScrapeConcurrency(4), SetScraperPriorities(map[string]int{"tables": -10}))
+		for range 4 {
+			<-started
+		}
+		// The other scrapers are still blocked until they are released.
+		convey.So(<-done, convey.ShouldResemble, result{name: "tables", err: context.DeadlineExceeded})
+		close(release)
+		for range 3 {
+			convey.So((<-done).err, convey.ShouldBeNil)
+		}
+		convey.So(<-successes, convey.ShouldResemble, map[string]float64{
+			"collect.tables": 0, "collect.processlist": 1, "collect." + globalStatus: 1, "collect.user": 1,
+		})
+	})
+
+	convey.Convey("A slot is reserved for high priority scrapers", t, func() {
+		started, release, done := make(chan string, 4), make(chan struct{}), make(chan result, 4)
+		successes := run(context.Background(), time.Now(), newScrapers(started, release, done),
+			SetScrapeConcurrency(2), SetScraperPriorities(map[string]int{"user": -1}))
+		// The reserved slot is taken by global_status, the other scrapers
+		// share the remaining slot.
+		first := []string{<-started, <-started}
+		slices.Sort(first)
+		convey.So(first, convey.ShouldResemble, []string{globalStatus, "tables"})
+		select {
+		case name := <-started:
+			t.Fatalf("unexpected start of %s without a free slot", name)
+		case <-time.After(50 * time.Millisecond):
+		}
+		close(release)
+		convey.So(<-started, convey.ShouldEqual, "processlist")
+		convey.So(<-started, convey.ShouldEqual, "user")
+		convey.So(<-successes, convey.ShouldResemble, map[string]float64{
+			"collect.tables": 1, "collect.processlist": 1, "collect." + globalStatus: 1, "collect.user": 1,
+		})
+	})
+
+	convey.Convey("Scrapers without a slot at the end of the scrape are not run", t, func() {
+		started, done := make(chan string, 4), make(chan result, 4)
+		scrapeErrors := NewScrapeErrors(time.Hour)
+		ctx, cancel := context.WithCancel(context.Background())
+		successes := run(ctx, time.Now(), newScrapers(started, nil, done), SetScrapeConcurrency(1), SetScrapeErrors(scrapeErrors, "client"))
+		convey.So(<-started, convey.ShouldEqual, globalStatus)
+		cancel()
+		convey.So(<-done, convey.ShouldResemble, result{name: globalStatus, err: context.Canceled})
+		convey.So(<-successes, convey.ShouldResemble, map[string]float64{
+			"collect.tables": 0, "collect.processlist": 0, "collect." + globalStatus: 0, "collect.user": 0,
+		})
+		convey.So(started, convey.ShouldBeEmpty)
+
+		scrapeErrors.mu.Lock()
+		defer scrapeErrors.mu.Unlock()
+		convey.So(scrapeErrors.targets, convey.ShouldHaveLength, 1)
+		for _, target := range scrapeErrors.targets {
+			for _, name := range []string{"tables", "processlist", "user"} {
+				errs := target.collectors["collect."+name]
+				convey.So(errs, convey.ShouldNotBeNil)
+				convey.So(errs.counts, convey.ShouldResemble, map[scrapeErrorKey]float64{{class: errorClassCanceled}: 1})
+			}
+		}
+	})
+
+	convey.Convey("Cancelling the scrape cancels all scrapers", t, func() {
+		started, done := make(chan string, 4), make(chan result, 4)
+		ctx, cancel := context.WithCancel(context.Background())
+		successes := run(ctx, time.Now(), newScrapers(started, nil, done), SetScrapeConcurrency(4))
+		for range 4 {
+			<-started
+		}
+		cancel()
+		for range 4 {
+			convey.So((<-done).err, convey.ShouldEqual, context.Canceled)
+		}
+		convey.So(<-successes, convey.ShouldResemble, map[string]float64{
+			"collect.tables": 0, "collect.processlist": 0, "collect." + globalStatus: 0, "collect.user": 0,
+		})
+	})
 }
 
 func TestScrapeContextTimeout(t *testing.T) {
@@ -175,7 +395,7 @@ func TestScrapeContextTimeout(t *testing.T) {
 	const timeout = 5 * time.Second
 	scraper := &mockScraper{
 		name: "timeout_test",
-		validate: func(ctx context.Context, _ *instance) error {
+		validate: func(ctx context.Context, _ *Instance) error {
 			deadline, ok := ctx.Deadline()
 			if !ok {
 				t.Error("scraper context should have a deadline")
@@ -213,13 +433,13 @@ func TestNewInstanceMaxOpenConnections(t *testing.T) {
 	}
 
 	const want = 5
-	inst, err := newInstance(context.Background(), connDSN, want)
+	inst, err := NewInstance(context.Background(), connDSN, want)
 	if err != nil {
-		t.Fatalf("newInstance: %v", err)
+		t.Fatalf("NewInstance: %v", err)
 	}
 	defer inst.Close()
 
-	if got := inst.getDB().Stats().MaxOpenConnections; got != want {
+	if got := inst.DB().Stats().MaxOpenConnections; got != want {
 		t.Errorf("MaxOpenConnections = %d, want %d", got, want)
 	}
 }
@@ -240,8 +460,8 @@ func TestSlowScraperDoesNotBlockFastScraper(t *testing.T) {
 
 	slowScraper := &mockScraper{
 		name: "slow",
-		validate: func(ctx context.Context, instance *instance) error {
-			conn, err := instance.getDB().Conn(ctx)
+		validate: func(ctx context.Context, instance *Instance) error {
+			conn, err := instance.DB().Conn(ctx)
 			if err != nil {
 				close(slowReady)
 				slowResult <- err
@@ -261,7 +481,7 @@ func TestSlowScraperDoesNotBlockFastScraper(t *testing.T) {
 
 	fastScraper := &mockScraper{
 		name: "fast",
-		validate: func(ctx context.Context, instance *instance) error {
+		validate: func(ctx context.Context, instance *Instance) error {
 			select {
 			case <-slowReady:
 			case <-ctx.Done():
@@ -270,7 +490,7 @@ func TestSlowScraperDoesNotBlockFastScraper(t *testing.T) {
 			}
 
 			var result int
-			err := instance.getDB().QueryRowContext(ctx, "SELECT 1").Scan(&result)
+			err := instance.DB().QueryRowContext(ctx, "SELECT 1").Scan(&result)
 			fastResult <- err
 			return err
 		},
@@ -23,6 +23,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/blang/semver/v4"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -98,9 +99,17 @@ func (ScrapeGlobalStatus) Version() float64 {
 	return 5.1
 }
 
+// VersionRanges of the servers from which scraper is available.
+func (ScrapeGlobalStatus) VersionRanges() map[string]semver.Range {
+	return map[string]semver.Range{
+		FlavorMySQL:   semver.MustParseRange(">=5.1.0"),
+		FlavorMariaDB: semver.MustParseRange(">=5.1.0"),
+	}
+}
+
 // Scrape collects data from database connection and sends it over channel as prometheus metric.
-func (ScrapeGlobalStatus) Scrape(ctx context.Context, instance *instance, ch chan<- prometheus.Metric, logger *slog.Logger) error {
-	db := instance.getDB()
+func (ScrapeGlobalStatus) Scrape(ctx context.Context, instance *Instance, ch chan<- prometheus.Metric, logger *slog.Logger) error {
+	db := instance.DB()
 	globalStatusRows, err := db.QueryContext(ctx, globalStatusQuery)
 	if err != nil {
 		return err
@@ -227,4 +236,4 @@ func (ScrapeGlobalStatus) Scrape(ctx context.Context, instance *instance, ch cha
 }
 
 // check interface
-var _ Scraper = ScrapeGlobalStatus{}
+var _ VersionRangesScraper = ScrapeGlobalStatus{}
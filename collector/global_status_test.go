@@ -30,7 +30,7 @@ func TestScrapeGlobalStatus(t *testing.T) {
 		t.Fatalf("error opening a stub database connection: %s", err)
 	}
 	defer db.Close()
-	inst := &instance{db: db}
+	inst := &Instance{db: db}
 
 	columns := []string{"Variable_name", "Value"}
 	rows := sqlmock.NewRows(columns).
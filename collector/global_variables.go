@@ -23,6 +23,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/blang/semver/v4"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -140,9 +141,17 @@ func (ScrapeGlobalVariables) Version() float64 {
 	return 5.1
 }
 
+// VersionRanges of the servers from which scraper is available.
+func (ScrapeGlobalVariables) VersionRanges() map[string]semver.Range {
+	return map[string]semver.Range{
+		FlavorMySQL:   semver.MustParseRange(">=5.1.0"),
+		FlavorMariaDB: semver.MustParseRange(">=5.1.0"),
+	}
+}
+
 // Scrape collects data from database connection and sends it over channel as prometheus metric.
-func (ScrapeGlobalVariables) Scrape(ctx context.Context, instance *instance, ch chan<- prometheus.Metric, logger *slog.Logger) error {
-	db := instance.getDB()
+func (ScrapeGlobalVariables) Scrape(ctx context.Context, instance *Instance, ch chan<- prometheus.Metric, logger *slog.Logger) error {
+	db := instance.DB()
 	globalVariablesRows, err := db.QueryContext(ctx, globalVariablesQuery)
 	if err != nil {
 		return err
@@ -253,4 +262,4 @@ func validPrometheusName(s string) string {
 }
 
 // check interface
-var _ Scraper = ScrapeGlobalVariables{}
+var _ VersionRangesScraper = ScrapeGlobalVariables{}
@@ -22,7 +22,7 @@ import (
 	"log/slog"
 	"strconv"
 
-	"github.com/alecthomas/kingpin/v2"
+	"github.com/blang/semver/v4"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -36,21 +36,6 @@ const (
 	heartbeatQuery = "SELECT UNIX_TIMESTAMP(ts), UNIX_TIMESTAMP(%s), server_id from `%s`.`%s`"
 )
 
-var (
-	collectHeartbeatDatabase = kingpin.Flag(
-		"collect.heartbeat.database",
-		"Database from where to collect heartbeat data",
-	).Default("heartbeat").String()
-	collectHeartbeatTable = kingpin.Flag(
-		"collect.heartbeat.table",
-		"Table from where to collect heartbeat data",
-	).Default("heartbeat").String()
-	collectHeartbeatUtc = kingpin.Flag(
-		"collect.heartbeat.utc",
-		"Use UTC for timestamps of the current server (`pt-heartbeat` is called with `--utc`)",
-	).Bool()
-)
-
 // Metric descriptors.
 var (
 	HeartbeatStoredDesc = prometheus.NewDesc(
@@ -91,18 +76,26 @@ func (ScrapeHeartbeat) Version() float64 {
 	return 5.1
 }
 
+// VersionRanges of the servers from which scraper is available.
+func (ScrapeHeartbeat) VersionRanges() map[string]semver.Range {
+	return map[string]semver.Range{
+		FlavorMySQL:   semver.MustParseRange(">=5.1.0"),
+		FlavorMariaDB: semver.MustParseRange(">=5.1.0"),
+	}
+}
+
 // nowExpr returns a current timestamp expression.
-func nowExpr() string {
-	if *collectHeartbeatUtc {
+func nowExpr(utc bool) string {
+	if utc {
 		return "UTC_TIMESTAMP(6)"
 	}
 	return "NOW(6)"
 }
 
 // Scrape collects data from database connection and sends it over channel as prometheus metric.
-func (ScrapeHeartbeat) Scrape(ctx context.Context, instance *instance, ch chan<- prometheus.Metric, logger *slog.Logger) error {
-	db := instance.getDB()
-	query := fmt.Sprintf(heartbeatQuery, nowExpr(), *collectHeartbeatDatabase, *collectHeartbeatTable)
+func (ScrapeHeartbeat) Scrape(ctx context.Context, instance *Instance, ch chan<- prometheus.Metric, logger *slog.Logger) error {
+	db := instance.DB()
+	query := fmt.Sprintf(heartbeatQuery, nowExpr(instance.options.HeartbeatUTC), instance.options.HeartbeatDatabase, instance.options.HeartbeatTable)
 	heartbeatRows, err := db.QueryContext(ctx, query)
 	if err != nil {
 		return err
@@ -149,4 +142,4 @@ func (ScrapeHeartbeat) Scrape(ctx context.Context, instance *instance, ch chan<-
 }
 
 // check interface
-var _ Scraper = ScrapeHeartbeat{}
+var _ VersionRangesScraper = ScrapeHeartbeat{}
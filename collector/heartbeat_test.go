@@ -19,7 +19,6 @@ import (
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
-	"github.com/alecthomas/kingpin/v2"
 	"github.com/prometheus/client_golang/prometheus"
 	dto "github.com/prometheus/client_model/go"
 	"github.com/prometheus/common/promslog"
@@ -27,26 +26,21 @@ import (
 )
 
 type ScrapeHeartbeatTestCase struct {
-	Args    []string
-	Columns []string
-	Query   string
+	Database string
+	Table    string
+	UTC      bool
+	Columns  []string
+	Query    string
 }
 
 var ScrapeHeartbeatTestCases = []ScrapeHeartbeatTestCase{
 	{
-		[]string{
-			"--collect.heartbeat.database", "heartbeat-test",
-			"--collect.heartbeat.table", "heartbeat-test",
-		},
+		"heartbeat-test", "heartbeat-test", false,
 		[]string{"UNIX_TIMESTAMP(ts)", "UNIX_TIMESTAMP(NOW(6))", "server_id"},
 		"SELECT UNIX_TIMESTAMP(ts), UNIX_TIMESTAMP(NOW(6)), server_id from `heartbeat-test`.`heartbeat-test`",
 	},
 	{
-		[]string{
-			"--collect.heartbeat.database", "heartbeat-test",
-			"--collect.heartbeat.table", "heartbeat-test",
-			"--collect.heartbeat.utc",
-		},
+		"heartbeat-test", "heartbeat-test", true,
 		[]string{"UNIX_TIMESTAMP(ts)", "UNIX_TIMESTAMP(UTC_TIMESTAMP(6))", "server_id"},
 		"SELECT UNIX_TIMESTAMP(ts), UNIX_TIMESTAMP(UTC_TIMESTAMP(6)), server_id from `heartbeat-test`.`heartbeat-test`",
 	},
@@ -54,18 +48,17 @@ var ScrapeHeartbeatTestCases = []ScrapeHeartbeatTestCase{
 
 func TestScrapeHeartbeat(t *testing.T) {
 	for _, tt := range ScrapeHeartbeatTestCases {
-		t.Run(fmt.Sprint(tt.Args), func(t *testing.T) {
-			_, err := kingpin.CommandLine.Parse(tt.Args)
-			if err != nil {
-				t.Fatal(err)
-			}
-
+		t.Run(fmt.Sprint(tt.Database, tt.Table, tt.UTC), func(t *testing.T) {
 			db, mock, err := sqlmock.New()
 			if err != nil {
 				t.Fatalf("error opening a stub database connection: %s", err)
 			}
 			defer db.Close()
-			inst := &instance{db: db}
+			options := DefaultOptions()
+			options.HeartbeatDatabase = tt.Database
+			options.HeartbeatTable = tt.Table
+			options.HeartbeatUTC = tt.UTC
+			inst := &Instance{db: db, options: options}
 
 			rows := sqlmock.NewRows(tt.Columns).
 				AddRow("1487597613.001320", "1487598113.448042", 1)
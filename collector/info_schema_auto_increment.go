@@ -19,6 +19,7 @@ import (
 	"context"
 	"log/slog"
 
+	"github.com/blang/semver/v4"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -68,9 +69,17 @@ func (ScrapeAutoIncrementColumns) Version() float64 {
 	return 5.1
 }
 
+// VersionRanges of the servers from which scraper is available.
+func (ScrapeAutoIncrementColumns) VersionRanges() map[string]semver.Range {
+	return map[string]semver.Range{
+		FlavorMySQL:   semver.MustParseRange(">=5.1.0"),
+		FlavorMariaDB: semver.MustParseRange(">=5.1.0"),
+	}
+}
+
 // Scrape collects data from database connection and sends it over channel as prometheus metric.
-func (ScrapeAutoIncrementColumns) Scrape(ctx context.Context, instance *instance, ch chan<- prometheus.Metric, logger *slog.Logger) error {
-	db := instance.getDB()
+func (ScrapeAutoIncrementColumns) Scrape(ctx context.Context, instance *Instance, ch chan<- prometheus.Metric, logger *slog.Logger) error {
+	db := instance.DB()
 	autoIncrementRows, err := db.QueryContext(ctx, infoSchemaAutoIncrementQuery)
 	if err != nil {
 		return err
@@ -101,4 +110,4 @@ func (ScrapeAutoIncrementColumns) Scrape(ctx context.Context, instance *instance
 }
 
 // check interface
-var _ Scraper = ScrapeAutoIncrementColumns{}
+var _ VersionRangesScraper = ScrapeAutoIncrementColumns{}
@@ -21,6 +21,7 @@ import (
 	"log/slog"
 	"strings"
 
+	"github.com/blang/semver/v4"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -158,10 +159,23 @@ func (ScrapeClientStat) Version() float64 {
 	return 5.5
 }
 
+// VersionRanges of the servers from which scraper is available.
+func (ScrapeClientStat) VersionRanges() map[string]semver.Range {
+	return map[string]semver.Range{
+		FlavorMySQL:   semver.MustParseRange(">=5.5.0"),
+		FlavorMariaDB: semver.MustParseRange(">=5.5.0"),
+	}
+}
+
+// Requirements of the server for the scraper.
+func (ScrapeClientStat) Requirements() Requirements {
+	return Requirements{Tables: []string{"information_schema.client_statistics"}}
+}
+
 // Scrape collects data from database connection and sends it over channel as prometheus metric.
-func (ScrapeClientStat) Scrape(ctx context.Context, instance *instance, ch chan<- prometheus.Metric, logger *slog.Logger) error {
+func (ScrapeClientStat) Scrape(ctx context.Context, instance *Instance, ch chan<- prometheus.Metric, logger *slog.Logger) error {
 	var varName, varVal string
-	db := instance.getDB()
+	db := instance.DB()
 	err := db.QueryRowContext(ctx, userstatCheckQuery).Scan(&varName, &varVal)
 	if err != nil {
 		logger.Debug("Detailed client stats are not available.")
@@ -220,4 +234,5 @@ func (ScrapeClientStat) Scrape(ctx context.Context, instance *instance, ch chan<
 }
 
 // check interface
-var _ Scraper = ScrapeClientStat{}
+var _ RequirementsScraper = ScrapeClientStat{}
+var _ VersionRangesScraper = ScrapeClientStat{}
@@ -19,6 +19,7 @@ import (
 	"context"
 	"log/slog"
 
+	"github.com/blang/semver/v4"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -75,9 +76,17 @@ func (ScrapeInnodbCmp) Version() float64 {
 	return 5.5
 }
 
+// VersionRanges of the servers from which scraper is available.
+func (ScrapeInnodbCmp) VersionRanges() map[string]semver.Range {
+	return map[string]semver.Range{
+		FlavorMySQL:   semver.MustParseRange(">=5.5.0"),
+		FlavorMariaDB: semver.MustParseRange(">=5.5.0"),
+	}
+}
+
 // Scrape collects data from database connection and sends it over channel as prometheus metric.
-func (ScrapeInnodbCmp) Scrape(ctx context.Context, instance *instance, ch chan<- prometheus.Metric, logger *slog.Logger) error {
-	db := instance.getDB()
+func (ScrapeInnodbCmp) Scrape(ctx context.Context, instance *Instance, ch chan<- prometheus.Metric, logger *slog.Logger) error {
+	db := instance.DB()
 	informationSchemaInnodbCmpRows, err := db.QueryContext(ctx, innodbCmpQuery)
 	if err != nil {
 		return err
@@ -107,4 +116,4 @@ func (ScrapeInnodbCmp) Scrape(ctx context.Context, instance *instance, ch chan<-
 }
 
 // check interface
-var _ Scraper = ScrapeInnodbCmp{}
+var _ VersionRangesScraper = ScrapeInnodbCmp{}
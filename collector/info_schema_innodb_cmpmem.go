@@ -19,6 +19,7 @@ import (
 	"context"
 	"log/slog"
 
+	"github.com/blang/semver/v4"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -70,9 +71,17 @@ func (ScrapeInnodbCmpMem) Version() float64 {
 	return 5.5
 }
 
+// VersionRanges of the servers from which scraper is available.
+func (ScrapeInnodbCmpMem) VersionRanges() map[string]semver.Range {
+	return map[string]semver.Range{
+		FlavorMySQL:   semver.MustParseRange(">=5.5.0"),
+		FlavorMariaDB: semver.MustParseRange(">=5.5.0"),
+	}
+}
+
 // Scrape collects data from database connection and sends it over channel as prometheus metric.
-func (ScrapeInnodbCmpMem) Scrape(ctx context.Context, instance *instance, ch chan<- prometheus.Metric, logger *slog.Logger) error {
-	db := instance.getDB()
+func (ScrapeInnodbCmpMem) Scrape(ctx context.Context, instance *Instance, ch chan<- prometheus.Metric, logger *slog.Logger) error {
+	db := instance.DB()
 	informationSchemaInnodbCmpMemRows, err := db.QueryContext(ctx, innodbCmpMemQuery)
 	if err != nil {
 		return err
@@ -100,4 +109,4 @@ func (ScrapeInnodbCmpMem) Scrape(ctx context.Context, instance *instance, ch cha
 }
 
 // check interface
-var _ Scraper = ScrapeInnodbCmpMem{}
+var _ VersionRangesScraper = ScrapeInnodbCmpMem{}
@@ -22,6 +22,7 @@ import (
 	"log/slog"
 	"regexp"
 
+	"github.com/blang/semver/v4"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -90,12 +91,21 @@ func (ScrapeInnodbMetrics) Version() float64 {
 	return 5.6
 }
 
+// VersionRanges of the servers from which scraper is available.
+// MariaDB ships the InnoDB of MySQL 5.6 since 10.0.
+func (ScrapeInnodbMetrics) VersionRanges() map[string]semver.Range {
+	return map[string]semver.Range{
+		FlavorMySQL:   semver.MustParseRange(">=5.6.0"),
+		FlavorMariaDB: semver.MustParseRange(">=10.0.0"),
+	}
+}
+
 // Scrape collects data from database connection and sends it over channel as prometheus metric.
-func (ScrapeInnodbMetrics) Scrape(ctx context.Context, instance *instance, ch chan<- prometheus.Metric, logger *slog.Logger) error {
+func (ScrapeInnodbMetrics) Scrape(ctx context.Context, instance *Instance, ch chan<- prometheus.Metric, logger *slog.Logger) error {
 	var enabledColumnName string
 	var query string
 
-	db := instance.getDB()
+	db := instance.DB()
 	err := db.QueryRowContext(ctx, infoSchemaInnodbMetricsEnabledColumnQuery).Scan(&enabledColumnName)
 	if err != nil {
 		return err
@@ -199,4 +209,4 @@ func (ScrapeInnodbMetrics) Scrape(ctx context.Context, instance *instance, ch ch
 }
 
 // check interface
-var _ Scraper = ScrapeInnodbMetrics{}
+var _ VersionRangesScraper = ScrapeInnodbMetrics{}
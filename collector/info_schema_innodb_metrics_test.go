@@ -31,7 +31,7 @@ func TestScrapeInnodbMetrics(t *testing.T) {
 		t.Fatalf("error opening a stub database connection: %s", err)
 	}
 	defer db.Close()
-	inst := &instance{db: db}
+	inst := &Instance{db: db}
 
 	enabledColumnName := []string{"COLUMN_NAME"}
 	rows := sqlmock.NewRows(enabledColumnName).
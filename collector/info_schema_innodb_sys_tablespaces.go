@@ -100,11 +100,20 @@ func (ScrapeInfoSchemaInnodbTablespaces) Version() float64 {
 	return 5.7
 }
 
+// VersionRanges of the servers from which scraper is available.
+// MariaDB ships the InnoDB of MySQL 5.7, with the file sizes of tablespaces, since 10.2.
+func (ScrapeInfoSchemaInnodbTablespaces) VersionRanges() map[string]semver.Range {
+	return map[string]semver.Range{
+		FlavorMySQL:   semver.MustParseRange(">=5.7.0"),
+		FlavorMariaDB: semver.MustParseRange(">=10.2.0"),
+	}
+}
+
 // Scrape collects data from database connection and sends it over channel as prometheus metric.
-func (ScrapeInfoSchemaInnodbTablespaces) Scrape(ctx context.Context, instance *instance, ch chan<- prometheus.Metric, logger *slog.Logger) error {
+func (ScrapeInfoSchemaInnodbTablespaces) Scrape(ctx context.Context, instance *Instance, ch chan<- prometheus.Metric, logger *slog.Logger) error {
 	var tablespacesTablename string
 	var query string
-	db := instance.getDB()
+	db := instance.DB()
 
 	err := db.QueryRowContext(ctx, innodbTablespacesTablenameQuery).Scan(&tablespacesTablename)
 	if err != nil {
@@ -181,4 +190,4 @@ func (ScrapeInfoSchemaInnodbTablespaces) Scrape(ctx context.Context, instance *i
 }
 
 // check interface
-var _ Scraper = ScrapeInfoSchemaInnodbTablespaces{}
+var _ VersionRangesScraper = ScrapeInfoSchemaInnodbTablespaces{}
@@ -23,7 +23,7 @@ import (
 	"slices"
 	"strings"
 
-	"github.com/alecthomas/kingpin/v2"
+	"github.com/blang/semver/v4"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -41,22 +41,6 @@ const infoSchemaProcesslistQuery = `
 		  GROUP BY user, host, command, state
 	`
 
-// Tunable flags.
-var (
-	processlistMinTime = kingpin.Flag(
-		"collect.info_schema.processlist.min_time",
-		"Minimum time a thread must be in each state to be counted",
-	).Default("0").Int()
-	processesByUserFlag = kingpin.Flag(
-		"collect.info_schema.processlist.processes_by_user",
-		"Enable collecting the number of processes by user",
-	).Default("true").Bool()
-	processesByHostFlag = kingpin.Flag(
-		"collect.info_schema.processlist.processes_by_host",
-		"Enable collecting the number of processes by host",
-	).Default("true").Bool()
-)
-
 // Metric descriptors.
 var (
 	processlistCountDesc = prometheus.NewDesc(
@@ -95,13 +79,21 @@ func (ScrapeProcesslist) Version() float64 {
 	return 5.1
 }
 
+// VersionRanges of the servers from which scraper is available.
+func (ScrapeProcesslist) VersionRanges() map[string]semver.Range {
+	return map[string]semver.Range{
+		FlavorMySQL:   semver.MustParseRange(">=5.1.0"),
+		FlavorMariaDB: semver.MustParseRange(">=5.1.0"),
+	}
+}
+
 // Scrape collects data from database connection and sends it over channel as prometheus metric.
-func (ScrapeProcesslist) Scrape(ctx context.Context, instance *instance, ch chan<- prometheus.Metric, logger *slog.Logger) error {
+func (ScrapeProcesslist) Scrape(ctx context.Context, instance *Instance, ch chan<- prometheus.Metric, logger *slog.Logger) error {
 	processQuery := fmt.Sprintf(
 		infoSchemaProcesslistQuery,
-		*processlistMinTime,
+		instance.options.ProcesslistMinTime,
 	)
-	db := instance.getDB()
+	db := instance.DB()
 	processlistRows, err := db.QueryContext(ctx, processQuery)
 	if err != nil {
 		return err
@@ -162,12 +154,12 @@ func (ScrapeProcesslist) Scrape(ctx context.Context, instance *instance, ch chan
 		}
 	}
 
-	if *processesByHostFlag {
+	if instance.options.ProcesslistProcessesByHost {
 		for _, host := range slices.Sorted(maps.Keys(stateHostCounts)) {
 			ch <- prometheus.MustNewConstMetric(processesByHostDesc, prometheus.GaugeValue, float64(stateHostCounts[host]), host)
 		}
 	}
-	if *processesByUserFlag {
+	if instance.options.ProcesslistProcessesByUser {
 		for _, user := range slices.Sorted(maps.Keys(stateUserCounts)) {
 			ch <- prometheus.MustNewConstMetric(processesByUserDesc, prometheus.GaugeValue, float64(stateUserCounts[user]), user)
 		}
@@ -198,4 +190,4 @@ func sanitizeState(state string) string {
 }
 
 // check interface
-var _ Scraper = ScrapeProcesslist{}
+var _ VersionRangesScraper = ScrapeProcesslist{}
@@ -21,6 +21,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/blang/semver/v4"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -54,8 +55,8 @@ var (
 	}
 )
 
-func processQueryResponseTimeTable(ctx context.Context, instance *instance, ch chan<- prometheus.Metric, query string, i int) error {
-	db := instance.getDB()
+func processQueryResponseTimeTable(ctx context.Context, instance *Instance, ch chan<- prometheus.Metric, query string, i int) error {
+	db := instance.DB()
 	queryDistributionRows, err := db.QueryContext(ctx, query)
 	if err != nil {
 		return err
@@ -117,10 +118,27 @@ func (ScrapeQueryResponseTime) Version() float64 {
 	return 5.5
 }
 
+// VersionRanges of the servers from which scraper is available.
+// MariaDB ships the QUERY_RESPONSE_TIME plugin since 10.0.4.
+func (ScrapeQueryResponseTime) VersionRanges() map[string]semver.Range {
+	return map[string]semver.Range{
+		FlavorMySQL:   semver.MustParseRange(">=5.5.0"),
+		FlavorMariaDB: semver.MustParseRange(">=10.0.4"),
+	}
+}
+
+// Requirements of the server for the scraper.
+func (ScrapeQueryResponseTime) Requirements() Requirements {
+	return Requirements{
+		Tables:  []string{"information_schema.query_response_time"},
+		Plugins: []string{"query_response_time"},
+	}
+}
+
 // Scrape collects data from database connection and sends it over channel as prometheus metric.
-func (ScrapeQueryResponseTime) Scrape(ctx context.Context, instance *instance, ch chan<- prometheus.Metric, logger *slog.Logger) error {
+func (ScrapeQueryResponseTime) Scrape(ctx context.Context, instance *Instance, ch chan<- prometheus.Metric, logger *slog.Logger) error {
 	var queryStats uint8
-	db := instance.getDB()
+	db := instance.DB()
 	err := db.QueryRowContext(ctx, queryResponseCheckQuery).Scan(&queryStats)
 	if err != nil {
 		logger.Debug("Query response time distribution is not available.")
@@ -143,4 +161,5 @@ func (ScrapeQueryResponseTime) Scrape(ctx context.Context, instance *instance, c
 }
 
 // check interface
-var _ Scraper = ScrapeQueryResponseTime{}
+var _ RequirementsScraper = ScrapeQueryResponseTime{}
+var _ VersionRangesScraper = ScrapeQueryResponseTime{}
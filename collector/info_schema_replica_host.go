@@ -19,6 +19,7 @@ import (
 	"context"
 	"log/slog"
 
+	"github.com/blang/semver/v4"
 	MySQL "github.com/go-sql-driver/mysql"
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -81,9 +82,22 @@ func (ScrapeReplicaHost) Version() float64 {
 	return 5.6
 }
 
+// VersionRanges of the servers from which scraper is available.
+// The replica host status is specific to Aurora MySQL.
+func (ScrapeReplicaHost) VersionRanges() map[string]semver.Range {
+	return map[string]semver.Range{
+		FlavorMySQL: semver.MustParseRange(">=5.6.0"),
+	}
+}
+
+// Requirements of the server for the scraper.
+func (ScrapeReplicaHost) Requirements() Requirements {
+	return Requirements{Tables: []string{"information_schema.replica_host_status"}}
+}
+
 // Scrape collects data from database connection and sends it over channel as prometheus metric.
-func (ScrapeReplicaHost) Scrape(ctx context.Context, instance *instance, ch chan<- prometheus.Metric, logger *slog.Logger) error {
-	db := instance.getDB()
+func (ScrapeReplicaHost) Scrape(ctx context.Context, instance *Instance, ch chan<- prometheus.Metric, logger *slog.Logger) error {
+	db := instance.DB()
 	replicaHostRows, err := db.QueryContext(ctx, replicaHostQuery)
 	if err != nil {
 		if mysqlErr, ok := err.(*MySQL.MySQLError); ok { // Now the error number is accessible directly
@@ -143,4 +157,5 @@ func (ScrapeReplicaHost) Scrape(ctx context.Context, instance *instance, ch chan
 }
 
 // check interface
-var _ Scraper = ScrapeReplicaHost{}
+var _ RequirementsScraper = ScrapeReplicaHost{}
+var _ VersionRangesScraper = ScrapeReplicaHost{}
@@ -19,6 +19,7 @@ import (
 	"context"
 	"log/slog"
 
+	"github.com/blang/semver/v4"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -242,9 +243,26 @@ func (ScrapeRocksDBPerfContext) Version() float64 {
 	return 5.6
 }
 
+// VersionRanges of the servers from which scraper is available.
+// MariaDB ships MyRocks since 10.2.5.
+func (ScrapeRocksDBPerfContext) VersionRanges() map[string]semver.Range {
+	return map[string]semver.Range{
+		FlavorMySQL:   semver.MustParseRange(">=5.6.0"),
+		FlavorMariaDB: semver.MustParseRange(">=10.2.5"),
+	}
+}
+
+// Requirements of the server for the scraper.
+func (ScrapeRocksDBPerfContext) Requirements() Requirements {
+	return Requirements{
+		Tables:  []string{"information_schema.rocksdb_perf_context"},
+		Plugins: []string{"rocksdb"},
+	}
+}
+
 // Scrape collects data from database connection and sends it over channel as prometheus metric.
-func (ScrapeRocksDBPerfContext) Scrape(ctx context.Context, instance *instance, ch chan<- prometheus.Metric, logger *slog.Logger) error {
-	db := instance.getDB()
+func (ScrapeRocksDBPerfContext) Scrape(ctx context.Context, instance *Instance, ch chan<- prometheus.Metric, logger *slog.Logger) error {
+	db := instance.DB()
 	informationSchemaInnodbCmpMemRows, err := db.QueryContext(ctx, rocksdbPerfContextQuery)
 	if err != nil {
 		return err
@@ -270,4 +288,5 @@ func (ScrapeRocksDBPerfContext) Scrape(ctx context.Context, instance *instance,
 }
 
 // check interface
-var _ Scraper = ScrapeRocksDBPerfContext{}
+var _ RequirementsScraper = ScrapeRocksDBPerfContext{}
+var _ VersionRangesScraper = ScrapeRocksDBPerfContext{}
@@ -19,6 +19,7 @@ import (
 	"context"
 	"log/slog"
 
+	"github.com/blang/semver/v4"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -69,10 +70,23 @@ func (ScrapeTableStat) Version() float64 {
 	return 5.1
 }
 
+// VersionRanges of the servers from which scraper is available.
+func (ScrapeTableStat) VersionRanges() map[string]semver.Range {
+	return map[string]semver.Range{
+		FlavorMySQL:   semver.MustParseRange(">=5.1.0"),
+		FlavorMariaDB: semver.MustParseRange(">=5.1.0"),
+	}
+}
+
+// Requirements of the server for the scraper.
+func (ScrapeTableStat) Requirements() Requirements {
+	return Requirements{Tables: []string{"information_schema.table_statistics"}}
+}
+
 // Scrape collects data from database connection and sends it over channel as prometheus metric.
-func (ScrapeTableStat) Scrape(ctx context.Context, instance *instance, ch chan<- prometheus.Metric, logger *slog.Logger) error {
+func (ScrapeTableStat) Scrape(ctx context.Context, instance *Instance, ch chan<- prometheus.Metric, logger *slog.Logger) error {
 	var varName, varVal string
-	db := instance.getDB()
+	db := instance.DB()
 	err := db.QueryRowContext(ctx, userstatCheckQuery).Scan(&varName, &varVal)
 	if err != nil {
 		logger.Debug("Detailed table stats are not available.")
@@ -125,4 +139,5 @@ func (ScrapeTableStat) Scrape(ctx context.Context, instance *instance, ch chan<-
 }
 
 // check interface
-var _ Scraper = ScrapeTableStat{}
+var _ RequirementsScraper = ScrapeTableStat{}
+var _ VersionRangesScraper = ScrapeTableStat{}
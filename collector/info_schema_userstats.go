@@ -21,6 +21,7 @@ import (
 	"log/slog"
 	"strings"
 
+	"github.com/blang/semver/v4"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -154,10 +155,23 @@ func (ScrapeUserStat) Version() float64 {
 	return 5.1
 }
 
+// VersionRanges of the servers from which scraper is available.
+func (ScrapeUserStat) VersionRanges() map[string]semver.Range {
+	return map[string]semver.Range{
+		FlavorMySQL:   semver.MustParseRange(">=5.1.0"),
+		FlavorMariaDB: semver.MustParseRange(">=5.1.0"),
+	}
+}
+
+// Requirements of the server for the scraper.
+func (ScrapeUserStat) Requirements() Requirements {
+	return Requirements{Tables: []string{"information_schema.user_statistics"}}
+}
+
 // Scrape collects data from database connection and sends it over channel as prometheus metric.
-func (ScrapeUserStat) Scrape(ctx context.Context, instance *instance, ch chan<- prometheus.Metric, logger *slog.Logger) error {
+func (ScrapeUserStat) Scrape(ctx context.Context, instance *Instance, ch chan<- prometheus.Metric, logger *slog.Logger) error {
 	var varName, varVal string
-	db := instance.getDB()
+	db := instance.DB()
 	err := db.QueryRowContext(ctx, userstatCheckQuery).Scan(&varName, &varVal)
 	if err != nil {
 		logger.Debug("Detailed user stats are not available.")
@@ -215,4 +229,5 @@ func (ScrapeUserStat) Scrape(ctx context.Context, instance *instance, ch chan<-
 }
 
 // check interface
-var _ Scraper = ScrapeUserStat{}
+var _ RequirementsScraper = ScrapeUserStat{}
+var _ VersionRangesScraper = ScrapeUserStat{}
@@ -15,38 +15,105 @@ package collector
 
 import (
 	"context"
+	"crypto/tls"
 	"database/sql"
+	"database/sql/driver"
+	"errors"
 	"fmt"
+	"log/slog"
+	"net"
 	"regexp"
 	"strconv"
 	"strings"
 
 	"github.com/blang/semver/v4"
+	"github.com/go-sql-driver/mysql"
 )
 
+// Flavors of MySQL servers.
 const (
 	FlavorMySQL   = "mysql"
 	FlavorMariaDB = "mariadb"
+	FlavorPercona = "percona"
 )
 
-type instance struct {
+// Instance is a connection pool to a MySQL server along with the version and
+// flavor detected when connecting. It is passed to the scrapers.
+type Instance struct {
 	db                *sql.DB
 	flavor            string
 	version           semver.Version
 	versionMajorMinor float64
+
+	// capabilities are rediscovered after capabilitiesTTL and shared by the
+	// copies of the instance, they are unknown if nil.
+	capabilities *capabilitiesCache
+
+	// options are the tunables of the scrapers for the current scrape.
+	options Options
 }
 
-func newInstance(ctx context.Context, dsn string, maxOpenConns int) (*instance, error) {
-	i := &instance{}
-	db, err := sql.Open("mysql", dsn)
+// NewInstance connects to the MySQL server of the DSN and detects its
+// version and flavor. The instance must be closed once no longer needed.
+func NewInstance(ctx context.Context, dsn string, maxOpenConns int) (*Instance, error) {
+	connector, err := newConnector(ctx, dsn)
 	if err != nil {
 		return nil, err
 	}
+	db := sql.OpenDB(connector)
 	db.SetMaxOpenConns(maxOpenConns)
-	db.SetMaxIdleConns(1)
-	i.db = db
+	db.SetMaxIdleConns(maxOpenConns)
+	return newInstance(ctx, db)
+}
+
+// newConnector returns the connector of the DSN. Its connections over TCP
+// and unix sockets are opened with a dialer, which is set on the connector
+// rather than registered with the driver so that other users of the driver
+// in the same program keep their dialers. The connections are restricted to
+// the target addresses of ctx, if any.
+func newConnector(ctx context.Context, dsn string) (driver.Connector, error) {
+	cfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+	setTLSServerName(cfg)
+	addrs, pinned := targetAddrsFromContext(ctx)
+	switch {
+	case cfg.Net == "tcp" || cfg.Net == "unix":
+		cfg.DialFunc = dialer{addrs: addrs, pinned: pinned}.dialTimed
+	case pinned:
+		return nil, fmt.Errorf("connections over %s can not be restricted to the target addresses", cfg.Net)
+	}
+	return mysql.NewConnector(cfg)
+}
+
+// setTLSServerName sets the host of the target as server name of the TLS
+// configuration if it has none, like the driver does for the configurations
+// verified by Go. The configurations of the auth modules verify the server
+// themselves, so the host is also passed to their verification, as Go does
+// not send IP addresses as server name.
+func setTLSServerName(cfg *mysql.Config) {
+	host, _, err := net.SplitHostPort(cfg.Addr)
+	if err != nil || cfg.TLS == nil || cfg.TLS.ServerName != "" {
+		return
+	}
+	// The TLS configuration of a parsed DSN is a copy, it can be modified.
+	cfg.TLS.ServerName = host
+	if verify := cfg.TLS.VerifyConnection; verify != nil {
+		cfg.TLS.VerifyConnection = func(cs tls.ConnectionState) error {
+			if cs.ServerName == "" {
+				cs.ServerName = host
+			}
+			return verify(cs)
+		}
+	}
+}
 
-	version, versionString, err := queryVersion(ctx, db)
+// newInstance detects the version and flavor of the server of the
+// connection pool, closing it on errors.
+func newInstance(ctx context.Context, db *sql.DB) (*Instance, error) {
+	i := &Instance{db: db, capabilities: &capabilitiesCache{}, options: DefaultOptions()}
+	version, versionString, versionComment, err := queryVersion(ctx, db)
 	if err != nil {
 		db.Close()
 		return nil, err
@@ -62,25 +129,58 @@ func newInstance(ctx context.Context, dsn string, maxOpenConns int) (*instance,
 
 	i.versionMajorMinor = versionMajorMinor
 
-	if strings.Contains(strings.ToLower(versionString), "mariadb") {
+	switch {
+	case strings.Contains(strings.ToLower(versionString), "mariadb"):
 		i.flavor = FlavorMariaDB
-	} else {
+	case strings.Contains(strings.ToLower(versionComment), "percona"):
+		i.flavor = FlavorPercona
+	default:
 		i.flavor = FlavorMySQL
 	}
 
 	return i, nil
 }
 
-func (i *instance) getDB() *sql.DB {
+// DB returns the connection pool to the server.
+func (i *Instance) DB() *sql.DB {
 	return i.db
 }
 
-func (i *instance) Close() error {
+// Version returns the version of the server.
+func (i *Instance) Version() semver.Version {
+	return i.version
+}
+
+// Flavor returns the flavor of the server, one of the Flavor constants.
+func (i *Instance) Flavor() string {
+	return i.flavor
+}
+
+// Capabilities returns the features of the server. They are discovered on
+// the first call and kept for capabilitiesTTL, unless ctx is done before the
+// discovery completed. Unknown features are nil.
+func (i *Instance) Capabilities(ctx context.Context, logger *slog.Logger) *Capabilities {
+	if i.capabilities == nil {
+		return nil
+	}
+	return i.capabilities.get(ctx, i.db, logger)
+}
+
+// withOptions returns a copy of the instance passing the given options to
+// the scrapers. The copy shares the connection pool with the instance.
+func (i *Instance) withOptions(options Options) *Instance {
+	scrapeInstance := *i
+	scrapeInstance.options = options
+	return &scrapeInstance
+}
+
+// Close closes the connection pool to the server.
+func (i *Instance) Close() error {
 	return i.db.Close()
 }
 
 // Ping checks connection availability and possibly invalidates the connection if it fails.
-func (i *instance) Ping(ctx context.Context) error {
+func (i *Instance) Ping(ctx context.Context) error {
 	if err := i.db.PingContext(ctx); err != nil {
 		if cerr := i.Close(); cerr != nil {
 			return err
@@ -95,21 +195,27 @@ func (i *instance) Ping(ctx context.Context) error {
 // for MySQL: "8.0.36-28.1"
 var versionRegex = regexp.MustCompile(`^((\d+)(\.\d+)(\.\d+))`)
 
-func queryVersion(ctx context.Context, db *sql.DB) (semver.Version, string, error) {
-	var version string
-	err := db.QueryRowContext(ctx, "SELECT @@version;").Scan(&version)
+// errParseVersion is returned when the version of the server is not understood.
+var errParseVersion = errors.New("could not parse version")
+
+// The result of SELECT @@version_comment is something like:
+// for Percona Server: "Percona Server (GPL), Release 28, Revision 47601f19"
+// for MySQL: "MySQL Community Server - GPL"
+func queryVersion(ctx context.Context, db *sql.DB) (semver.Version, string, string, error) {
+	var version, versionComment string
+	err := db.QueryRowContext(ctx, "SELECT @@version, @@version_comment;").Scan(&version, &versionComment)
 	if err != nil {
-		return semver.Version{}, version, err
+		return semver.Version{}, version, versionComment, err
 	}
 
 	matches := versionRegex.FindStringSubmatch(version)
 	if len(matches) > 1 {
 		parsedVersion, err := semver.ParseTolerant(matches[1])
 		if err != nil {
-			return semver.Version{}, version, fmt.Errorf("could not parse version from %q", matches[1])
+			return semver.Version{}, version, versionComment, fmt.Errorf("%w from %q", errParseVersion, matches[1])
 		}
-		return parsedVersion, version, nil
+		return parsedVersion, version, versionComment, nil
 	}
 
-	return semver.Version{}, version, fmt.Errorf("could not parse version from %q", version)
+	return semver.Version{}, version, versionComment, fmt.Errorf("%w from %q", errParseVersion, version)
 }
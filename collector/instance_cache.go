@@ -0,0 +1,336 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// Subsystem for the connection pool self-metrics.
+	connectionPool = "connection_pool"
+)
+
+// Metric descriptors.
+var (
+	instanceCacheEntriesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, exporter, "instance_cache_entries"),
+		"Number of targets with an open connection pool.",
+		nil, nil,
+	)
+	connectionPoolMaxOpenDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, exporter, connectionPool+"_max_open_connections"),
+		"Maximum number of open connections to the target.",
+		[]string{"target", "auth_module"}, nil,
+	)
+	connectionPoolOpenDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, exporter, connectionPool+"_open_connections"),
+		"The number of established connections to the target, both in use and idle.",
+		[]string{"target", "auth_module"}, nil,
+	)
+	connectionPoolInUseDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, exporter, connectionPool+"_in_use_connections"),
+		"The number of connections to the target currently in use.",
+		[]string{"target", "auth_module"}, nil,
+	)
+	connectionPoolIdleDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, exporter, connectionPool+"_idle_connections"),
+		"The number of idle connections to the target.",
+		[]string{"target", "auth_module"}, nil,
+	)
+	connectionPoolWaitCountDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, exporter, connectionPool+"_wait_count_total"),
+		"The total number of connections waited for.",
+		[]string{"target", "auth_module"}, nil,
+	)
+	connectionPoolWaitDurationDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, exporter, connectionPool+"_wait_duration_seconds_total"),
+		"The total time blocked waiting for a new connection.",
+		[]string{"target", "auth_module"}, nil,
+	)
+	connectionPoolMaxIdleClosedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, exporter, connectionPool+"_max_idle_closed_total"),
+		"The total number of connections closed due to the idle connection limit.",
+		[]string{"target", "auth_module"}, nil,
+	)
+	connectionPoolMaxIdleTimeClosedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, exporter, connectionPool+"_max_idle_time_closed_total"),
+		"The total number of connections closed due to the maximum idle time.",
+		[]string{"target", "auth_module"}, nil,
+	)
+	connectionPoolMaxLifetimeClosedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, exporter, connectionPool+"_max_lifetime_closed_total"),
+		"The total number of connections closed due to the maximum connection lifetime.",
+		[]string{"target", "auth_module"}, nil,
+	)
+)
+
+// Verify if InstanceCache implements prometheus.Collector
+var _ prometheus.Collector = (*InstanceCache)(nil)
+
+// InstanceCache keeps the connection pool and the detected version of every
+// scraped target open between scrapes, so that each scrape does not have to
+// reconnect to the server. Entries are keyed by DSN, along with the target
+// addresses their connections are restricted to if any, and closed once they
+// have not been used for the configured idle timeout.
+//
+// InstanceCache implements prometheus.Collector to expose the statistics of
+// the cached connection pools.
+type InstanceCache struct {
+	mu          sync.Mutex
+	idleTimeout time.Duration
+	entries     map[string]*instanceCacheEntry
+
+	// open is used to create new instances, it can be replaced in tests.
+	open func(ctx context.Context, dsn string, maxOpenConns int) (*Instance, error)
+	now  func() time.Time
+}
+
+type instanceCacheEntry struct {
+	instance   *Instance
+	target     string
+	authModule string
+	refs       int
+	lastUsed   time.Time
+	// evicted entries are no longer in the cache and are closed as soon as
+	// the last scrape using them releases them.
+	evicted bool
+}
+
+// NewInstanceCache returns a new InstanceCache which closes connections to
+// targets that have not been scraped for idleTimeout.
+func NewInstanceCache(idleTimeout time.Duration) *InstanceCache {
+	return &InstanceCache{
+		idleTimeout: idleTimeout,
+		entries:     make(map[string]*instanceCacheEntry),
+		open:        NewInstance,
+		now:         time.Now,
+	}
+}
+
+// SetIdleTimeout replaces the idle timeout after which the connections to
+// a target are closed, e.g. on a reload of the config.
+func (c *InstanceCache) SetIdleTimeout(idleTimeout time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.idleTimeout = idleTimeout
+}
+
+// instanceKey returns the key of the instance of the DSN in the cache. The
+// instances restricted to the target addresses of ctx are only shared by the
+// scrapes of the same addresses.
+func instanceKey(ctx context.Context, dsn string) string {
+	addrs, ok := targetAddrsFromContext(ctx)
+	if !ok {
+		return dsn
+	}
+	return fmt.Sprintf("%s %v", dsn, addrs)
+}
+
+// get returns the cached instance for the DSN, connecting to the target if
+// there is none yet. The returned release function must be called once the
+// caller is done with the instance.
+func (c *InstanceCache) get(ctx context.Context, dsn string, maxOpenConns int, authModule string) (*Instance, func(), error) {
+	key := instanceKey(ctx, dsn)
+	c.mu.Lock()
+	c.evictIdleLocked()
+	if entry, ok := c.entries[key]; ok {
+		entry.instance.db.SetMaxOpenConns(maxOpenConns)
+		entry.instance.db.SetMaxIdleConns(maxOpenConns)
+		entry.refs++
+		c.mu.Unlock()
+		return entry.instance, c.releaseFunc(entry), nil
+	}
+	c.mu.Unlock()
+
+	// Connect without holding the lock, so that a slow or unreachable target
+	// does not block the scrapes of the others.
+	inst, err := c.open(ctx, dsn, maxOpenConns)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if entry, ok := c.entries[key]; ok {
+		// Another scrape connected to the same target in the meantime.
+		inst.Close()
+		entry.refs++
+		return entry.instance, c.releaseFunc(entry), nil
+	}
+	entry := &instanceCacheEntry{
+		instance:   inst,
+		target:     targetFromDsn(dsn),
+		authModule: authModule,
+		refs:       1,
+		lastUsed:   c.now(),
+	}
+	c.entries[key] = entry
+	return inst, c.releaseFunc(entry), nil
+}
+
+func (c *InstanceCache) releaseFunc(entry *instanceCacheEntry) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			entry.refs--
+			entry.lastUsed = c.now()
+			if entry.evicted && entry.refs == 0 {
+				entry.instance.Close()
+			}
+		})
+	}
+}
+
+// discard removes the instance from the cache, e.g. after it failed a ping.
+func (c *InstanceCache) discard(inst *Instance) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, entry := range c.entries {
+		if entry.instance == inst {
+			c.evictLocked(key, entry)
+		}
+	}
+}
+
+// Invalidate closes the connections of all targets which were scraped using
+// the given auth module, e.g. because its config section was reloaded.
+// Scrapes in progress keep using their connection until they finish.
+func (c *InstanceCache) Invalidate(authModule string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, entry := range c.entries {
+		if entry.authModule == authModule {
+			c.evictLocked(key, entry)
+		}
+	}
+}
+
+// Close closes the connections of all cached targets.
+func (c *InstanceCache) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, entry := range c.entries {
+		c.evictLocked(key, entry)
+	}
+}
+
+func (c *InstanceCache) evictIdleLocked() {
+	if c.idleTimeout <= 0 {
+		return
+	}
+	now := c.now()
+	for key, entry := range c.entries {
+		if entry.refs == 0 && now.Sub(entry.lastUsed) > c.idleTimeout {
+			c.evictLocked(key, entry)
+		}
+	}
+}
+
+func (c *InstanceCache) evictLocked(key string, entry *instanceCacheEntry) {
+	delete(c.entries, key)
+	entry.evicted = true
+	if entry.refs == 0 {
+		entry.instance.Close()
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *InstanceCache) Describe(ch chan<- *prometheus.Desc) {
+	ch <- instanceCacheEntriesDesc
+	ch <- connectionPoolMaxOpenDesc
+	ch <- connectionPoolOpenDesc
+	ch <- connectionPoolInUseDesc
+	ch <- connectionPoolIdleDesc
+	ch <- connectionPoolWaitCountDesc
+	ch <- connectionPoolWaitDurationDesc
+	ch <- connectionPoolMaxIdleClosedDesc
+	ch <- connectionPoolMaxIdleTimeClosedDesc
+	ch <- connectionPoolMaxLifetimeClosedDesc
+}
+
+// Collect implements prometheus.Collector.
+func (c *InstanceCache) Collect(ch chan<- prometheus.Metric) {
+	type poolKey struct{ target, authModule string }
+
+	c.mu.Lock()
+	c.evictIdleLocked()
+	// Several DSNs may point to the same target and auth module, e.g. while
+	// a rotated password is still in use by an older connection pool.
+	pools := make(map[poolKey]poolStats, len(c.entries))
+	for _, entry := range c.entries {
+		key := poolKey{entry.target, entry.authModule}
+		pools[key] = pools[key].add(entry.instance.db.Stats())
+	}
+	entries := len(c.entries)
+	c.mu.Unlock()
+
+	ch <- prometheus.MustNewConstMetric(instanceCacheEntriesDesc, prometheus.GaugeValue, float64(entries))
+	for key, stats := range pools {
+		labels := []string{key.target, key.authModule}
+		ch <- prometheus.MustNewConstMetric(connectionPoolMaxOpenDesc, prometheus.GaugeValue, float64(stats.MaxOpenConnections), labels...)
+		ch <- prometheus.MustNewConstMetric(connectionPoolOpenDesc, prometheus.GaugeValue, float64(stats.OpenConnections), labels...)
+		ch <- prometheus.MustNewConstMetric(connectionPoolInUseDesc, prometheus.GaugeValue, float64(stats.InUse), labels...)
+		ch <- prometheus.MustNewConstMetric(connectionPoolIdleDesc, prometheus.GaugeValue, float64(stats.Idle), labels...)
+		ch <- prometheus.MustNewConstMetric(connectionPoolWaitCountDesc, prometheus.CounterValue, float64(stats.WaitCount), labels...)
+		ch <- prometheus.MustNewConstMetric(connectionPoolWaitDurationDesc, prometheus.CounterValue, stats.WaitDuration.Seconds(), labels...)
+		ch <- prometheus.MustNewConstMetric(connectionPoolMaxIdleClosedDesc, prometheus.CounterValue, float64(stats.MaxIdleClosed), labels...)
+		ch <- prometheus.MustNewConstMetric(connectionPoolMaxIdleTimeClosedDesc, prometheus.CounterValue, float64(stats.MaxIdleTimeClosed), labels...)
+		ch <- prometheus.MustNewConstMetric(connectionPoolMaxLifetimeClosedDesc, prometheus.CounterValue, float64(stats.MaxLifetimeClosed), labels...)
+	}
+}
+
+// poolStats sums up sql.DBStats of several connection pools.
+type poolStats struct {
+	MaxOpenConnections int
+	OpenConnections    int
+	InUse              int
+	Idle               int
+	WaitCount          int64
+	WaitDuration       time.Duration
+	MaxIdleClosed      int64
+	MaxIdleTimeClosed  int64
+	MaxLifetimeClosed  int64
+}
+
+func (s poolStats) add(o sql.DBStats) poolStats {
+	s.MaxOpenConnections += o.MaxOpenConnections
+	s.OpenConnections += o.OpenConnections
+	s.InUse += o.InUse
+	s.Idle += o.Idle
+	s.WaitCount += o.WaitCount
+	s.WaitDuration += o.WaitDuration
+	s.MaxIdleClosed += o.MaxIdleClosed
+	s.MaxIdleTimeClosed += o.MaxIdleTimeClosed
+	s.MaxLifetimeClosed += o.MaxLifetimeClosed
+	return s
+}
+
+// targetFromDsn returns the address of the target the DSN points to.
+func targetFromDsn(dsn string) string {
+	dsnConfig, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return ""
+	}
+	return dsnConfig.Addr
+}
@@ -0,0 +1,143 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func newTestInstanceCache(t *testing.T, idleTimeout time.Duration) (*InstanceCache, map[string]sqlmock.Sqlmock, *time.Time) {
+	mocks := map[string]sqlmock.Sqlmock{}
+	now := time.Unix(1700000000, 0)
+	cache := NewInstanceCache(idleTimeout)
+	cache.now = func() time.Time { return now }
+	cache.open = func(_ context.Context, dsn string, maxOpenConns int) (*Instance, error) {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("error opening a stub database connection: %s", err)
+		}
+		db.SetMaxOpenConns(maxOpenConns)
+		mocks[dsn] = mock
+		return &Instance{db: db}, nil
+	}
+	return cache, mocks, &now
+}
+
+func TestInstanceCache(t *testing.T) {
+	const dsnA = "user:pass@tcp(server1:3306)/"
+	const dsnB = "user:pass@tcp(server2:3306)/"
+	ctx := context.Background()
+
+	convey.Convey("Instances are reused between scrapes", t, func() {
+		cache, mocks, _ := newTestInstanceCache(t, time.Minute)
+		first, release, err := cache.get(ctx, dsnA, 2, "client")
+		convey.So(err, convey.ShouldBeNil)
+		release()
+		second, release, err := cache.get(ctx, dsnA, 2, "client")
+		convey.So(err, convey.ShouldBeNil)
+		release()
+		convey.So(second, convey.ShouldEqual, first)
+		convey.So(mocks, convey.ShouldHaveLength, 1)
+	})
+
+	convey.Convey("Instances are only reused for the same target addresses", t, func() {
+		cache, _, _ := newTestInstanceCache(t, time.Minute)
+		pinned := WithTargetAddrs(ctx, []netip.Addr{netip.MustParseAddr("192.0.2.1")})
+		rebound := WithTargetAddrs(ctx, []netip.Addr{netip.MustParseAddr("192.0.2.2")})
+		var instances []*Instance
+		for _, ctx := range []context.Context{ctx, pinned, rebound, pinned} {
+			inst, release, err := cache.get(ctx, dsnA, 2, "client")
+			convey.So(err, convey.ShouldBeNil)
+			release()
+			instances = append(instances, inst)
+		}
+		convey.So(instances[1] != instances[0], convey.ShouldBeTrue)
+		convey.So(instances[2] != instances[1], convey.ShouldBeTrue)
+		convey.So(instances[3], convey.ShouldEqual, instances[1])
+	})
+
+	convey.Convey("Idle instances are evicted", t, func() {
+		cache, mocks, now := newTestInstanceCache(t, time.Minute)
+		_, release, err := cache.get(ctx, dsnA, 2, "client")
+		convey.So(err, convey.ShouldBeNil)
+		release()
+		_, releaseB, err := cache.get(ctx, dsnB, 2, "client")
+		convey.So(err, convey.ShouldBeNil)
+
+		mocks[dsnA].ExpectClose()
+		*now = now.Add(2 * time.Minute)
+		cache.Collect(make(chan prometheus.Metric, 100))
+		convey.So(cache.entries, convey.ShouldNotContainKey, dsnA)
+		// Instances in use are never evicted.
+		convey.So(cache.entries, convey.ShouldContainKey, dsnB)
+		convey.So(mocks[dsnA].ExpectationsWereMet(), convey.ShouldBeNil)
+		releaseB()
+	})
+
+	convey.Convey("Invalidated instances are closed once released", t, func() {
+		cache, mocks, _ := newTestInstanceCache(t, time.Minute)
+		first, release, err := cache.get(ctx, dsnA, 2, "client.servers")
+		convey.So(err, convey.ShouldBeNil)
+
+		cache.Invalidate("client")
+		convey.So(cache.entries, convey.ShouldContainKey, dsnA)
+
+		cache.Invalidate("client.servers")
+		convey.So(cache.entries, convey.ShouldNotContainKey, dsnA)
+
+		mocks[dsnA].ExpectClose()
+		release()
+		convey.So(mocks[dsnA].ExpectationsWereMet(), convey.ShouldBeNil)
+
+		second, release, err := cache.get(ctx, dsnA, 2, "client.servers")
+		convey.So(err, convey.ShouldBeNil)
+		release()
+		convey.So(second != first, convey.ShouldBeTrue)
+	})
+
+	convey.Convey("Connection pool statistics are exposed", t, func() {
+		cache, _, _ := newTestInstanceCache(t, time.Minute)
+		_, release, err := cache.get(ctx, dsnA, 3, "client")
+		convey.So(err, convey.ShouldBeNil)
+		defer release()
+
+		convey.So(testutil.CollectAndCount(cache, "mysql_exporter_instance_cache_entries"), convey.ShouldEqual, 1)
+		got := readMetric(<-collectDesc(cache, connectionPoolMaxOpenDesc))
+		convey.So(got.labels, convey.ShouldResemble, labelMap{"target": "server1:3306", "auth_module": "client"})
+		convey.So(got.value, convey.ShouldEqual, 3)
+	})
+}
+
+// collectDesc returns the metrics of the collector matching the descriptor.
+func collectDesc(c prometheus.Collector, desc *prometheus.Desc) <-chan prometheus.Metric {
+	all := make(chan prometheus.Metric, 100)
+	c.Collect(all)
+	close(all)
+	filtered := make(chan prometheus.Metric, 100)
+	for m := range all {
+		if m.Desc() == desc {
+			filtered <- m
+		}
+	}
+	close(filtered)
+	return filtered
+}
@@ -0,0 +1,132 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestSetTLSServerName(t *testing.T) {
+	var verified []string
+	registered := &tls.Config{
+		InsecureSkipVerify: true,
+		VerifyConnection: func(cs tls.ConnectionState) error {
+			verified = append(verified, cs.ServerName)
+			return nil
+		},
+	}
+	if err := mysql.RegisterTLSConfig("instance_test", registered); err != nil {
+		t.Fatal(err)
+	}
+	defer mysql.DeregisterTLSConfig("instance_test")
+
+	convey.Convey("The host of the target is the server name of the connections", t, func() {
+		verified = nil
+		for _, dsn := range []string{
+			"user:pass@tcp(db1.example:3306)/?tls=instance_test",
+			"user:pass@tcp(192.0.2.1:3306)/?tls=instance_test",
+		} {
+			cfg, err := mysql.ParseDSN(dsn)
+			convey.So(err, convey.ShouldBeNil)
+			setTLSServerName(cfg)
+			convey.So(cfg.TLS.VerifyConnection(tls.ConnectionState{}), convey.ShouldBeNil)
+		}
+		convey.So(verified, convey.ShouldResemble, []string{"db1.example", "192.0.2.1"})
+		convey.So(registered.ServerName, convey.ShouldBeEmpty)
+	})
+
+	convey.Convey("Configured server names are kept", t, func() {
+		verified = nil
+		cfg, err := mysql.ParseDSN("user:pass@unix(/run/mysqld/mysqld.sock)/?tls=instance_test")
+		convey.So(err, convey.ShouldBeNil)
+		setTLSServerName(cfg)
+		convey.So(cfg.TLS.ServerName, convey.ShouldBeEmpty)
+
+		cfg, err = mysql.ParseDSN("user:pass@tcp(db1.example:3306)/?tls=instance_test")
+		convey.So(err, convey.ShouldBeNil)
+		cfg.TLS.ServerName = "mysql.example"
+		setTLSServerName(cfg)
+		convey.So(cfg.TLS.VerifyConnection(tls.ConnectionState{ServerName: "mysql.example"}), convey.ShouldBeNil)
+		convey.So(verified, convey.ShouldResemble, []string{"mysql.example"})
+	})
+}
+
+// acceptOnce returns a listener which closes the first connection it
+// accepts and reports it on the returned channel.
+func acceptOnce(t *testing.T) (net.Listener, <-chan struct{}) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	accepted := make(chan struct{}, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err == nil {
+			conn.Close()
+			accepted <- struct{}{}
+		}
+	}()
+	return listener, accepted
+}
+
+func TestNewConnector(t *testing.T) {
+	convey.Convey("Connectors open their connections with the timed dialer", t, func() {
+		listener, _ := acceptOnce(t)
+		defer listener.Close()
+
+		connector, err := newConnector(context.Background(), "user:pass@tcp("+listener.Addr().String()+")/")
+		convey.So(err, convey.ShouldBeNil)
+		timings := &connectTimings{}
+		_, err = connector.Connect(withConnectTimings(context.Background(), timings))
+		convey.So(err, convey.ShouldNotBeNil)
+		convey.So(timings.dialStart.IsZero(), convey.ShouldBeFalse)
+	})
+
+	convey.Convey("The target addresses are bound to the connector", t, func() {
+		listener, accepted := acceptOnce(t)
+		defer listener.Close()
+		_, port, err := net.SplitHostPort(listener.Addr().String())
+		convey.So(err, convey.ShouldBeNil)
+
+		ctx := WithTargetAddrs(context.Background(), []netip.Addr{netip.MustParseAddr("127.0.0.1")})
+		connector, err := newConnector(ctx, "user:pass@tcp("+net.JoinHostPort("db.invalid", port)+")/")
+		convey.So(err, convey.ShouldBeNil)
+		// Connections opened with other contexts, like the ones the
+		// connection pool opens in the background, are restricted too.
+		_, err = connector.Connect(context.Background())
+		convey.So(err, convey.ShouldNotBeNil)
+		select {
+		case <-accepted:
+		case <-time.After(5 * time.Second):
+			t.Fatal("the connection was not opened to the target address")
+		}
+	})
+
+	convey.Convey("Connectors without target addresses fail closed", t, func() {
+		ctx := WithTargetAddrs(context.Background(), nil)
+		connector, err := newConnector(ctx, "user:pass@tcp(127.0.0.1:3306)/")
+		convey.So(err, convey.ShouldBeNil)
+		_, err = connector.Connect(context.Background())
+		convey.So(err, convey.ShouldNotBeNil)
+		convey.So(err.Error(), convey.ShouldContainSubstring, "no addresses to connect to")
+	})
+}
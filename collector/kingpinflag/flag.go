@@ -0,0 +1,107 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package kingpinflag exposes the tunables of the collector package as
+// Kingpin flags.
+package kingpinflag
+
+import (
+	"strconv"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus/mysqld_exporter/collector"
+)
+
+type flagGroup interface {
+	Flag(string, string) *kingpin.FlagClause
+}
+
+var _ flagGroup = &kingpin.Application{}
+
+// AddFlags adds the flags of the scraper tunables to the Kingpin application
+// or CmdClause. The returned options hold the flag values once the
+// application is parsed. To use the default Kingpin application, call
+// AddFlags(kingpin.CommandLine).
+func AddFlags(a flagGroup) *collector.Options {
+	defaults := collector.DefaultOptions()
+	options := &collector.Options{}
+
+	a.Flag(
+		"collect.heartbeat.database",
+		"Database from where to collect heartbeat data",
+	).Default(defaults.HeartbeatDatabase).StringVar(&options.HeartbeatDatabase)
+	a.Flag(
+		"collect.heartbeat.table",
+		"Table from where to collect heartbeat data",
+	).Default(defaults.HeartbeatTable).StringVar(&options.HeartbeatTable)
+	a.Flag(
+		"collect.heartbeat.utc",
+		"Use UTC for timestamps of the current server (`pt-heartbeat` is called with `--utc`)",
+	).Default(strconv.FormatBool(defaults.HeartbeatUTC)).BoolVar(&options.HeartbeatUTC)
+
+	a.Flag(
+		"collect.info_schema.processlist.min_time",
+		"Minimum time a thread must be in each state to be counted",
+	).Default(strconv.Itoa(defaults.ProcesslistMinTime)).IntVar(&options.ProcesslistMinTime)
+	a.Flag(
+		"collect.info_schema.processlist.processes_by_user",
+		"Enable collecting the number of processes by user",
+	).Default(strconv.FormatBool(defaults.ProcesslistProcessesByUser)).BoolVar(&options.ProcesslistProcessesByUser)
+	a.Flag(
+		"collect.info_schema.processlist.processes_by_host",
+		"Enable collecting the number of processes by host",
+	).Default(strconv.FormatBool(defaults.ProcesslistProcessesByHost)).BoolVar(&options.ProcesslistProcessesByHost)
+
+	a.Flag(
+		"collect.info_schema.tables.databases",
+		"The list of databases to collect table stats for, or '*' for all",
+	).Default(defaults.TableSchemaDatabases).StringVar(&options.TableSchemaDatabases)
+
+	a.Flag(
+		"collect.mysql.user.privileges",
+		"Enable collecting user privileges from mysql.user",
+	).Default(strconv.FormatBool(defaults.UserPrivileges)).BoolVar(&options.UserPrivileges)
+
+	a.Flag(
+		"collect.perf_schema.eventsstatements.limit",
+		"Limit the number of events statements digests by response time",
+	).Default(strconv.Itoa(defaults.PerfEventsStatementsLimit)).IntVar(&options.PerfEventsStatementsLimit)
+	a.Flag(
+		"collect.perf_schema.eventsstatements.timelimit",
+		"Limit how old the 'last_seen' events statements can be, in seconds",
+	).Default(strconv.Itoa(defaults.PerfEventsStatementsTimeLimit)).IntVar(&options.PerfEventsStatementsTimeLimit)
+	a.Flag(
+		"collect.perf_schema.eventsstatements.digest_text_limit",
+		"Maximum length of the normalized statement text",
+	).Default(strconv.Itoa(defaults.PerfEventsStatementsDigestTextLimit)).IntVar(&options.PerfEventsStatementsDigestTextLimit)
+	a.Flag(
+		"collect.perf_schema.eventsstatements.exclude_schemas",
+		"Additional schema name to exclude (always excludes mysql, performance_schema, information_schema). Repeatable",
+	).StringsVar(&options.PerfEventsStatementsExcludeSchemas)
+
+	a.Flag(
+		"collect.perf_schema.file_instances.filter",
+		"RegEx file_name filter for performance_schema.file_summary_by_instance",
+	).Default(defaults.PerfFileInstancesFilter).StringVar(&options.PerfFileInstancesFilter)
+	a.Flag(
+		"collect.perf_schema.file_instances.remove_prefix",
+		"Remove path prefix in performance_schema.file_summary_by_instance",
+	).Default(defaults.PerfFileInstancesRemovePrefix).StringVar(&options.PerfFileInstancesRemovePrefix)
+
+	a.Flag(
+		"collect.perf_schema.memory_events.remove_prefix",
+		"Remove instrument prefix in performance_schema.memory_summary_global_by_event_name",
+	).Default(defaults.PerfMemoryEventsRemovePrefix).StringVar(&options.PerfMemoryEventsRemovePrefix)
+
+	return options
+}
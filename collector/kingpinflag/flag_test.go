@@ -0,0 +1,87 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kingpinflag
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/kingpin/v2"
+	"github.com/prometheus/mysqld_exporter/collector"
+)
+
+func TestAddFlags(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want func(*collector.Options)
+	}{
+		{
+			name: "defaults",
+			want: func(*collector.Options) {},
+		},
+		{
+			name: "flags set",
+			args: []string{
+				"--collect.heartbeat.database", "hb",
+				"--collect.heartbeat.utc",
+				"--no-collect.info_schema.processlist.processes_by_user",
+				"--collect.perf_schema.eventsstatements.limit", "10",
+				"--collect.perf_schema.eventsstatements.exclude_schemas", "a",
+				"--collect.perf_schema.eventsstatements.exclude_schemas", "b",
+			},
+			want: func(o *collector.Options) {
+				o.HeartbeatDatabase = "hb"
+				o.HeartbeatUTC = true
+				o.ProcesslistProcessesByUser = false
+				o.PerfEventsStatementsLimit = 10
+				o.PerfEventsStatementsExcludeSchemas = []string{"a", "b"}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := kingpin.New("test", "")
+			got := AddFlags(app)
+			if _, err := app.Parse(tt.args); err != nil {
+				t.Fatal(err)
+			}
+			want := collector.DefaultOptions()
+			tt.want(&want)
+			if !reflect.DeepEqual(*got, want) {
+				t.Errorf("AddFlags() = %+v, want %+v", *got, want)
+			}
+		})
+	}
+}
+
+func TestOptionNamesMatchFlags(t *testing.T) {
+	app := kingpin.New("test", "")
+	AddFlags(app)
+	for _, name := range collector.OptionNames() {
+		if app.GetFlag("collect."+name) == nil {
+			t.Errorf("option %q has no flag collect.%s", name, name)
+		}
+	}
+	var flags int
+	for _, flag := range app.Model().Flags {
+		if strings.HasPrefix(flag.Name, "collect.") {
+			flags++
+		}
+	}
+	if want := len(collector.OptionNames()); flags != want {
+		t.Errorf("got %d flags for %d options", flags, want)
+	}
+}
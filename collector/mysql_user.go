@@ -22,7 +22,7 @@ import (
 	"log/slog"
 	"strings"
 
-	"github.com/alecthomas/kingpin/v2"
+	"github.com/blang/semver/v4"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -69,14 +69,6 @@ const mysqlUserQuery = `
 		  FROM mysql.user
 		`
 
-// Tunable flags.
-var (
-	userPrivilegesFlag = kingpin.Flag(
-		"collect.mysql.user.privileges",
-		"Enable collecting user privileges from mysql.user",
-	).Default("false").Bool()
-)
-
 var (
 	labelNames = []string{"mysql_user", "hostmask"}
 )
@@ -119,9 +111,17 @@ func (ScrapeUser) Version() float64 {
 	return 5.1
 }
 
+// VersionRanges of the servers from which scraper is available.
+func (ScrapeUser) VersionRanges() map[string]semver.Range {
+	return map[string]semver.Range{
+		FlavorMySQL:   semver.MustParseRange(">=5.1.0"),
+		FlavorMariaDB: semver.MustParseRange(">=5.1.0"),
+	}
+}
+
 // Scrape collects data from database connection and sends it over channel as prometheus metric.
-func (ScrapeUser) Scrape(ctx context.Context, instance *instance, ch chan<- prometheus.Metric, logger *slog.Logger) error {
-	db := instance.getDB()
+func (ScrapeUser) Scrape(ctx context.Context, instance *Instance, ch chan<- prometheus.Metric, logger *slog.Logger) error {
+	db := instance.DB()
 	var (
 		userRows *sql.Rows
 		err      error
@@ -214,7 +214,7 @@ func (ScrapeUser) Scrape(ctx context.Context, instance *instance, ch chan<- prom
 			return err
 		}
 
-		if *userPrivilegesFlag {
+		if instance.options.UserPrivileges {
 			userCols, err := userRows.Columns()
 			if err != nil {
 				return err
@@ -254,3 +254,6 @@ func (ScrapeUser) Scrape(ctx context.Context, instance *instance, ch chan<- prom
 
 	return nil
 }
+
+// check interface
+var _ VersionRangesScraper = ScrapeUser{}
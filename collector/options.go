@@ -0,0 +1,200 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Options holds the tunables of the built-in scrapers.
+type Options struct {
+	// HeartbeatDatabase is the database from where to collect heartbeat data.
+	HeartbeatDatabase string
+	// HeartbeatTable is the table from where to collect heartbeat data.
+	HeartbeatTable string
+	// HeartbeatUTC uses UTC for timestamps of the current server
+	// (`pt-heartbeat` is called with `--utc`).
+	HeartbeatUTC bool
+
+	// ProcesslistMinTime is the minimum time a thread must be in each state
+	// to be counted.
+	ProcesslistMinTime int
+	// ProcesslistProcessesByUser enables collecting the number of processes by user.
+	ProcesslistProcessesByUser bool
+	// ProcesslistProcessesByHost enables collecting the number of processes by host.
+	ProcesslistProcessesByHost bool
+
+	// TableSchemaDatabases is the comma separated list of databases to
+	// collect table stats for, or '*' for all.
+	TableSchemaDatabases string
+
+	// UserPrivileges enables collecting user privileges from mysql.user.
+	UserPrivileges bool
+
+	// PerfEventsStatementsLimit limits the number of events statements
+	// digests by response time.
+	PerfEventsStatementsLimit int
+	// PerfEventsStatementsTimeLimit limits how old the 'last_seen' events
+	// statements can be, in seconds.
+	PerfEventsStatementsTimeLimit int
+	// PerfEventsStatementsDigestTextLimit is the maximum length of the
+	// normalized statement text.
+	PerfEventsStatementsDigestTextLimit int
+	// PerfEventsStatementsExcludeSchemas are additional schemas to exclude,
+	// mysql, performance_schema and information_schema are always excluded.
+	PerfEventsStatementsExcludeSchemas []string
+
+	// PerfFileInstancesFilter is the regular expression file_name filter for
+	// performance_schema.file_summary_by_instance.
+	PerfFileInstancesFilter string
+	// PerfFileInstancesRemovePrefix is the path prefix removed in
+	// performance_schema.file_summary_by_instance.
+	PerfFileInstancesRemovePrefix string
+
+	// PerfMemoryEventsRemovePrefix is the instrument prefix removed in
+	// performance_schema.memory_summary_global_by_event_name.
+	PerfMemoryEventsRemovePrefix string
+}
+
+// DefaultOptions returns the default tunables of the built-in scrapers.
+func DefaultOptions() Options {
+	return Options{
+		HeartbeatDatabase:                   "heartbeat",
+		HeartbeatTable:                      "heartbeat",
+		ProcesslistProcessesByUser:          true,
+		ProcesslistProcessesByHost:          true,
+		TableSchemaDatabases:                "*",
+		PerfEventsStatementsLimit:           250,
+		PerfEventsStatementsTimeLimit:       86400,
+		PerfEventsStatementsDigestTextLimit: 120,
+		PerfFileInstancesFilter:             ".*",
+		PerfFileInstancesRemovePrefix:       "/var/lib/mysql/",
+		PerfMemoryEventsRemovePrefix:        "memory/",
+	}
+}
+
+// optionFields maps the names of the options, which are the names of their
+// flags without the `collect.` prefix, to their fields.
+var optionFields = map[string]func(o *Options) any{
+	"heartbeat.database":                             func(o *Options) any { return &o.HeartbeatDatabase },
+	"heartbeat.table":                                func(o *Options) any { return &o.HeartbeatTable },
+	"heartbeat.utc":                                  func(o *Options) any { return &o.HeartbeatUTC },
+	"info_schema.processlist.min_time":               func(o *Options) any { return &o.ProcesslistMinTime },
+	"info_schema.processlist.processes_by_user":      func(o *Options) any { return &o.ProcesslistProcessesByUser },
+	"info_schema.processlist.processes_by_host":      func(o *Options) any { return &o.ProcesslistProcessesByHost },
+	"info_schema.tables.databases":                   func(o *Options) any { return &o.TableSchemaDatabases },
+	"mysql.user.privileges":                          func(o *Options) any { return &o.UserPrivileges },
+	"perf_schema.eventsstatements.limit":             func(o *Options) any { return &o.PerfEventsStatementsLimit },
+	"perf_schema.eventsstatements.timelimit":         func(o *Options) any { return &o.PerfEventsStatementsTimeLimit },
+	"perf_schema.eventsstatements.digest_text_limit": func(o *Options) any { return &o.PerfEventsStatementsDigestTextLimit },
+	"perf_schema.eventsstatements.exclude_schemas":   func(o *Options) any { return &o.PerfEventsStatementsExcludeSchemas },
+	"perf_schema.file_instances.filter":              func(o *Options) any { return &o.PerfFileInstancesFilter },
+	"perf_schema.file_instances.remove_prefix":       func(o *Options) any { return &o.PerfFileInstancesRemovePrefix },
+	"perf_schema.memory_events.remove_prefix":        func(o *Options) any { return &o.PerfMemoryEventsRemovePrefix },
+}
+
+// OptionNames returns the sorted names of the options accepted by Set.
+func OptionNames() []string {
+	return slices.Sorted(maps.Keys(optionFields))
+}
+
+// RequestOptionNames returns the sorted names of the numeric and boolean
+// options. Unlike the options naming databases, tables or patterns, they
+// are safe to be set by the parameters of untrusted requests.
+func RequestOptionNames() []string {
+	var names []string
+	for name, field := range optionFields {
+		switch field(&Options{}).(type) {
+		case *bool, *int:
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Validate returns an error if the heartbeat database or table is not a
+// valid identifier, as they are quoted into the heartbeat query.
+func (o Options) Validate() error {
+	for name, identifier := range map[string]string{
+		"heartbeat.database": o.HeartbeatDatabase,
+		"heartbeat.table":    o.HeartbeatTable,
+	} {
+		if err := validateIdentifier(identifier); err != nil {
+			return fmt.Errorf("invalid value for option %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// validateIdentifier returns an error if the name can not be quoted as a
+// MySQL identifier with backticks.
+func validateIdentifier(name string) error {
+	switch {
+	case name == "":
+		return errors.New("identifier must not be empty")
+	case len(name) > 64:
+		return fmt.Errorf("identifier must be at most 64 characters: %q", name)
+	case strings.ContainsAny(name, "`\x00"):
+		return fmt.Errorf("identifier must not contain backticks or NUL characters: %q", name)
+	}
+	return nil
+}
+
+// Set sets the option with the given name, which is the name of its flag
+// without the `collect.` prefix, e.g. `perf_schema.eventsstatements.limit`.
+// Numbers must not be negative, lists are comma separated.
+func (o *Options) Set(name, value string) error {
+	field, ok := optionFields[name]
+	if !ok {
+		return fmt.Errorf("unknown option %q", name)
+	}
+	switch p := field(o).(type) {
+	case *string:
+		if name == "heartbeat.database" || name == "heartbeat.table" {
+			if err := validateIdentifier(value); err != nil {
+				return fmt.Errorf("invalid value for option %q: %w", name, err)
+			}
+		}
+		*p = value
+	case *bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid value for option %q: %w", name, err)
+		}
+		*p = b
+	case *int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid value for option %q: %w", name, err)
+		}
+		if n < 0 {
+			return fmt.Errorf("invalid value for option %q, must be >= 0: %d", name, n)
+		}
+		*p = n
+	case *[]string:
+		*p = nil
+		for item := range strings.SplitSeq(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				*p = append(*p, item)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestOptionsSet(t *testing.T) {
+	convey.Convey("Set options by name", t, func() {
+		o := DefaultOptions()
+		convey.So(o.Set("perf_schema.eventsstatements.limit", "50"), convey.ShouldBeNil)
+		convey.So(o.Set("heartbeat.utc", "true"), convey.ShouldBeNil)
+		convey.So(o.Set("info_schema.tables.databases", "shop"), convey.ShouldBeNil)
+		convey.So(o.Set("perf_schema.eventsstatements.exclude_schemas", "sys, test"), convey.ShouldBeNil)
+
+		want := DefaultOptions()
+		want.PerfEventsStatementsLimit = 50
+		want.HeartbeatUTC = true
+		want.TableSchemaDatabases = "shop"
+		want.PerfEventsStatementsExcludeSchemas = []string{"sys", "test"}
+		convey.So(o, convey.ShouldResemble, want)
+	})
+
+	convey.Convey("Reject invalid options", t, func() {
+		o := DefaultOptions()
+		convey.So(o.Set("perf_schema.eventsstatements.foo", "50"), convey.ShouldNotBeNil)
+		convey.So(o.Set("perf_schema.eventsstatements.limit", "many"), convey.ShouldNotBeNil)
+		convey.So(o.Set("perf_schema.eventsstatements.limit", "-1"), convey.ShouldNotBeNil)
+		convey.So(o.Set("heartbeat.utc", "maybe"), convey.ShouldNotBeNil)
+		convey.So(o.Set("heartbeat.table", "x`; DROP TABLE y; --"), convey.ShouldNotBeNil)
+		convey.So(o.Set("heartbeat.database", ""), convey.ShouldNotBeNil)
+		convey.So(o, convey.ShouldResemble, DefaultOptions())
+	})
+}
+
+func TestOptionsValidate(t *testing.T) {
+	convey.Convey("Validate the heartbeat identifiers", t, func() {
+		o := DefaultOptions()
+		convey.So(o.Validate(), convey.ShouldBeNil)
+		o.HeartbeatDatabase = "pt-heartbeat"
+		convey.So(o.Validate(), convey.ShouldBeNil)
+		o.HeartbeatTable = "x`y"
+		convey.So(o.Validate(), convey.ShouldNotBeNil)
+		o.HeartbeatTable = strings.Repeat("x", 65)
+		convey.So(o.Validate(), convey.ShouldNotBeNil)
+	})
+}
+
+func TestRequestOptionNames(t *testing.T) {
+	convey.Convey("Only numeric and boolean options can be set by requests", t, func() {
+		names := RequestOptionNames()
+		convey.So(names, convey.ShouldContain, "heartbeat.utc")
+		convey.So(names, convey.ShouldContain, "perf_schema.eventsstatements.limit")
+		convey.So(names, convey.ShouldNotContain, "heartbeat.database")
+		convey.So(names, convey.ShouldNotContain, "heartbeat.table")
+		convey.So(names, convey.ShouldNotContain, "info_schema.tables.databases")
+		convey.So(names, convey.ShouldNotContain, "perf_schema.eventsstatements.exclude_schemas")
+		convey.So(names, convey.ShouldNotContain, "perf_schema.file_instances.filter")
+	})
+}
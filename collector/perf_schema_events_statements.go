@@ -22,7 +22,6 @@ import (
 	"slices"
 	"strings"
 
-	"github.com/alecthomas/kingpin/v2"
 	"github.com/blang/semver/v4"
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -125,26 +124,6 @@ const perfEventsStatementsQueryMySQL = `
 	  LIMIT %d
 	`
 
-// Tunable flags.
-var (
-	perfEventsStatementsLimit = kingpin.Flag(
-		"collect.perf_schema.eventsstatements.limit",
-		"Limit the number of events statements digests by response time",
-	).Default("250").Int()
-	perfEventsStatementsTimeLimit = kingpin.Flag(
-		"collect.perf_schema.eventsstatements.timelimit",
-		"Limit how old the 'last_seen' events statements can be, in seconds",
-	).Default("86400").Int()
-	perfEventsStatementsDigestTextLimit = kingpin.Flag(
-		"collect.perf_schema.eventsstatements.digest_text_limit",
-		"Maximum length of the normalized statement text",
-	).Default("120").Int()
-	perfEventsStatementsExcludeSchemas = kingpin.Flag(
-		"collect.perf_schema.eventsstatements.exclude_schemas",
-		"Additional schema name to exclude (always excludes mysql, performance_schema, information_schema). Repeatable",
-	).Default("").Strings()
-)
-
 var defaultExcludedSchemas = []string{"'mysql'", "'performance_schema'", "'information_schema'"}
 
 // Metric descriptors.
@@ -244,26 +223,40 @@ func (ScrapePerfEventsStatements) Version() float64 {
 	return 5.6
 }
 
+// VersionRanges of the servers from which scraper is available.
+// MariaDB ships the performance schema of MySQL 5.6 since 10.0.
+func (ScrapePerfEventsStatements) VersionRanges() map[string]semver.Range {
+	return map[string]semver.Range{
+		FlavorMySQL:   semver.MustParseRange(">=5.6.0"),
+		FlavorMariaDB: semver.MustParseRange(">=10.0.0"),
+	}
+}
+
+// Requirements of the server for the scraper.
+func (ScrapePerfEventsStatements) Requirements() Requirements {
+	return Requirements{Consumers: []string{"statements_digest"}}
+}
+
 // Scrape collects data from database connection and sends it over channel as prometheus metric.
-func (ScrapePerfEventsStatements) Scrape(ctx context.Context, instance *instance, ch chan<- prometheus.Metric, logger *slog.Logger) error {
-	mysqlVersion8028 := instance.flavor == FlavorMySQL && instance.version.GTE(semver.MustParse("8.0.28"))
+func (ScrapePerfEventsStatements) Scrape(ctx context.Context, instance *Instance, ch chan<- prometheus.Metric, logger *slog.Logger) error {
+	mysqlVersion8028 := instance.flavor != FlavorMariaDB && instance.version.GTE(semver.MustParse("8.0.28"))
 
 	perfQuery := perfEventsStatementsQuery
 	if mysqlVersion8028 {
 		perfQuery = perfEventsStatementsQueryMySQL
 	}
 
-	excludeSchemasList := buildExcludedSchemasList(*perfEventsStatementsExcludeSchemas)
+	excludeSchemasList := buildExcludedSchemasList(instance.options.PerfEventsStatementsExcludeSchemas)
 
 	perfQuery = fmt.Sprintf(
 		perfQuery,
-		*perfEventsStatementsDigestTextLimit,
+		instance.options.PerfEventsStatementsDigestTextLimit,
 		excludeSchemasList,
-		*perfEventsStatementsTimeLimit,
-		*perfEventsStatementsLimit,
+		instance.options.PerfEventsStatementsTimeLimit,
+		instance.options.PerfEventsStatementsLimit,
 	)
 
-	db := instance.getDB()
+	db := instance.DB()
 	// Timers here are returned in picoseconds.
 	perfSchemaEventsStatementsRows, err := db.QueryContext(ctx, perfQuery)
 	if err != nil {
@@ -373,4 +366,5 @@ func buildExcludedSchemasList(extraSchemas []string) string {
 }
 
 // check interface
-var _ Scraper = ScrapePerfEventsStatements{}
+var _ RequirementsScraper = ScrapePerfEventsStatements{}
+var _ VersionRangesScraper = ScrapePerfEventsStatements{}
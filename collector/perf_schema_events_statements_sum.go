@@ -19,6 +19,7 @@ import (
 	"context"
 	"log/slog"
 
+	"github.com/blang/semver/v4"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -175,9 +176,23 @@ func (ScrapePerfEventsStatementsSum) Version() float64 {
 	return 5.7
 }
 
+// VersionRanges of the servers from which scraper is available.
+// MariaDB ships the performance schema of MySQL 5.7 since 10.5.2.
+func (ScrapePerfEventsStatementsSum) VersionRanges() map[string]semver.Range {
+	return map[string]semver.Range{
+		FlavorMySQL:   semver.MustParseRange(">=5.7.0"),
+		FlavorMariaDB: semver.MustParseRange(">=10.5.2"),
+	}
+}
+
+// Requirements of the server for the scraper.
+func (ScrapePerfEventsStatementsSum) Requirements() Requirements {
+	return Requirements{Consumers: []string{"statements_digest"}}
+}
+
 // Scrape collects data from database connection and sends it over channel as prometheus metric.
-func (ScrapePerfEventsStatementsSum) Scrape(ctx context.Context, instance *instance, ch chan<- prometheus.Metric, logger *slog.Logger) error {
-	db := instance.getDB()
+func (ScrapePerfEventsStatementsSum) Scrape(ctx context.Context, instance *Instance, ch chan<- prometheus.Metric, logger *slog.Logger) error {
+	db := instance.DB()
 	// Timers here are returned in picoseconds.
 	perfEventsStatementsSumRows, err := db.QueryContext(ctx, perfEventsStatementsSumQuery)
 	if err != nil {
@@ -273,4 +288,5 @@ func (ScrapePerfEventsStatementsSum) Scrape(ctx context.Context, instance *insta
 }
 
 // check interface
-var _ Scraper = ScrapePerfEventsStatementsSum{}
+var _ RequirementsScraper = ScrapePerfEventsStatementsSum{}
+var _ VersionRangesScraper = ScrapePerfEventsStatementsSum{}
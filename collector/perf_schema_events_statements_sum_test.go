@@ -76,7 +76,7 @@ func TestScrapePerfEventsStatementsSum(t *testing.T) {
 
 	ch := make(chan prometheus.Metric)
 	go func() {
-		if err = (ScrapePerfEventsStatementsSum{}).Scrape(context.Background(), &instance{db: db}, ch, promslog.NewNopLogger()); err != nil {
+		if err = (ScrapePerfEventsStatementsSum{}).Scrape(context.Background(), &Instance{db: db}, ch, promslog.NewNopLogger()); err != nil {
 			t.Errorf("error calling function on test: %s", err)
 		}
 		close(ch)
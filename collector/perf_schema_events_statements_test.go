@@ -32,6 +32,7 @@ func TestScrapePerfEventsStatements(t *testing.T) {
 		t.Fatalf("error opening a stub database connection: %s", err)
 	}
 	defer db.Close()
+	options := DefaultOptions()
 
 	columns := []string{
 		"SCHEMA_NAME", "DIGEST", "DIGEST_TEXT",
@@ -49,12 +50,12 @@ func TestScrapePerfEventsStatements(t *testing.T) {
 			1, 2, 3,
 			100, 1)
 
-	query := fmt.Sprintf(perfEventsStatementsQuery, *perfEventsStatementsDigestTextLimit, buildExcludedSchemasList(*perfEventsStatementsExcludeSchemas), *perfEventsStatementsTimeLimit, *perfEventsStatementsLimit)
+	query := fmt.Sprintf(perfEventsStatementsQuery, options.PerfEventsStatementsDigestTextLimit, buildExcludedSchemasList(options.PerfEventsStatementsExcludeSchemas), options.PerfEventsStatementsTimeLimit, options.PerfEventsStatementsLimit)
 	mock.ExpectQuery(sanitizeQuery(query)).WillReturnRows(rows)
 
 	ch := make(chan prometheus.Metric)
 	go func() {
-		if err = (ScrapePerfEventsStatements{}).Scrape(context.Background(), &instance{db: db}, ch, promslog.NewNopLogger()); err != nil {
+		if err = (ScrapePerfEventsStatements{}).Scrape(context.Background(), &Instance{db: db, options: options}, ch, promslog.NewNopLogger()); err != nil {
 			t.Errorf("error calling function on test: %s", err)
 		}
 		close(ch)
@@ -97,10 +98,12 @@ func TestScrapePerfEventsStatementsMySQL8028(t *testing.T) {
 	}
 	defer db.Close()
 
-	inst := &instance{
+	options := DefaultOptions()
+	inst := &Instance{
 		db:      db,
 		flavor:  FlavorMySQL,
 		version: semver.MustParse("8.0.28"),
+		options: options,
 	}
 
 	columns := []string{
@@ -125,7 +128,7 @@ func TestScrapePerfEventsStatementsMySQL8028(t *testing.T) {
 			100, 1,
 			100, 150, 200)
 
-	query := fmt.Sprintf(perfEventsStatementsQueryMySQL, *perfEventsStatementsDigestTextLimit, buildExcludedSchemasList(*perfEventsStatementsExcludeSchemas), *perfEventsStatementsTimeLimit, *perfEventsStatementsLimit)
+	query := fmt.Sprintf(perfEventsStatementsQueryMySQL, options.PerfEventsStatementsDigestTextLimit, buildExcludedSchemasList(options.PerfEventsStatementsExcludeSchemas), options.PerfEventsStatementsTimeLimit, options.PerfEventsStatementsLimit)
 	mock.ExpectQuery(sanitizeQuery(query)).WillReturnRows(rows)
 
 	ch := make(chan prometheus.Metric)
@@ -19,6 +19,7 @@ import (
 	"context"
 	"log/slog"
 
+	"github.com/blang/semver/v4"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -59,9 +60,17 @@ func (ScrapePerfEventsWaits) Version() float64 {
 	return 5.5
 }
 
+// VersionRanges of the servers from which scraper is available.
+func (ScrapePerfEventsWaits) VersionRanges() map[string]semver.Range {
+	return map[string]semver.Range{
+		FlavorMySQL:   semver.MustParseRange(">=5.5.0"),
+		FlavorMariaDB: semver.MustParseRange(">=5.5.0"),
+	}
+}
+
 // Scrape collects data from database connection and sends it over channel as prometheus metric.
-func (ScrapePerfEventsWaits) Scrape(ctx context.Context, instance *instance, ch chan<- prometheus.Metric, logger *slog.Logger) error {
-	db := instance.getDB()
+func (ScrapePerfEventsWaits) Scrape(ctx context.Context, instance *Instance, ch chan<- prometheus.Metric, logger *slog.Logger) error {
+	db := instance.DB()
 	// Timers here are returned in picoseconds.
 	perfSchemaEventsWaitsRows, err := db.QueryContext(ctx, perfEventsWaitsQuery)
 	if err != nil {
@@ -93,4 +102,4 @@ func (ScrapePerfEventsWaits) Scrape(ctx context.Context, instance *instance, ch
 }
 
 // check interface
-var _ Scraper = ScrapePerfEventsWaits{}
+var _ VersionRangesScraper = ScrapePerfEventsWaits{}
@@ -19,6 +19,7 @@ import (
 	"context"
 	"log/slog"
 
+	"github.com/blang/semver/v4"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -68,9 +69,18 @@ func (ScrapePerfFileEvents) Version() float64 {
 	return 5.6
 }
 
+// VersionRanges of the servers from which scraper is available.
+// MariaDB ships the performance schema of MySQL 5.6 since 10.0.
+func (ScrapePerfFileEvents) VersionRanges() map[string]semver.Range {
+	return map[string]semver.Range{
+		FlavorMySQL:   semver.MustParseRange(">=5.6.0"),
+		FlavorMariaDB: semver.MustParseRange(">=10.0.0"),
+	}
+}
+
 // Scrape collects data from database connection and sends it over channel as prometheus metric.
-func (ScrapePerfFileEvents) Scrape(ctx context.Context, instance *instance, ch chan<- prometheus.Metric, logger *slog.Logger) error {
-	db := instance.getDB()
+func (ScrapePerfFileEvents) Scrape(ctx context.Context, instance *Instance, ch chan<- prometheus.Metric, logger *slog.Logger) error {
+	db := instance.DB()
 	// Timers here are returned in picoseconds.
 	perfSchemaFileEventsRows, err := db.QueryContext(ctx, perfFileEventsQuery)
 	if err != nil {
@@ -130,4 +140,4 @@ func (ScrapePerfFileEvents) Scrape(ctx context.Context, instance *instance, ch c
 }
 
 // check interface
-var _ Scraper = ScrapePerfFileEvents{}
+var _ VersionRangesScraper = ScrapePerfFileEvents{}
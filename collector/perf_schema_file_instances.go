@@ -20,7 +20,7 @@ import (
 	"log/slog"
 	"strings"
 
-	"github.com/alecthomas/kingpin/v2"
+	"github.com/blang/semver/v4"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -33,19 +33,6 @@ const perfFileInstancesQuery = `
 	     where FILE_NAME REGEXP ?
 	`
 
-// Tunable flags.
-var (
-	performanceSchemaFileInstancesFilter = kingpin.Flag(
-		"collect.perf_schema.file_instances.filter",
-		"RegEx file_name filter for performance_schema.file_summary_by_instance",
-	).Default(".*").String()
-
-	performanceSchemaFileInstancesRemovePrefix = kingpin.Flag(
-		"collect.perf_schema.file_instances.remove_prefix",
-		"Remove path prefix in performance_schema.file_summary_by_instance",
-	).Default("/var/lib/mysql/").String()
-)
-
 // Metric descriptors.
 var (
 	performanceSchemaFileInstancesBytesDesc = prometheus.NewDesc(
@@ -78,11 +65,19 @@ func (ScrapePerfFileInstances) Version() float64 {
 	return 5.5
 }
 
+// VersionRanges of the servers from which scraper is available.
+func (ScrapePerfFileInstances) VersionRanges() map[string]semver.Range {
+	return map[string]semver.Range{
+		FlavorMySQL:   semver.MustParseRange(">=5.5.0"),
+		FlavorMariaDB: semver.MustParseRange(">=5.5.0"),
+	}
+}
+
 // Scrape collects data from database connection and sends it over channel as prometheus metric.
-func (ScrapePerfFileInstances) Scrape(ctx context.Context, instance *instance, ch chan<- prometheus.Metric, logger *slog.Logger) error {
-	db := instance.getDB()
+func (ScrapePerfFileInstances) Scrape(ctx context.Context, instance *Instance, ch chan<- prometheus.Metric, logger *slog.Logger) error {
+	db := instance.DB()
 	// Timers here are returned in picoseconds.
-	perfSchemaFileInstancesRows, err := db.QueryContext(ctx, perfFileInstancesQuery, *performanceSchemaFileInstancesFilter)
+	perfSchemaFileInstancesRows, err := db.QueryContext(ctx, perfFileInstancesQuery, instance.options.PerfFileInstancesFilter)
 	if err != nil {
 		return err
 	}
@@ -103,7 +98,7 @@ func (ScrapePerfFileInstances) Scrape(ctx context.Context, instance *instance, c
 			return err
 		}
 
-		fileName = strings.TrimPrefix(fileName, *performanceSchemaFileInstancesRemovePrefix)
+		fileName = strings.TrimPrefix(fileName, instance.options.PerfFileInstancesRemovePrefix)
 		ch <- prometheus.MustNewConstMetric(
 			performanceSchemaFileInstancesCountDesc, prometheus.CounterValue, float64(countRead),
 			fileName, eventName, "read",
@@ -126,4 +121,4 @@ func (ScrapePerfFileInstances) Scrape(ctx context.Context, instance *instance, c
 }
 
 // check interface
-var _ Scraper = ScrapePerfFileInstances{}
+var _ VersionRangesScraper = ScrapePerfFileInstances{}
@@ -20,7 +20,7 @@ import (
 	"log/slog"
 	"strings"
 
-	"github.com/alecthomas/kingpin/v2"
+	"github.com/blang/semver/v4"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -32,14 +32,6 @@ const perfMemoryEventsQuery = `
 		where COUNT_ALLOC > 0;
 `
 
-// Tunable flags.
-var (
-	performanceSchemaMemoryEventsRemovePrefix = kingpin.Flag(
-		"collect.perf_schema.memory_events.remove_prefix",
-		"Remove instrument prefix in performance_schema.memory_summary_global_by_event_name",
-	).Default("memory/").String()
-)
-
 // Metric descriptors.
 var (
 	performanceSchemaMemoryBytesAllocDesc = prometheus.NewDesc(
@@ -77,9 +69,23 @@ func (ScrapePerfMemoryEvents) Version() float64 {
 	return 5.7
 }
 
+// VersionRanges of the servers from which scraper is available.
+// MariaDB instruments memory in the performance schema since 10.5.2.
+func (ScrapePerfMemoryEvents) VersionRanges() map[string]semver.Range {
+	return map[string]semver.Range{
+		FlavorMySQL:   semver.MustParseRange(">=5.7.0"),
+		FlavorMariaDB: semver.MustParseRange(">=10.5.2"),
+	}
+}
+
+// Requirements of the server for the scraper.
+func (ScrapePerfMemoryEvents) Requirements() Requirements {
+	return Requirements{Tables: []string{"performance_schema.memory_summary_global_by_event_name"}}
+}
+
 // Scrape collects data from database connection and sends it over channel as prometheus metric.
-func (ScrapePerfMemoryEvents) Scrape(ctx context.Context, instance *instance, ch chan<- prometheus.Metric, logger *slog.Logger) error {
-	db := instance.getDB()
+func (ScrapePerfMemoryEvents) Scrape(ctx context.Context, instance *Instance, ch chan<- prometheus.Metric, logger *slog.Logger) error {
+	db := instance.DB()
 	perfSchemaMemoryEventsRows, err := db.QueryContext(ctx, perfMemoryEventsQuery)
 	if err != nil {
 		return err
@@ -100,7 +106,7 @@ func (ScrapePerfMemoryEvents) Scrape(ctx context.Context, instance *instance, ch
 			return err
 		}
 
-		eventName := strings.TrimPrefix(eventName, *performanceSchemaMemoryEventsRemovePrefix)
+		eventName := strings.TrimPrefix(eventName, instance.options.PerfMemoryEventsRemovePrefix)
 		ch <- prometheus.MustNewConstMetric(
 			performanceSchemaMemoryBytesAllocDesc, prometheus.CounterValue, float64(bytesAlloc), eventName,
 		)
@@ -115,4 +121,5 @@ func (ScrapePerfMemoryEvents) Scrape(ctx context.Context, instance *instance, ch
 }
 
 // check interface
-var _ Scraper = ScrapePerfMemoryEvents{}
+var _ VersionRangesScraper = ScrapePerfMemoryEvents{}
+var _ RequirementsScraper = ScrapePerfMemoryEvents{}
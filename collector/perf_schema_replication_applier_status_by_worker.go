@@ -18,6 +18,7 @@ import (
 	"log/slog"
 	"time"
 
+	"github.com/blang/semver/v4"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -99,9 +100,21 @@ func (ScrapePerfReplicationApplierStatsByWorker) Version() float64 {
 	return 8.0
 }
 
+// VersionRanges of the servers from which scraper is available.
+func (ScrapePerfReplicationApplierStatsByWorker) VersionRanges() map[string]semver.Range {
+	return map[string]semver.Range{
+		FlavorMySQL: semver.MustParseRange(">=8.0.0"),
+	}
+}
+
+// Requirements of the server for the scraper.
+func (ScrapePerfReplicationApplierStatsByWorker) Requirements() Requirements {
+	return Requirements{Tables: []string{"performance_schema.replication_applier_status_by_worker"}}
+}
+
 // Scrape collects data from database connection and sends it over channel as prometheus metric.
-func (ScrapePerfReplicationApplierStatsByWorker) Scrape(ctx context.Context, instance *instance, ch chan<- prometheus.Metric, logger *slog.Logger) error {
-	db := instance.getDB()
+func (ScrapePerfReplicationApplierStatsByWorker) Scrape(ctx context.Context, instance *Instance, ch chan<- prometheus.Metric, logger *slog.Logger) error {
+	db := instance.DB()
 	perfReplicationApplierStatsByWorkerRows, err := db.QueryContext(ctx, perfReplicationApplierStatsByWorkerQuery)
 	if err != nil {
 		return err
@@ -234,4 +247,5 @@ func (ScrapePerfReplicationApplierStatsByWorker) Scrape(ctx context.Context, ins
 }
 
 // check interface
-var _ Scraper = ScrapePerfReplicationApplierStatsByWorker{}
+var _ VersionRangesScraper = ScrapePerfReplicationApplierStatsByWorker{}
+var _ RequirementsScraper = ScrapePerfReplicationApplierStatsByWorker{}
@@ -31,7 +31,7 @@ func TestScrapePerfReplicationApplierStatsByWorker(t *testing.T) {
 		t.Fatalf("error opening a stub database connection: %s", err)
 	}
 	defer db.Close()
-	inst := &instance{db: db}
+	inst := &Instance{db: db}
 
 	columns := []string{
 		"CHANNEL_NAME",
@@ -19,6 +19,7 @@ import (
 	"log/slog"
 	"strconv"
 
+	"github.com/blang/semver/v4"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -78,9 +79,22 @@ func (ScrapePerfReplicationGroupMemberStats) Version() float64 {
 	return 5.7
 }
 
+// VersionRanges of the servers from which scraper is available.
+// Group Replication is not available on MariaDB.
+func (ScrapePerfReplicationGroupMemberStats) VersionRanges() map[string]semver.Range {
+	return map[string]semver.Range{
+		FlavorMySQL: semver.MustParseRange(">=5.7.0"),
+	}
+}
+
+// Requirements of the server for the scraper.
+func (ScrapePerfReplicationGroupMemberStats) Requirements() Requirements {
+	return Requirements{Tables: []string{"performance_schema.replication_group_member_stats"}}
+}
+
 // Scrape collects data from database connection and sends it over channel as prometheus metric.
-func (ScrapePerfReplicationGroupMemberStats) Scrape(ctx context.Context, instance *instance, ch chan<- prometheus.Metric, logger *slog.Logger) error {
-	db := instance.getDB()
+func (ScrapePerfReplicationGroupMemberStats) Scrape(ctx context.Context, instance *Instance, ch chan<- prometheus.Metric, logger *slog.Logger) error {
+	db := instance.DB()
 	rows, err := db.QueryContext(ctx, perfReplicationGroupMemberStatsQuery)
 	if err != nil {
 		return err
@@ -116,4 +130,5 @@ func (ScrapePerfReplicationGroupMemberStats) Scrape(ctx context.Context, instanc
 }
 
 // check interface
-var _ Scraper = ScrapePerfReplicationGroupMemberStats{}
+var _ VersionRangesScraper = ScrapePerfReplicationGroupMemberStats{}
+var _ RequirementsScraper = ScrapePerfReplicationGroupMemberStats{}
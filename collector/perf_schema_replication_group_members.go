@@ -19,6 +19,7 @@ import (
 	"log/slog"
 	"strings"
 
+	"github.com/blang/semver/v4"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -44,9 +45,22 @@ func (ScrapePerfReplicationGroupMembers) Version() float64 {
 	return 5.7
 }
 
+// VersionRanges of the servers from which scraper is available.
+// Group Replication is not available on MariaDB.
+func (ScrapePerfReplicationGroupMembers) VersionRanges() map[string]semver.Range {
+	return map[string]semver.Range{
+		FlavorMySQL: semver.MustParseRange(">=5.7.0"),
+	}
+}
+
+// Requirements of the server for the scraper.
+func (ScrapePerfReplicationGroupMembers) Requirements() Requirements {
+	return Requirements{Tables: []string{"performance_schema.replication_group_members"}}
+}
+
 // Scrape collects data from database connection and sends it over channel as prometheus metric.
-func (ScrapePerfReplicationGroupMembers) Scrape(ctx context.Context, instance *instance, ch chan<- prometheus.Metric, logger *slog.Logger) error {
-	db := instance.getDB()
+func (ScrapePerfReplicationGroupMembers) Scrape(ctx context.Context, instance *Instance, ch chan<- prometheus.Metric, logger *slog.Logger) error {
+	db := instance.DB()
 	perfReplicationGroupMembersRows, err := db.QueryContext(ctx, perfReplicationGroupMembersQuery)
 	if err != nil {
 		return err
@@ -91,4 +105,5 @@ func (ScrapePerfReplicationGroupMembers) Scrape(ctx context.Context, instance *i
 }
 
 // check interface
-var _ Scraper = ScrapePerfReplicationGroupMembers{}
+var _ VersionRangesScraper = ScrapePerfReplicationGroupMembers{}
+var _ RequirementsScraper = ScrapePerfReplicationGroupMembers{}
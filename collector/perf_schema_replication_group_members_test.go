@@ -30,7 +30,7 @@ func TestScrapePerfReplicationGroupMembers(t *testing.T) {
 		t.Fatalf("error opening a stub database connection: %s", err)
 	}
 	defer db.Close()
-	inst := &instance{db: db}
+	inst := &Instance{db: db}
 
 	columns := []string{
 		"CHANNEL_NAME",
@@ -84,7 +84,7 @@ func TestScrapePerfReplicationGroupMembersMySQL57(t *testing.T) {
 		t.Fatalf("error opening a stub database connection: %s", err)
 	}
 	defer db.Close()
-	inst := &instance{db: db}
+	inst := &Instance{db: db}
 
 	columns := []string{
 		"CHANNEL_NAME",
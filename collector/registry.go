@@ -0,0 +1,88 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"fmt"
+	"maps"
+	"sync"
+)
+
+var (
+	scrapersMu sync.Mutex
+	// scrapers lists all registered scrapers and if they should be enabled by default.
+	scrapers = map[Scraper]bool{}
+)
+
+func init() {
+	Register(ScrapeGlobalStatus{}, true)
+	Register(ScrapeGlobalVariables{}, true)
+	Register(ScrapeSlaveStatus{}, true)
+	Register(ScrapeProcesslist{}, false)
+	Register(ScrapeUser{}, false)
+	Register(ScrapeTableSchema{}, false)
+	Register(ScrapeInfoSchemaInnodbTablespaces{}, false)
+	Register(ScrapeInnodbMetrics{}, false)
+	Register(ScrapeAutoIncrementColumns{}, false)
+	Register(ScrapeBinlogSize{}, false)
+	Register(ScrapePerfTableIOWaits{}, false)
+	Register(ScrapePerfIndexIOWaits{}, false)
+	Register(ScrapePerfTableLockWaits{}, false)
+	Register(ScrapePerfEventsStatements{}, false)
+	Register(ScrapePerfEventsStatementsSum{}, false)
+	Register(ScrapePerfEventsWaits{}, false)
+	Register(ScrapePerfFileEvents{}, false)
+	Register(ScrapePerfFileInstances{}, false)
+	Register(ScrapePerfMemoryEvents{}, false)
+	Register(ScrapePerfReplicationGroupMembers{}, false)
+	Register(ScrapePerfReplicationGroupMemberStats{}, false)
+	Register(ScrapePerfReplicationApplierStatsByWorker{}, false)
+	Register(ScrapeSysUserSummary{}, false)
+	Register(ScrapeUserStat{}, false)
+	Register(ScrapeClientStat{}, false)
+	Register(ScrapeTableStat{}, false)
+	Register(ScrapeSchemaStat{}, false)
+	Register(ScrapeInnodbCmp{}, true)
+	Register(ScrapeInnodbCmpMem{}, true)
+	Register(ScrapeQueryResponseTime{}, true)
+	Register(ScrapeEngineTokudbStatus{}, false)
+	Register(ScrapeEngineInnodbStatus{}, false)
+	Register(ScrapeHeartbeat{}, false)
+	Register(ScrapeSlaveHosts{}, false)
+	Register(ScrapeReplicaHost{}, false)
+	Register(ScrapeRocksDBPerfContext{}, false)
+}
+
+// Register makes a scraper available to the exporter, e.g. so that it can
+// be enabled with a `--collect.<name>` flag. It is meant to be called from
+// the init function of the package providing the scraper, and panics if a
+// scraper with the same name is already registered.
+func Register(scraper Scraper, enabledByDefault bool) {
+	scrapersMu.Lock()
+	defer scrapersMu.Unlock()
+	for registered := range scrapers {
+		if registered.Name() == scraper.Name() {
+			panic(fmt.Sprintf("collector: scraper %q is already registered", scraper.Name()))
+		}
+	}
+	scrapers[scraper] = enabledByDefault
+}
+
+// Scrapers returns all registered scrapers and if they should be enabled by
+// default.
+func Scrapers() map[Scraper]bool {
+	scrapersMu.Lock()
+	defer scrapersMu.Unlock()
+	return maps.Clone(scrapers)
+}
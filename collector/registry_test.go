@@ -0,0 +1,46 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"testing"
+
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestRegister(t *testing.T) {
+	convey.Convey("Built-in scrapers are registered", t, func() {
+		registered := Scrapers()
+		convey.So(registered, convey.ShouldContainKey, ScrapeGlobalStatus{})
+		convey.So(registered[ScrapeGlobalStatus{}], convey.ShouldBeTrue)
+		convey.So(registered[ScrapeProcesslist{}], convey.ShouldBeFalse)
+	})
+
+	convey.Convey("Third-party scrapers can be registered", t, func() {
+		scraper := &mockScraper{name: "third_party"}
+		Register(scraper, true)
+		defer func() {
+			scrapersMu.Lock()
+			delete(scrapers, scraper)
+			scrapersMu.Unlock()
+		}()
+
+		registered := Scrapers()
+		convey.So(registered[scraper], convey.ShouldBeTrue)
+
+		convey.Convey("Registering a scraper name twice panics", func() {
+			convey.So(func() { Register(&mockScraper{name: "third_party"}, false) }, convey.ShouldPanic)
+		})
+	})
+}
@@ -0,0 +1,343 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"database/sql/driver"
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metric descriptors.
+var (
+	collectorErrorsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, exporter, "collector_errors_total"),
+		"Number of failed runs of a collector by error class and MySQL error code.",
+		[]string{"collector", "error_class", "mysql_error_code"}, nil,
+	)
+	collectorLastErrorDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, exporter, "collector_last_error_info"),
+		"The last error of a collector which did not succeed since.",
+		[]string{"collector", "error_class", "mysql_error_code", "error"}, nil,
+	)
+	connectErrorsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, exporter, "connect_errors_total"),
+		"Number of failed connections to the MySQL server by reason.",
+		[]string{"reason"}, nil,
+	)
+	lastConnectErrorDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, exporter, "last_connect_error_info"),
+		"The last error connecting to the MySQL server, if the exporter did not connect since.",
+		[]string{"reason", "error"}, nil,
+	)
+)
+
+// Classes of scrape errors.
+const (
+	errorClassAccessDenied    = "access_denied"
+	errorClassLockWaitTimeout = "lock_wait_timeout"
+	errorClassDeadlock        = "deadlock"
+	errorClassTimeout         = "timeout"
+	errorClassCanceled        = "canceled"
+	errorClassConnection      = "connection"
+	errorClassMySQL           = "mysql"
+	errorClassOther           = "other"
+)
+
+// Reasons of connect errors.
+const (
+	connectErrorDNS                = "dns"
+	connectErrorRefused            = "connection_refused"
+	connectErrorTimeout            = "timeout"
+	connectErrorTLS                = "tls"
+	connectErrorAccessDenied       = "access_denied"
+	connectErrorTooManyConnections = "too_many_connections"
+	connectErrorVersion            = "version"
+	connectErrorConnection         = "connection"
+	connectErrorMySQL              = "mysql"
+	connectErrorOther              = "other"
+)
+
+// maxLastErrorLength limits the length of the error label of the last error.
+const maxLastErrorLength = 256
+
+// classifyError returns the class of a scrape error and its MySQL error code,
+// which is empty for errors not returned by the server.
+func classifyError(err error) (string, string) {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		code := strconv.Itoa(int(mysqlErr.Number))
+		switch mysqlErr.Number {
+		case 1044, 1045, 1142, 1143, 1227, 1370:
+			return errorClassAccessDenied, code
+		case 1205:
+			return errorClassLockWaitTimeout, code
+		case 1213:
+			return errorClassDeadlock, code
+		case 3024:
+			// Query execution was interrupted, maximum statement execution time exceeded.
+			return errorClassTimeout, code
+		default:
+			return errorClassMySQL, code
+		}
+	}
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return errorClassTimeout, ""
+	case errors.Is(err, context.Canceled):
+		return errorClassCanceled, ""
+	case errors.Is(err, driver.ErrBadConn), errors.Is(err, mysql.ErrInvalidConn), errors.As(err, &netErr):
+		return errorClassConnection, ""
+	}
+	return errorClassOther, ""
+}
+
+// TLSVerificationError is an error verifying the TLS connection to the
+// server outside of crypto/tls, e.g. by tls.Config.VerifyConnection, so that
+// it is counted as a TLS error.
+type TLSVerificationError struct {
+	Err error
+}
+
+func (e *TLSVerificationError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *TLSVerificationError) Unwrap() error {
+	return e.Err
+}
+
+// classifyConnectError returns the reason of an error connecting to the
+// server.
+func classifyConnectError(err error) string {
+	var (
+		mysqlErr         *mysql.MySQLError
+		dnsErr           *net.DNSError
+		recordHeaderErr  tls.RecordHeaderError
+		alertErr         tls.AlertError
+		verificationErr  *tls.CertificateVerificationError
+		unknownAuthority x509.UnknownAuthorityError
+		hostnameErr      x509.HostnameError
+		invalidCertErr   x509.CertificateInvalidError
+		tlsVerifyErr     *TLSVerificationError
+		netErr           net.Error
+	)
+	switch {
+	case errors.As(err, &mysqlErr):
+		switch mysqlErr.Number {
+		case 1044, 1045, 1698:
+			return connectErrorAccessDenied
+		case 1040, 1203, 1226:
+			return connectErrorTooManyConnections
+		default:
+			return connectErrorMySQL
+		}
+	case errors.Is(err, errParseVersion):
+		return connectErrorVersion
+	case errors.As(err, &dnsErr):
+		return connectErrorDNS
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return connectErrorRefused
+	case errors.As(err, &recordHeaderErr), errors.As(err, &alertErr), errors.As(err, &verificationErr),
+		errors.As(err, &unknownAuthority), errors.As(err, &hostnameErr), errors.As(err, &invalidCertErr),
+		errors.As(err, &tlsVerifyErr):
+		return connectErrorTLS
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return connectErrorTimeout
+	case errors.Is(err, driver.ErrBadConn), errors.Is(err, mysql.ErrInvalidConn), errors.As(err, &netErr):
+		return connectErrorConnection
+	}
+	return connectErrorOther
+}
+
+// ScrapeErrors counts the connect errors and the errors of the scrapers per
+// target and auth module, so that the counts persist across the exporters
+// created for every scrape. The counts of a target are removed once it has
+// not been scraped for the configured idle timeout.
+type ScrapeErrors struct {
+	mu          sync.Mutex
+	idleTimeout time.Duration
+	targets     map[scrapeTarget]*targetErrors
+
+	// now can be replaced in tests.
+	now func() time.Time
+}
+
+// scrapeTarget identifies the errors of a target. It does not include the
+// credentials, so that the counts persist across password rotations.
+type scrapeTarget struct {
+	target     string
+	authModule string
+}
+
+// targetErrors are the errors of a target.
+type targetErrors struct {
+	connect    errorCounts
+	collectors map[string]*errorCounts
+	lastUsed   time.Time
+}
+
+// errorCounts counts errors by class and keeps the last one.
+type errorCounts struct {
+	counts    map[scrapeErrorKey]float64
+	lastError *scrapeError
+}
+
+type scrapeErrorKey struct {
+	class string
+	code  string
+}
+
+type scrapeError struct {
+	scrapeErrorKey
+	message string
+}
+
+// NewScrapeErrors returns a new ScrapeErrors which removes the counts of
+// targets that have not been scraped for idleTimeout. Zero keeps the counts
+// of all targets.
+func NewScrapeErrors(idleTimeout time.Duration) *ScrapeErrors {
+	return &ScrapeErrors{
+		idleTimeout: idleTimeout,
+		targets:     make(map[scrapeTarget]*targetErrors),
+		now:         time.Now,
+	}
+}
+
+// SetIdleTimeout replaces the idle timeout after which the counts of a
+// target are removed, e.g. on a reload of the config.
+func (s *ScrapeErrors) SetIdleTimeout(idleTimeout time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.idleTimeout = idleTimeout
+}
+
+// targetLocked returns the errors of the target and marks them as used. If
+// there are none yet, they are only created if create is set.
+func (s *ScrapeErrors) targetLocked(target scrapeTarget, create bool) *targetErrors {
+	s.evictIdleLocked()
+	t, ok := s.targets[target]
+	if !ok {
+		if !create {
+			return nil
+		}
+		t = &targetErrors{collectors: make(map[string]*errorCounts)}
+		s.targets[target] = t
+	}
+	t.lastUsed = s.now()
+	return t
+}
+
+func (s *ScrapeErrors) evictIdleLocked() {
+	if s.idleTimeout <= 0 {
+		return
+	}
+	now := s.now()
+	for target, t := range s.targets {
+		if now.Sub(t.lastUsed) > s.idleTimeout {
+			delete(s.targets, target)
+		}
+	}
+}
+
+// record records the result of a scraper run against the target.
+func (s *ScrapeErrors) record(target scrapeTarget, collector string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t := s.targetLocked(target, err != nil)
+	if t == nil {
+		return
+	}
+	errs, ok := t.collectors[collector]
+	if !ok {
+		if err == nil {
+			return
+		}
+		errs = &errorCounts{}
+		t.collectors[collector] = errs
+	}
+	if err == nil {
+		errs.lastError = nil
+		return
+	}
+	class, code := classifyError(err)
+	errs.add(scrapeErrorKey{class: class, code: code}, err)
+}
+
+// recordConnect records the result of connecting to the target.
+func (s *ScrapeErrors) recordConnect(target scrapeTarget, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t := s.targetLocked(target, err != nil)
+	if t == nil {
+		return
+	}
+	if err == nil {
+		t.connect.lastError = nil
+		return
+	}
+	t.connect.add(scrapeErrorKey{class: classifyConnectError(err)}, err)
+}
+
+func (c *errorCounts) add(key scrapeErrorKey, err error) {
+	if c.counts == nil {
+		c.counts = make(map[scrapeErrorKey]float64)
+	}
+	c.counts[key]++
+	message := err.Error()
+	if len(message) > maxLastErrorLength {
+		message = strings.ToValidUTF8(message[:maxLastErrorLength-3], "") + "..."
+	}
+	c.lastError = &scrapeError{scrapeErrorKey: key, message: message}
+}
+
+// collect sends the error metrics of the target.
+func (s *ScrapeErrors) collect(target scrapeTarget, ch chan<- prometheus.Metric) {
+	var metrics []prometheus.Metric
+	s.mu.Lock()
+	if t := s.targetLocked(target, false); t != nil {
+		for key, count := range t.connect.counts {
+			metrics = append(metrics, prometheus.MustNewConstMetric(connectErrorsDesc, prometheus.CounterValue, count, key.class))
+		}
+		if lastError := t.connect.lastError; lastError != nil {
+			metrics = append(metrics, prometheus.MustNewConstMetric(lastConnectErrorDesc, prometheus.GaugeValue, 1, lastError.class, lastError.message))
+		}
+		for collector, errs := range t.collectors {
+			for key, count := range errs.counts {
+				metrics = append(metrics, prometheus.MustNewConstMetric(collectorErrorsDesc, prometheus.CounterValue, count, collector, key.class, key.code))
+			}
+			if lastError := errs.lastError; lastError != nil {
+				metrics = append(metrics, prometheus.MustNewConstMetric(collectorLastErrorDesc, prometheus.GaugeValue, 1,
+					collector, lastError.class, lastError.code, lastError.message))
+			}
+		}
+	}
+	s.mu.Unlock()
+
+	for _, m := range metrics {
+		ch <- m
+	}
+}
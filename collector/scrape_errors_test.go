@@ -0,0 +1,198 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"crypto/x509"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/promslog"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		err       error
+		wantClass string
+		wantCode  string
+	}{
+		{&mysql.MySQLError{Number: 1142, Message: "SELECT command denied"}, errorClassAccessDenied, "1142"},
+		{fmt.Errorf("wrapped: %w", &mysql.MySQLError{Number: 1227}), errorClassAccessDenied, "1227"},
+		{&mysql.MySQLError{Number: 1205}, errorClassLockWaitTimeout, "1205"},
+		{&mysql.MySQLError{Number: 1213}, errorClassDeadlock, "1213"},
+		{&mysql.MySQLError{Number: 1146}, errorClassMySQL, "1146"},
+		{context.DeadlineExceeded, errorClassTimeout, ""},
+		{fmt.Errorf("query: %w", context.Canceled), errorClassCanceled, ""},
+		{driver.ErrBadConn, errorClassConnection, ""},
+		{errors.New("sql: Scan error"), errorClassOther, ""},
+	}
+	convey.Convey("Error classes", t, func() {
+		for _, tt := range tests {
+			class, code := classifyError(tt.err)
+			convey.So(class, convey.ShouldEqual, tt.wantClass)
+			convey.So(code, convey.ShouldEqual, tt.wantCode)
+		}
+	})
+}
+
+func TestScrapeErrors(t *testing.T) {
+	var scrapeErr error
+	scraper := &mockScraper{
+		name: "failing",
+		validate: func(context.Context, *Instance) error {
+			return scrapeErr
+		},
+	}
+	var scrapeErrors *ScrapeErrors
+
+	collectDSN := func(dsn, authModule string) map[string]MetricResult {
+		exporter := New(context.Background(), dsn, []Scraper{scraper}, promslog.NewNopLogger(), SetScrapeErrors(scrapeErrors, authModule))
+		ch := make(chan prometheus.Metric)
+		go func() {
+			exporter.runScraper(context.Background(), scraper, &Instance{}, ch)
+			exporter.scrapeErrors.collect(exporter.scrapeTarget(), ch)
+			close(ch)
+		}()
+		got := map[string]MetricResult{}
+		for m := range ch {
+			switch m.Desc() {
+			case collectorErrorsDesc, collectorLastErrorDesc:
+				r := readMetric(m)
+				got[m.Desc().String()+r.labels["error_class"]] = r
+			}
+		}
+		return got
+	}
+	collect := func() map[string]MetricResult { return collectDSN("exporter:old@tcp(db:3306)/", "client") }
+	errorsKey := func(class string) string { return collectorErrorsDesc.String() + class }
+	lastErrorKey := func(class string) string { return collectorLastErrorDesc.String() + class }
+
+	convey.Convey("Scrape errors persist across exporters", t, func() {
+		scrapeErrors = NewScrapeErrors(0)
+		scrapeErr = &mysql.MySQLError{Number: 1142, Message: "SELECT command denied"}
+		collect()
+		got := collect()
+		convey.So(got[errorsKey(errorClassAccessDenied)], convey.ShouldResemble, MetricResult{
+			labels:     labelMap{"collector": "collect.failing", "error_class": errorClassAccessDenied, "mysql_error_code": "1142"},
+			value:      2,
+			metricType: dto.MetricType_COUNTER,
+		})
+		convey.So(got[lastErrorKey(errorClassAccessDenied)].labels["error"], convey.ShouldEqual, "Error 1142: SELECT command denied")
+
+		scrapeErr = fmt.Errorf("scrape: %w, %s", context.DeadlineExceeded, strings.Repeat("x", 300))
+		got = collect()
+		convey.So(got[errorsKey(errorClassAccessDenied)].value, convey.ShouldEqual, 2)
+		convey.So(got[errorsKey(errorClassTimeout)].value, convey.ShouldEqual, 1)
+		convey.So(got, convey.ShouldNotContainKey, lastErrorKey(errorClassAccessDenied))
+		convey.So(len(got[lastErrorKey(errorClassTimeout)].labels["error"]), convey.ShouldEqual, maxLastErrorLength)
+
+		convey.Convey("The last error is cleared once the collector succeeds", func() {
+			scrapeErr = nil
+			got = collect()
+			convey.So(got[errorsKey(errorClassTimeout)].value, convey.ShouldEqual, 1)
+			convey.So(got, convey.ShouldNotContainKey, lastErrorKey(errorClassTimeout))
+		})
+
+		convey.Convey("Scrape errors persist across password rotations", func() {
+			got = collectDSN("exporter:new@tcp(db:3306)/", "client")
+			convey.So(got[errorsKey(errorClassTimeout)].value, convey.ShouldEqual, 2)
+		})
+
+		convey.Convey("Scrape errors are counted per auth module", func() {
+			got = collectDSN("exporter:old@tcp(db:3306)/", "client.other")
+			convey.So(got[errorsKey(errorClassTimeout)].value, convey.ShouldEqual, 1)
+		})
+	})
+}
+
+func TestClassifyConnectError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{&mysql.MySQLError{Number: 1045, Message: "Access denied for user 'exporter'@'localhost'"}, connectErrorAccessDenied},
+		{&mysql.MySQLError{Number: 1040, Message: "Too many connections"}, connectErrorTooManyConnections},
+		{&mysql.MySQLError{Number: 1129}, connectErrorMySQL},
+		{fmt.Errorf("%w from %q", errParseVersion, "unknown"), connectErrorVersion},
+		{&net.OpError{Op: "dial", Err: &net.DNSError{Err: "no such host", Name: "db"}}, connectErrorDNS},
+		{&net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}, connectErrorRefused},
+		{fmt.Errorf("handshake: %w", x509.UnknownAuthorityError{}), connectErrorTLS},
+		{&TLSVerificationError{Err: errors.New(`certificate "CN=mysql.example" was revoked`)}, connectErrorTLS},
+		{&TLSVerificationError{Err: errors.New("server did not present a certificate")}, connectErrorTLS},
+		{&TLSVerificationError{Err: errors.New("TLS 1.3 cipher suite TLS_AES_128_GCM_SHA256 is not allowed by tls-ciphersuites")}, connectErrorTLS},
+		{fmt.Errorf("handshake: %w", &TLSVerificationError{Err: errors.New("no server name to verify the certificate against, set tls-server-name")}), connectErrorTLS},
+		{context.DeadlineExceeded, connectErrorTimeout},
+		{mysql.ErrInvalidConn, connectErrorConnection},
+		{errors.New("invalid DSN"), connectErrorOther},
+	}
+	convey.Convey("Connect error reasons", t, func() {
+		for _, tt := range tests {
+			convey.So(classifyConnectError(tt.err), convey.ShouldEqual, tt.want)
+		}
+	})
+}
+
+func TestConnectErrors(t *testing.T) {
+	now := time.Unix(0, 0)
+	scrapeErrors := NewScrapeErrors(time.Hour)
+	scrapeErrors.now = func() time.Time { return now }
+	target := scrapeTarget{target: "db:3306", authModule: "client"}
+	collect := func() map[*prometheus.Desc]MetricResult {
+		ch := make(chan prometheus.Metric)
+		go func() {
+			scrapeErrors.collect(target, ch)
+			close(ch)
+		}()
+		got := map[*prometheus.Desc]MetricResult{}
+		for m := range ch {
+			got[m.Desc()] = readMetric(m)
+		}
+		return got
+	}
+
+	convey.Convey("Connect errors", t, func() {
+		scrapeErrors.recordConnect(target, nil)
+		convey.So(collect(), convey.ShouldBeEmpty)
+
+		scrapeErrors.recordConnect(target, &mysql.MySQLError{Number: 1045, Message: "Access denied"})
+		got := collect()
+		convey.So(got[connectErrorsDesc], convey.ShouldResemble, MetricResult{labels: labelMap{"reason": connectErrorAccessDenied}, value: 1, metricType: dto.MetricType_COUNTER})
+		convey.So(got[lastConnectErrorDesc].labels, convey.ShouldResemble, labelMap{"reason": connectErrorAccessDenied, "error": "Error 1045: Access denied"})
+
+		scrapeErrors.recordConnect(target, nil)
+		got = collect()
+		convey.So(got[connectErrorsDesc].value, convey.ShouldEqual, 1)
+		convey.So(got, convey.ShouldNotContainKey, lastConnectErrorDesc)
+
+		convey.Convey("The errors of idle targets are removed", func() {
+			now = now.Add(59 * time.Minute)
+			convey.So(collect()[connectErrorsDesc].value, convey.ShouldEqual, 1)
+			now = now.Add(61 * time.Minute)
+			convey.So(collect(), convey.ShouldBeEmpty)
+			convey.So(scrapeErrors.targets, convey.ShouldBeEmpty)
+		})
+	})
+}
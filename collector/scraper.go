@@ -17,6 +17,7 @@ import (
 	"context"
 	"log/slog"
 
+	"github.com/blang/semver/v4"
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -34,5 +35,16 @@ type Scraper interface {
 	Version() float64
 
 	// Scrape collects data from database connection and sends it over channel as prometheus metric.
-	Scrape(ctx context.Context, instance *instance, ch chan<- prometheus.Metric, logger *slog.Logger) error
+	Scrape(ctx context.Context, instance *Instance, ch chan<- prometheus.Metric, logger *slog.Logger) error
+}
+
+// VersionRangesScraper is implemented by scrapers declaring the server
+// versions they support per flavor. The Version of such scrapers is ignored.
+type VersionRangesScraper interface {
+	Scraper
+
+	// VersionRanges returns the ranges of supported server versions keyed by
+	// flavor. Flavors without a range are not supported, except for Percona
+	// Server which falls back to the MySQL range.
+	VersionRanges() map[string]semver.Range
 }
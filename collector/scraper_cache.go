@@ -0,0 +1,133 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metric descriptors.
+var (
+	scraperCacheAgeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, exporter, "collector_cache_age_seconds"),
+		"Age of the metrics served by a collector with a minimum scrape interval.",
+		[]string{"collector"}, nil,
+	)
+)
+
+// ScraperCache keeps the metrics of scrapers which are expensive to run, so
+// that they are scraped at most once per their minimum interval and their
+// last metrics are served in between.
+type ScraperCache struct {
+	mu        sync.Mutex
+	intervals map[string]time.Duration
+	entries   map[scraperCacheKey]*scraperCacheEntry
+	now       func() time.Time
+}
+
+type scraperCacheKey struct {
+	dsn string
+	// options are the formatted options of the scrape, as scrapes of the
+	// same target may use different options.
+	options string
+	scraper string
+}
+
+type scraperCacheEntry struct {
+	// mu is held while the scraper runs, so that concurrent scrapes of the
+	// same target wait for its result instead of running it again.
+	mu        sync.Mutex
+	metrics   []prometheus.Metric
+	duration  time.Duration
+	timestamp time.Time
+}
+
+// NewScraperCache returns a new ScraperCache for the scrapers with the given
+// minimum intervals, keyed by scraper name.
+func NewScraperCache(intervals map[string]time.Duration) *ScraperCache {
+	return &ScraperCache{
+		intervals: intervals,
+		entries:   make(map[scraperCacheKey]*scraperCacheEntry),
+		now:       time.Now,
+	}
+}
+
+// SetIntervals replaces the minimum intervals of the scrapers, e.g. on a
+// reload of the config. The metrics of scrapers which are no longer cached
+// expire with the next lookup.
+func (c *ScraperCache) SetIntervals(intervals map[string]time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.intervals = intervals
+}
+
+// interval returns the minimum interval of the scraper, 0 if it is not cached.
+func (c *ScraperCache) interval(scraper string) time.Duration {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.intervals[scraper]
+}
+
+// entry returns the locked cache entry of the scraper for the target and
+// options. The entry must be unlocked once done with it.
+func (c *ScraperCache) entry(dsn, options, scraper string) *scraperCacheEntry {
+	key := scraperCacheKey{dsn: dsn, options: options, scraper: scraper}
+	for {
+		c.mu.Lock()
+		now := c.now()
+		// Drop the expired entries, e.g. of targets no longer scraped. Locked
+		// entries are in use and skipped.
+		for key, entry := range c.entries {
+			if entry.mu.TryLock() {
+				if now.Sub(entry.timestamp) > c.intervals[key.scraper] {
+					delete(c.entries, key)
+				}
+				entry.mu.Unlock()
+			}
+		}
+		entry, ok := c.entries[key]
+		if !ok {
+			// New entries are locked before they are inserted, so that they
+			// are not dropped before their scraper ran.
+			entry = &scraperCacheEntry{}
+			entry.mu.Lock()
+			c.entries[key] = entry
+			c.mu.Unlock()
+			return entry
+		}
+		c.mu.Unlock()
+
+		entry.mu.Lock()
+		// The entry may have been dropped while waiting for it, its metrics
+		// would not be found by the next scrape then.
+		c.mu.Lock()
+		current := c.entries[key] == entry
+		c.mu.Unlock()
+		if current {
+			return entry
+		}
+		entry.mu.Unlock()
+	}
+}
+
+// fresh returns whether the entry holds metrics younger than the interval.
+func (e *scraperCacheEntry) fresh(now time.Time, interval time.Duration) bool {
+	return !e.timestamp.IsZero() && now.Sub(e.timestamp) < interval
+}
@@ -0,0 +1,170 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/promslog"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestScraperCache(t *testing.T) {
+	var runs int
+	var scrapeErr error
+	desc := newDesc("test", "runs", "Number of runs of the scraper.")
+	scraper := &mockScraper{
+		name: "expensive",
+		validate: func(context.Context, *Instance) error {
+			runs++
+			return scrapeErr
+		},
+		emit: func(ch chan<- prometheus.Metric) {
+			ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, float64(runs))
+		},
+	}
+
+	now := time.Unix(1700000000, 0)
+	cache := NewScraperCache(map[string]time.Duration{"expensive": time.Hour})
+	cache.now = func() time.Time { return now }
+	exporter := New(context.Background(), dsn, []Scraper{scraper}, promslog.NewNopLogger(), SetScraperCache(cache))
+
+	collect := func() map[*prometheus.Desc]MetricResult {
+		ch := make(chan prometheus.Metric)
+		go func() {
+			exporter.runScraper(context.Background(), scraper, &Instance{}, ch)
+			close(ch)
+		}()
+		got := map[*prometheus.Desc]MetricResult{}
+		for m := range ch {
+			got[m.Desc()] = readMetric(m)
+		}
+		return got
+	}
+
+	convey.Convey("Scraper with a minimum interval", t, func() {
+		got := collect()
+		convey.So(runs, convey.ShouldEqual, 1)
+		convey.So(got[desc].value, convey.ShouldEqual, 1)
+		convey.So(got[scraperCacheAgeDesc].value, convey.ShouldEqual, 0)
+
+		now = now.Add(30 * time.Minute)
+		got = collect()
+		convey.So(runs, convey.ShouldEqual, 1)
+		convey.So(got[desc].value, convey.ShouldEqual, 1)
+		convey.So(got[mysqlScrapeCollectorSuccess].value, convey.ShouldEqual, 1)
+		convey.So(got[scraperCacheAgeDesc].value, convey.ShouldEqual, 30*60)
+
+		now = now.Add(time.Hour)
+		got = collect()
+		convey.So(runs, convey.ShouldEqual, 2)
+		convey.So(got[desc].value, convey.ShouldEqual, 2)
+		convey.So(got[scraperCacheAgeDesc].value, convey.ShouldEqual, 0)
+
+		convey.Convey("Failed scrapes are not cached", func() {
+			now = now.Add(2 * time.Hour)
+			scrapeErr = errors.New("failed")
+			got = collect()
+			convey.So(got[mysqlScrapeCollectorSuccess].value, convey.ShouldEqual, 0)
+			convey.So(got, convey.ShouldNotContainKey, scraperCacheAgeDesc)
+
+			scrapeErr = nil
+			collect()
+			convey.So(runs, convey.ShouldEqual, 4)
+		})
+	})
+
+	convey.Convey("Intervals replaced on a reload", t, func() {
+		runs = 0
+		cache.SetIntervals(map[string]time.Duration{})
+		collect()
+		collect()
+		convey.So(runs, convey.ShouldEqual, 2)
+
+		cache.SetIntervals(map[string]time.Duration{"expensive": time.Hour})
+		now = now.Add(24 * time.Hour)
+		collect()
+		collect()
+		convey.So(runs, convey.ShouldEqual, 3)
+	})
+}
+
+func TestScraperCacheOptions(t *testing.T) {
+	var runs int
+	scraper := &mockScraper{
+		name: "expensive",
+		validate: func(context.Context, *Instance) error {
+			runs++
+			return nil
+		},
+	}
+	cache := NewScraperCache(map[string]time.Duration{"expensive": time.Hour})
+	options := DefaultOptions()
+	options.PerfEventsStatementsLimit = 50
+
+	for _, opts := range [][]ExporterOpt{
+		{SetScraperCache(cache)},
+		{SetScraperCache(cache)},
+		{SetScraperCache(cache), SetOptions(options)},
+	} {
+		exporter := New(context.Background(), dsn, []Scraper{scraper}, promslog.NewNopLogger(), opts...)
+		ch := make(chan prometheus.Metric, 10)
+		exporter.runScraper(context.Background(), scraper, &Instance{}, ch)
+	}
+	if runs != 2 {
+		t.Fatalf("expected the scraper to run once per options, got %d runs", runs)
+	}
+}
+
+func TestScraperCacheSweptEntry(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	cache := NewScraperCache(map[string]time.Duration{"expensive": time.Hour})
+	cache.now = func() time.Time { return now }
+	key := scraperCacheKey{dsn: dsn, scraper: "expensive"}
+
+	convey.Convey("Entries swept while waiting for them are not returned", t, func() {
+		stale := cache.entry(dsn, "", "expensive")
+		got := make(chan *scraperCacheEntry)
+		go func() {
+			got <- cache.entry(dsn, "", "expensive")
+		}()
+		// Wait for the lookup to block on the entry, then sweep it.
+		time.Sleep(50 * time.Millisecond)
+		cache.mu.Lock()
+		delete(cache.entries, key)
+		cache.mu.Unlock()
+		stale.mu.Unlock()
+
+		entry := <-got
+		defer entry.mu.Unlock()
+		convey.So(entry != stale, convey.ShouldBeTrue)
+		cache.mu.Lock()
+		defer cache.mu.Unlock()
+		convey.So(cache.entries[key] == entry, convey.ShouldBeTrue)
+	})
+
+	convey.Convey("New entries are not swept before their scraper ran", t, func() {
+		entry := cache.entry(dsn, "", "expensive")
+		defer entry.mu.Unlock()
+		// Other lookups sweep the entries.
+		cache.entry(dsn, "", "other").mu.Unlock()
+		cache.mu.Lock()
+		defer cache.mu.Unlock()
+		convey.So(cache.entries[key] == entry, convey.ShouldBeTrue)
+	})
+}
@@ -20,6 +20,7 @@ import (
 	"database/sql"
 	"log/slog"
 
+	"github.com/blang/semver/v4"
 	"github.com/go-sql-driver/mysql"
 	"github.com/google/uuid"
 	"github.com/prometheus/client_golang/prometheus"
@@ -63,13 +64,21 @@ func (ScrapeSlaveHosts) Version() float64 {
 	return 5.1
 }
 
+// VersionRanges of the servers from which scraper is available.
+func (ScrapeSlaveHosts) VersionRanges() map[string]semver.Range {
+	return map[string]semver.Range{
+		FlavorMySQL:   semver.MustParseRange(">=5.1.0"),
+		FlavorMariaDB: semver.MustParseRange(">=5.1.0"),
+	}
+}
+
 // Scrape collects data from database connection and sends it over channel as prometheus metric.
-func (ScrapeSlaveHosts) Scrape(ctx context.Context, instance *instance, ch chan<- prometheus.Metric, logger *slog.Logger) error {
+func (ScrapeSlaveHosts) Scrape(ctx context.Context, instance *Instance, ch chan<- prometheus.Metric, logger *slog.Logger) error {
 	var (
 		slaveHostsRows *sql.Rows
 		err            error
 	)
-	db := instance.getDB()
+	db := instance.DB()
 	// Try SHOW SLAVE HOSTS first (MySQL < 8.4 / MariaDB). On MySQL 8.4+ that
 	// statement was removed, so fall back to SHOW REPLICAS only when the server
 	// reports a syntax/parse error (MySQL error 1064 / ER_PARSE_ERROR:
@@ -154,4 +163,4 @@ func (ScrapeSlaveHosts) Scrape(ctx context.Context, instance *instance, ch chan<
 }
 
 // check interface
-var _ Scraper = ScrapeSlaveHosts{}
+var _ VersionRangesScraper = ScrapeSlaveHosts{}
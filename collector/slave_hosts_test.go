@@ -32,7 +32,7 @@ func TestScrapeSlaveHostsOldFormat(t *testing.T) {
 		t.Fatalf("error opening a stub database connection: %s", err)
 	}
 	defer db.Close()
-	inst := &instance{db: db}
+	inst := &Instance{db: db}
 
 	columns := []string{"Server_id", "Host", "Port", "Rpl_recovery_rank", "Master_id"}
 	rows := sqlmock.NewRows(columns).
@@ -71,7 +71,7 @@ func TestScrapeSlaveHostsNewFormat(t *testing.T) {
 		t.Fatalf("error opening a stub database connection: %s", err)
 	}
 	defer db.Close()
-	inst := &instance{db: db}
+	inst := &Instance{db: db}
 
 	columns := []string{"Server_id", "Host", "Port", "Master_id", "Slave_UUID"}
 	rows := sqlmock.NewRows(columns).
@@ -110,7 +110,7 @@ func TestScrapeSlaveHostsWithoutSlaveUuid(t *testing.T) {
 		t.Fatalf("error opening a stub database connection: %s", err)
 	}
 	defer db.Close()
-	inst := &instance{db: db}
+	inst := &Instance{db: db}
 
 	columns := []string{"Server_id", "Host", "Port", "Master_id"}
 	rows := sqlmock.NewRows(columns).
@@ -149,7 +149,7 @@ func TestScrapeSlaveHostsPermissionErrorNotMasked(t *testing.T) {
 		t.Fatalf("error opening a stub database connection: %s", err)
 	}
 	defer db.Close()
-	inst := &instance{db: db}
+	inst := &Instance{db: db}
 
 	// Permission denied on SHOW SLAVE HOSTS must be returned as-is.
 	// Falling back to SHOW REPLICAS would produce a misleading syntax error
@@ -186,7 +186,7 @@ func TestScrapeSlaveHostsFallbackOnSyntaxError(t *testing.T) {
 		t.Fatalf("error opening a stub database connection: %s", err)
 	}
 	defer db.Close()
-	inst := &instance{db: db}
+	inst := &Instance{db: db}
 
 	// SHOW SLAVE HOSTS is gone on MySQL 8.4+; fall back to SHOW REPLICAS.
 	syntaxErr := &mysql.MySQLError{
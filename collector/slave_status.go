@@ -23,6 +23,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/blang/semver/v4"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -69,13 +70,21 @@ func (ScrapeSlaveStatus) Version() float64 {
 	return 5.1
 }
 
+// VersionRanges of the servers from which scraper is available.
+func (ScrapeSlaveStatus) VersionRanges() map[string]semver.Range {
+	return map[string]semver.Range{
+		FlavorMySQL:   semver.MustParseRange(">=5.1.0"),
+		FlavorMariaDB: semver.MustParseRange(">=5.1.0"),
+	}
+}
+
 // Scrape collects data from database connection and sends it over channel as prometheus metric.
-func (ScrapeSlaveStatus) Scrape(ctx context.Context, instance *instance, ch chan<- prometheus.Metric, logger *slog.Logger) error {
+func (ScrapeSlaveStatus) Scrape(ctx context.Context, instance *Instance, ch chan<- prometheus.Metric, logger *slog.Logger) error {
 	var (
 		slaveStatusRows *sql.Rows
 		err             error
 	)
-	db := instance.getDB()
+	db := instance.DB()
 	// Try the both syntax for MySQL/Percona and MariaDB
 	for _, query := range slaveStatusQueries {
 		slaveStatusRows, err = db.QueryContext(ctx, query)
@@ -181,4 +190,4 @@ func parseMariaDBGtid(ch chan<- prometheus.Metric, name string, value string, ma
 }
 
 // check interface
-var _ Scraper = ScrapeSlaveStatus{}
+var _ VersionRangesScraper = ScrapeSlaveStatus{}
@@ -17,6 +17,7 @@ import (
 	"context"
 	"log/slog"
 
+	"github.com/blang/semver/v4"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -97,10 +98,24 @@ func (ScrapeSysUserSummary) Version() float64 {
 	return 5.7
 }
 
+// VersionRanges of the servers from which scraper is available.
+// MariaDB ships the sys schema since 10.6.
+func (ScrapeSysUserSummary) VersionRanges() map[string]semver.Range {
+	return map[string]semver.Range{
+		FlavorMySQL:   semver.MustParseRange(">=5.7.0"),
+		FlavorMariaDB: semver.MustParseRange(">=10.6.0"),
+	}
+}
+
+// Requirements of the server for the scraper.
+func (ScrapeSysUserSummary) Requirements() Requirements {
+	return Requirements{Tables: []string{"sys.x$user_summary"}}
+}
+
 // Scrape the information from sys.user_summary, creating a metric for each value of each row, labeled with the user
-func (ScrapeSysUserSummary) Scrape(ctx context.Context, instance *instance, ch chan<- prometheus.Metric, logger *slog.Logger) error {
+func (ScrapeSysUserSummary) Scrape(ctx context.Context, instance *Instance, ch chan<- prometheus.Metric, logger *slog.Logger) error {
 
-	db := instance.getDB()
+	db := instance.DB()
 
 	userSummaryRows, err := db.QueryContext(ctx, sysUserSummaryQuery)
 	if err != nil {
@@ -155,4 +170,5 @@ func (ScrapeSysUserSummary) Scrape(ctx context.Context, instance *instance, ch c
 	return nil
 }
 
-var _ Scraper = ScrapeSysUserSummary{}
+var _ VersionRangesScraper = ScrapeSysUserSummary{}
+var _ RequirementsScraper = ScrapeSysUserSummary{}
@@ -0,0 +1,265 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Trace writes the steps of a scrape in plain text, e.g. for debugging a
+// target. It is safe for concurrent use.
+type Trace struct {
+	mu    sync.Mutex
+	w     io.Writer
+	start time.Time
+}
+
+// NewTrace returns a trace writing to w.
+func NewTrace(w io.Writer) *Trace {
+	return &Trace{w: w, start: time.Now()}
+}
+
+// Printf writes a step, prefixed with the time since the start of the trace.
+// It does nothing on a nil trace.
+func (t *Trace) Printf(format string, args ...any) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	fmt.Fprintf(t.w, "%12s  %s\n", time.Since(t.start).Round(time.Microsecond), fmt.Sprintf(format, args...))
+}
+
+// traceScraperKey is the context key of the name of the scraper running a
+// query.
+type traceScraperKey struct{}
+
+// printfContext writes a step, prefixed with the scraper of ctx if any.
+func (t *Trace) printfContext(ctx context.Context, format string, args ...any) {
+	if scraper, ok := ctx.Value(traceScraperKey{}).(string); ok {
+		format = "[" + scraper + "] " + format
+	}
+	t.Printf(format, args...)
+}
+
+// newTracedInstance connects to the MySQL server of the DSN like
+// NewInstance, tracing the connections and queries of the instance.
+func newTracedInstance(ctx context.Context, dsn string, maxOpenConns int, trace *Trace) (*Instance, error) {
+	connector, err := newConnector(ctx, dsn)
+	if err != nil {
+		return nil, err
+	}
+	db := sql.OpenDB(traceConnector{Connector: connector, trace: trace})
+	db.SetMaxOpenConns(maxOpenConns)
+	db.SetMaxIdleConns(maxOpenConns)
+	start := time.Now()
+	instance, err := newInstance(ctx, db)
+	if err != nil {
+		trace.Printf("Version detection failed after %s: %s", time.Since(start), err)
+		return nil, err
+	}
+	trace.Printf("Detected %s %s in %s", instance.flavor, instance.version, time.Since(start))
+	return instance, nil
+}
+
+// traceConnector traces the connections it opens.
+type traceConnector struct {
+	driver.Connector
+	trace *Trace
+}
+
+func (c traceConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	start := time.Now()
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		c.trace.Printf("Opening connection failed after %s: %s", time.Since(start), err)
+		return nil, err
+	}
+	c.trace.Printf("Opened connection in %s", time.Since(start))
+	return &traceConn{Conn: conn, trace: c.trace}, nil
+}
+
+// traceConn traces the queries of a connection, passing the optional
+// interfaces of the driver on.
+type traceConn struct {
+	driver.Conn
+	trace *Trace
+}
+
+func (c *traceConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	rows, err := queryer.QueryContext(ctx, query, args)
+	if errors.Is(err, driver.ErrSkip) {
+		// The query is run as prepared statement.
+		return nil, err
+	}
+	return c.traceRows(ctx, query, start, rows, err)
+}
+
+func (c *traceConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	start := time.Now()
+	result, err := execer.ExecContext(ctx, query, args)
+	if !errors.Is(err, driver.ErrSkip) {
+		c.traceResult(ctx, query, start, err)
+	}
+	return result, err
+}
+
+func (c *traceConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var stmt driver.Stmt
+	var err error
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		stmt, err = preparer.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.Prepare(query)
+	}
+	if err != nil {
+		c.trace.printfContext(ctx, "Preparing %s failed: %s", traceQuery(query), err)
+		return nil, err
+	}
+	return &traceStmt{Stmt: stmt, conn: c, query: query}, nil
+}
+
+func (c *traceConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+	return c.Begin() //nolint:staticcheck
+}
+
+func (c *traceConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *traceConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *traceConn) IsValid() bool {
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+func (c *traceConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// traceRows traces the query, and the number of its rows once they are
+// closed.
+func (c *traceConn) traceRows(ctx context.Context, query string, start time.Time, rows driver.Rows, err error) (driver.Rows, error) {
+	if err != nil {
+		c.trace.printfContext(ctx, "Query %s failed after %s: %s", traceQuery(query), time.Since(start), err)
+		return nil, err
+	}
+	c.trace.printfContext(ctx, "Query %s", traceQuery(query))
+	return &traceRowsCounter{Rows: rows, ctx: ctx, trace: c.trace, start: start}, nil
+}
+
+func (c *traceConn) traceResult(ctx context.Context, query string, start time.Time, err error) {
+	if err != nil {
+		c.trace.printfContext(ctx, "Statement %s failed after %s: %s", traceQuery(query), time.Since(start), err)
+		return
+	}
+	c.trace.printfContext(ctx, "Statement %s done in %s", traceQuery(query), time.Since(start))
+}
+
+// traceStmt traces the executions of a prepared statement.
+type traceStmt struct {
+	driver.Stmt
+	conn  *traceConn
+	query string
+}
+
+func (s *traceStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := s.Stmt.(driver.StmtQueryContext)
+	if !ok {
+		return nil, errors.New("prepared statement does not support contexts")
+	}
+	start := time.Now()
+	rows, err := queryer.QueryContext(ctx, args)
+	return s.conn.traceRows(ctx, s.query, start, rows, err)
+}
+
+func (s *traceStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := s.Stmt.(driver.StmtExecContext)
+	if !ok {
+		return nil, errors.New("prepared statement does not support contexts")
+	}
+	start := time.Now()
+	result, err := execer.ExecContext(ctx, args)
+	s.conn.traceResult(ctx, s.query, start, err)
+	return result, err
+}
+
+// traceRowsCounter counts the rows of a query.
+type traceRowsCounter struct {
+	driver.Rows
+	ctx   context.Context
+	trace *Trace
+	start time.Time
+	rows  int
+	err   error
+}
+
+func (r *traceRowsCounter) Next(dest []driver.Value) error {
+	err := r.Rows.Next(dest)
+	switch {
+	case err == nil:
+		r.rows++
+	case !errors.Is(err, io.EOF):
+		r.err = err
+	}
+	return err
+}
+
+func (r *traceRowsCounter) Close() error {
+	if r.err != nil {
+		r.trace.printfContext(r.ctx, "Reading rows failed after %d rows in %s: %s", r.rows, time.Since(r.start), r.err)
+	} else {
+		r.trace.printfContext(r.ctx, "Read %d rows in %s", r.rows, time.Since(r.start))
+	}
+	return r.Rows.Close()
+}
+
+// traceQuery returns the query on a single line.
+func traceQuery(query string) string {
+	return strings.Join(strings.Fields(query), " ")
+}
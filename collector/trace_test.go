@@ -0,0 +1,78 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package collector
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/promslog"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+// dsnConnector opens connections of a driver by DSN.
+type dsnConnector struct {
+	driver driver.Driver
+	dsn    string
+}
+
+func (c dsnConnector) Connect(context.Context) (driver.Conn, error) { return c.driver.Open(c.dsn) }
+func (c dsnConnector) Driver() driver.Driver                        { return c.driver }
+
+func TestTrace(t *testing.T) {
+	convey.Convey("Queries of scrapers are traced", t, func() {
+		mockDB, mock, err := sqlmock.NewWithDSN("trace_test")
+		convey.So(err, convey.ShouldBeNil)
+		defer mockDB.Close()
+
+		var out bytes.Buffer
+		trace := NewTrace(&out)
+		db := sql.OpenDB(traceConnector{Connector: dsnConnector{driver: mockDB.Driver(), dsn: "trace_test"}, trace: trace})
+		defer db.Close()
+
+		mock.ExpectQuery(sanitizeQuery(globalStatusQuery)).WillReturnRows(
+			sqlmock.NewRows([]string{"Variable_name", "Value"}).AddRow("Uptime", "10").AddRow("Threads_connected", "2"),
+		)
+		mock.ExpectQuery(sanitizeQuery(globalVariablesQuery)).WillReturnError(errors.New("access denied"))
+
+		e := New(context.Background(), "", nil, promslog.NewNopLogger(), SetTrace(trace))
+		ch := make(chan prometheus.Metric, 100)
+		instance := &Instance{db: db}
+		success, _ := e.scrapeOnce(context.Background(), ScrapeGlobalStatus{}, instance, ch)
+		convey.So(success, convey.ShouldEqual, 1)
+		success, _ = e.scrapeOnce(context.Background(), ScrapeGlobalVariables{}, instance, ch)
+		convey.So(success, convey.ShouldEqual, 0)
+		convey.So(mock.ExpectationsWereMet(), convey.ShouldBeNil)
+
+		got := out.String()
+		convey.So(got, convey.ShouldContainSubstring, "Opened connection in ")
+		convey.So(got, convey.ShouldContainSubstring, "[global_status] Started")
+		convey.So(got, convey.ShouldContainSubstring, "[global_status] Query SHOW GLOBAL STATUS")
+		convey.So(got, convey.ShouldContainSubstring, "[global_status] Read 2 rows in ")
+		convey.So(got, convey.ShouldContainSubstring, "[global_status] Done in ")
+		convey.So(got, convey.ShouldContainSubstring, "[global_variables] Query SHOW GLOBAL VARIABLES failed after ")
+		convey.So(got, convey.ShouldContainSubstring, "[global_variables] Failed after ")
+	})
+
+	convey.Convey("Nil traces are ignored", t, func() {
+		var trace *Trace
+		trace.Printf("ignored %d", 1)
+	})
+}
@@ -14,8 +14,8 @@
 package config
 
 import (
+	"context"
 	"crypto/tls"
-	"crypto/x509"
 	"fmt"
 	"log/slog"
 	"maps"
@@ -67,6 +67,10 @@ var (
 	}
 )
 
+func init() {
+	prometheus.MustRegister(configReloadSuccess, configReloadSeconds, tlsClientCertNotAfter)
+}
+
 type Config struct {
 	Sections map[string]MySqlConfig
 }
@@ -74,6 +78,10 @@ type Config struct {
 type MySqlConfig struct {
 	User                  string `ini:"user"`
 	Password              string `ini:"password"`
+	PasswordFile          string `ini:"password-file"`
+	PasswordCommand       string `ini:"password-command"`
+	PasswordCommandTTL    string `ini:"password-command-ttl"`
+	SecondaryPassword     string `ini:"secondary-password"`
 	Host                  string `ini:"host"`
 	Port                  int    `ini:"port"`
 	Socket                string `ini:"socket"`
@@ -86,12 +94,22 @@ type MySqlConfig struct {
 	TlsMinVersion         string `ini:"tls-min-version"`
 	TlsMaxVersion         string `ini:"tls-max-version"`
 	TlsServerName         string `ini:"tls-server-name"`
+	SslMode               string `ini:"ssl-mode"`
+	SslCrl                string `ini:"ssl-crl"`
+	SslCipher             string `ini:"ssl-cipher"`
+	TlsCiphersuites       string `ini:"tls-ciphersuites"`
+	MatchTarget           string `ini:"match-target"`
+	AllowTarget           string `ini:"allow-target"`
 }
 
 type MySqlConfigHandler struct {
 	sync.RWMutex
 	TlsInsecureSkipVerify bool
-	Config                *Config
+	// LoginPathFile is the path of the .mylogin.cnf written by
+	// mysql_config_editor, whose login paths are merged into the config if
+	// set.
+	LoginPathFile string
+	Config        *Config
 }
 
 func (ch *MySqlConfigHandler) GetConfig() *Config {
@@ -100,24 +118,76 @@ func (ch *MySqlConfigHandler) GetConfig() *Config {
 	return ch.Config
 }
 
+// SetConfig replaces the configuration.
+func (ch *MySqlConfigHandler) SetConfig(config *Config) {
+	ch.Lock()
+	ch.Config = config
+	ch.Unlock()
+}
+
+// ReloadConfig loads the configuration file and applies it, keeping the
+// current configuration if it is invalid. The result is reported in the
+// config reload metrics.
 func (ch *MySqlConfigHandler) ReloadConfig(filename string, mysqldAddress string, mysqldUser string, tlsInsecureSkipVerify bool, logger *slog.Logger) error {
-	var host, port string
-	defer func() {
-		if err != nil {
-			configReloadSuccess.Set(0)
-		} else {
-			configReloadSuccess.Set(1)
-			configReloadSeconds.SetToCurrentTime()
-		}
-	}()
+	config, err := ch.LoadConfig(filename, mysqldAddress, mysqldUser, tlsInsecureSkipVerify, logger)
+	reportReload(err)
+	if err != nil {
+		return err
+	}
+	ch.SetConfig(config)
+	return nil
+}
+
+// ReloadConfigs loads the exporter configuration file and the MySQL
+// configuration file and only applies them if both are valid, so that a
+// reload is never applied partially. The result is reported in the config
+// reload metrics.
+func ReloadConfigs(exporterHandler *ExporterConfigHandler, exporterFile string, collectors []string, mysqlHandler *MySqlConfigHandler, mysqlFile, mysqldAddress, mysqldUser string, tlsInsecureSkipVerify bool, logger *slog.Logger) error {
+	exporterConfig, err := exporterHandler.LoadConfig(exporterFile, collectors)
+	if err != nil {
+		reportReload(err)
+		return err
+	}
+	config, err := mysqlHandler.LoadConfig(mysqlFile, mysqldAddress, mysqldUser, tlsInsecureSkipVerify, logger)
+	reportReload(err)
+	if err != nil {
+		return err
+	}
+	exporterHandler.SetConfig(exporterConfig)
+	mysqlHandler.SetConfig(config)
+	return nil
+}
+
+// reportReload reports the result of a reload in the config reload metrics.
+func reportReload(err error) {
+	if err != nil {
+		configReloadSuccess.Set(0)
+		return
+	}
+	configReloadSuccess.Set(1)
+	configReloadSeconds.SetToCurrentTime()
+}
 
+// LoadConfig loads and validates the configuration file without applying
+// it.
+func (ch *MySqlConfigHandler) LoadConfig(filename string, mysqldAddress string, mysqldUser string, tlsInsecureSkipVerify bool, logger *slog.Logger) (*Config, error) {
+	var host, port string
+	files, err := loadOptionFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config from %s: %w", filename, err)
+	}
 	cfg, err := ini.LoadSources(
 		opts,
 		[]byte("[client]\npassword = ${MYSQLD_EXPORTER_PASSWORD}\n"),
-		filename,
+		files.sources...,
 	)
 	if err != nil {
-		return fmt.Errorf("failed to load config from %s: %w", filename, err)
+		return nil, fmt.Errorf("failed to load config from %s: %w", filename, err)
+	}
+	if ch.LoginPathFile != "" {
+		if err = mergeLoginPaths(cfg, ch.LoginPathFile); err != nil {
+			return nil, err
+		}
 	}
 
 	if clientSection := cfg.Section("client"); clientSection != nil {
@@ -130,7 +200,7 @@ func (ch *MySqlConfigHandler) ReloadConfig(filename string, mysqldAddress string
 		} else {
 			// Parse as TCP address (host:port)
 			if host, port, err = net.SplitHostPort(mysqldAddress); err != nil {
-				return fmt.Errorf("failed to parse address: %w", err)
+				return nil, fmt.Errorf("failed to parse address: %w", err)
 			}
 			if cfgHost := clientSection.Key("host"); cfgHost.String() == "" {
 				cfgHost.SetValue(host)
@@ -144,7 +214,7 @@ func (ch *MySqlConfigHandler) ReloadConfig(filename string, mysqldAddress string
 		}
 	}
 
-	cfg.ValueMapper = os.ExpandEnv
+	cfg.ValueMapper = mapLoginPathValue(os.ExpandEnv)
 	config := &Config{}
 	m := make(map[string]MySqlConfig)
 	for _, sec := range cfg.Sections() {
@@ -160,11 +230,16 @@ func (ch *MySqlConfigHandler) ReloadConfig(filename string, mysqldAddress string
 
 		err = sec.StrictMapTo(mysqlcfg)
 		if err != nil {
-			logger.Error("failed to parse config", "section", sectionName, "err", err)
+			logger.Error("failed to parse config", "section", sectionName, "location", files.locate(sectionName, ""), "err", err)
 			continue
 		}
-		if err := mysqlcfg.validateConfig(); err != nil {
-			logger.Error("failed to validate config", "section", sectionName, "err", err)
+		// Sections do not inherit the targets of their parent section, as
+		// the targets would be matched by both.
+		if !slices.Contains(sec.KeyStrings(), "match-target") {
+			mysqlcfg.MatchTarget = ""
+		}
+		if option, err := mysqlcfg.validateOptions(); err != nil {
+			logger.Error("failed to validate config", "section", sectionName, "location", files.locate(sectionName, option), "err", err)
 			continue
 		}
 
@@ -172,39 +247,100 @@ func (ch *MySqlConfigHandler) ReloadConfig(filename string, mysqldAddress string
 	}
 	config.Sections = m
 	if len(config.Sections) == 0 {
-		return fmt.Errorf("no configuration found")
+		return nil, fmt.Errorf("no configuration found")
 	}
-	ch.Lock()
-	ch.Config = config
-	ch.Unlock()
-	return nil
+	return config, nil
+}
+
+// ChangedSections returns the sorted names of the sections of c which were
+// modified or removed in newConfig.
+func (c *Config) ChangedSections(newConfig *Config) []string {
+	var changed []string
+	for name, section := range c.Sections {
+		if newSection, ok := newConfig.Sections[name]; !ok || newSection != section {
+			changed = append(changed, name)
+		}
+	}
+	slices.Sort(changed)
+	return changed
 }
 
 func (m MySqlConfig) validateConfig() error {
-	if m.User == "" {
-		return fmt.Errorf("no user specified in section or parent")
+	_, err := m.validateOptions()
+	return err
+}
+
+// validateOptions validates the section, returning the name of the invalid
+// option along with the error, so that it can be reported where the option
+// was defined.
+func (m MySqlConfig) validateOptions() (string, error) {
+	// A password-command may return the user.
+	if m.User == "" && m.PasswordCommand == "" {
+		return "user", fmt.Errorf("no user specified in section or parent")
+	}
+	if m.PasswordFile != "" && m.PasswordCommand != "" {
+		return "password-command", fmt.Errorf("password-file and password-command are mutually exclusive")
+	}
+	if _, err := m.passwordCommandTTL(); err != nil {
+		return "password-command-ttl", err
 	}
 
 	allowedTLSVersions := strings.Join(slices.Sorted(maps.Keys(tlsVersions)), ", ")
 	if _, ok := tlsVersions[m.TlsMinVersion]; !ok && m.TlsMinVersion != "" {
-		return fmt.Errorf("tls-min-version=%s is not allowed, use one of: %s", m.TlsMinVersion, allowedTLSVersions)
+		return "tls-min-version", fmt.Errorf("tls-min-version=%s is not allowed, use one of: %s", m.TlsMinVersion, allowedTLSVersions)
 	}
 	if _, ok := tlsVersions[m.TlsMaxVersion]; !ok && m.TlsMaxVersion != "" {
-		return fmt.Errorf("tls-max-version=%s is not allowed, use one of: %s", m.TlsMaxVersion, allowedTLSVersions)
+		return "tls-max-version", fmt.Errorf("tls-max-version=%s is not allowed, use one of: %s", m.TlsMaxVersion, allowedTLSVersions)
 	}
 	if m.TlsMinVersion != "" && m.TlsMaxVersion != "" {
 		if tlsVersions[m.TlsMinVersion] > tlsVersions[m.TlsMaxVersion] {
-			return fmt.Errorf("tls-min-version must not be higher than tls-max-version: %s > %s", m.TlsMinVersion, m.TlsMaxVersion)
+			return "tls-min-version", fmt.Errorf("tls-min-version must not be higher than tls-max-version: %s > %s", m.TlsMinVersion, m.TlsMaxVersion)
 		}
 	}
 
-	return nil
+	if m.SslMode != "" {
+		if !slices.Contains(sslModes, strings.ToUpper(m.SslMode)) {
+			return "ssl-mode", fmt.Errorf("ssl-mode=%s is not allowed, use one of: %s", m.SslMode, strings.Join(sslModes, ", "))
+		}
+		if m.Tls != "" {
+			return "ssl-mode", fmt.Errorf("ssl-mode and tls are mutually exclusive")
+		}
+	}
+	mode := m.sslMode()
+	if (mode == sslModeVerifyCA || mode == sslModeVerifyIdentity) && m.SslMode != "" && m.SslCa == "" {
+		return "ssl-mode", fmt.Errorf("ssl-mode=%s requires ssl-ca", m.SslMode)
+	}
+	if m.SslCrl != "" && mode != sslModeVerifyCA && mode != sslModeVerifyIdentity {
+		return "ssl-crl", fmt.Errorf("ssl-crl requires ssl-mode VERIFY_CA or VERIFY_IDENTITY")
+	}
+	if m.SslCipher != "" {
+		if _, err := parseCipherList(m.SslCipher); err != nil {
+			return "ssl-cipher", err
+		}
+	}
+	if m.TlsCiphersuites != "" {
+		if _, err := parseTLS13CipherSuites(m.TlsCiphersuites); err != nil {
+			return "tls-ciphersuites", err
+		}
+	}
+	if _, err := parseTargetRules(m.MatchTarget); err != nil {
+		return "match-target", err
+	}
+	if _, err := parseTargetRules(m.AllowTarget); err != nil {
+		return "allow-target", err
+	}
+
+	return "", nil
 }
 
 func (m MySqlConfig) FormDSN(target string, configSectionName string) (string, error) {
+	return m.FormDSNContext(context.Background(), target, configSectionName)
+}
+
+// FormDSNContext returns the DSN like FormDSN. A password-command run for
+// the credentials is killed once ctx is done.
+func (m MySqlConfig) FormDSNContext(ctx context.Context, target string, configSectionName string) (string, error) {
 	config := mysql.NewConfig()
-	config.User = m.User
-	config.Passwd = m.Password
 	config.Net = "tcp"
 	if target == "" {
 		if m.Socket == "" {
@@ -225,21 +361,42 @@ func (m MySqlConfig) FormDSN(target string, configSectionName string) (string, e
 		config.Net = "unix"
 		config.Addr = target[len(prefix):]
 	} else {
-		if _, _, err = net.SplitHostPort(target); err != nil {
+		if _, _, err := net.SplitHostPort(target); err != nil {
 			return "", fmt.Errorf("failed to parse target: %s", err)
 		}
 		config.Addr = target
 	}
 
-	if m.TlsInsecureSkipVerify {
+	user, password, err := m.resolveCredentials(ctx, configSectionName, config.Addr)
+	if err != nil {
+		return "", err
+	}
+	config.User = user
+	config.Passwd = password
+
+	switch mode := strings.ToUpper(m.SslMode); {
+	case mode == sslModeDisabled:
+		config.TLSConfig = "false"
+	case mode != "":
+		// The ssl-mode takes precedence over disabling TLS verification.
+		if err := m.CustomizeTLS(configSectionName); err != nil {
+			return "", fmt.Errorf("failed to register a custom TLS configuration for mysql dsn: %w", err)
+		}
+		config.TLSConfig = configSectionName
+		config.AllowFallbackToPlaintext = mode == sslModePreferred
+	case m.TlsInsecureSkipVerify:
 		config.TLSConfig = "skip-verify"
-	} else {
+	default:
 		config.TLSConfig = m.Tls
 
 		hasCustomTLS := m.SslCa != "" ||
 			m.TlsMinVersion != "" ||
 			m.TlsMaxVersion != "" ||
-			m.TlsServerName != ""
+			m.TlsServerName != "" ||
+			m.SslCrl != "" ||
+			m.SslCipher != "" ||
+			m.TlsCiphersuites != "" ||
+			(m.SslCert != "" && m.SslKey != "" && m.Tls == "true")
 
 		if hasCustomTLS {
 			if err := m.CustomizeTLS(configSectionName); err != nil {
@@ -257,40 +414,79 @@ func (m MySqlConfig) FormDSN(target string, configSectionName string) (string, e
 	return config.FormatDSN(), nil
 }
 
+// FormSecondaryDSN returns the DSN like FormDSN, but with the secondary
+// password instead of the password. It returns an empty DSN if the section
+// has no secondary password.
+func (m MySqlConfig) FormSecondaryDSN(target string, configSectionName string) (string, error) {
+	if m.SecondaryPassword == "" {
+		return "", nil
+	}
+	m.Password = m.SecondaryPassword
+	m.PasswordFile = ""
+	m.PasswordCommand = ""
+	return m.FormDSN(target, configSectionName)
+}
+
+// CustomizeTLS registers the TLS configuration of the section under its
+// name. The configuration is shared by all targets of the section, the
+// server name verified for VERIFY_IDENTITY is the one of each connection.
 func (m MySqlConfig) CustomizeTLS(configSectionName string) error {
+	tlsCfg, err := m.tlsConfig(configSectionName)
+	if err != nil {
+		return err
+	}
+	return mysql.RegisterTLSConfig(configSectionName, tlsCfg)
+}
+
+// tlsConfig returns the TLS configuration of the auth module, verifying the
+// server according to its ssl-mode. The CA certificates, client certificate
+// and CRLs are loaded once here to report errors early, and again by the
+// connections once their files changed. Unless tls-server-name is set, the
+// server name verified for VERIFY_IDENTITY is the one of the connection,
+// which is the host of the target.
+func (m MySqlConfig) tlsConfig(authModule string) (*tls.Config, error) {
 	var tlsCfg tls.Config
-	if m.SslCa != "" {
-		caBundle := x509.NewCertPool()
-		pemCA, err := os.ReadFile(m.SslCa)
-		if err != nil {
-			return err
-		}
-		if ok := caBundle.AppendCertsFromPEM(pemCA); ok {
-			tlsCfg.RootCAs = caBundle
-		} else {
-			return fmt.Errorf("failed parse pem-encoded CA certificates from %s", m.SslCa)
+	if _, err := m.rootCAs(); err != nil {
+		return nil, err
+	}
+	if m.SslCert != "" && m.SslKey != "" {
+		if _, err := m.clientCertificate(authModule); err != nil {
+			return nil, err
 		}
-		if m.SslCert != "" && m.SslKey != "" {
-			certPairs := make([]tls.Certificate, 0, 1)
-			keypair, err := tls.LoadX509KeyPair(m.SslCert, m.SslKey)
-			if err != nil {
-				return fmt.Errorf("failed to parse pem-encoded SSL cert %s or SSL key %s: %w",
-					m.SslCert, m.SslKey, err)
-			}
-			certPairs = append(certPairs, keypair)
-			tlsCfg.Certificates = certPairs
+		tlsCfg.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return m.clientCertificate(authModule)
 		}
 	}
+	if _, err := m.revocationLists(); err != nil {
+		return nil, err
+	}
 	if m.TlsMinVersion != "" {
 		tlsCfg.MinVersion = tlsVersions[m.TlsMinVersion]
 	}
 	if m.TlsMaxVersion != "" {
 		tlsCfg.MaxVersion = tlsVersions[m.TlsMaxVersion]
 	}
-	if m.TlsServerName != "" {
-		tlsCfg.ServerName = m.TlsServerName
+	tlsCfg.ServerName = m.TlsServerName
+	if m.SslCipher != "" {
+		ciphers, err := parseCipherList(m.SslCipher)
+		if err != nil {
+			return nil, err
+		}
+		tlsCfg.CipherSuites = ciphers
+	}
+	var tls13Suites []uint16
+	if m.TlsCiphersuites != "" {
+		suites, err := parseTLS13CipherSuites(m.TlsCiphersuites)
+		if err != nil {
+			return nil, err
+		}
+		tls13Suites = suites
 	}
-	tlsCfg.InsecureSkipVerify = m.TlsInsecureSkipVerify
-	mysql.RegisterTLSConfig(configSectionName, &tlsCfg)
-	return nil
+
+	// Go would verify the server against the CA certificates of the time
+	// the connection pool was opened, so it is verified once the handshake
+	// completed instead.
+	tlsCfg.InsecureSkipVerify = true
+	tlsCfg.VerifyConnection = m.verifyConnection(tlsCfg.ServerName, tls13Suites)
+	return &tlsCfg, nil
 }
@@ -360,3 +360,23 @@ func TestFormDSNWithCustomTls(t *testing.T) {
 		})
 	})
 }
+
+func TestChangedSections(t *testing.T) {
+	convey.Convey("Changed sections between reloads", t, func() {
+		oldConfig := &Config{Sections: map[string]MySqlConfig{
+			"client":         {User: "root", Password: "abc"},
+			"client.server1": {User: "test", Password: "foo"},
+			"client.server2": {User: "test", Password: "bar"},
+			"client.server3": {User: "test", Password: "baz"},
+		}}
+		newConfig := &Config{Sections: map[string]MySqlConfig{
+			"client":         {User: "root", Password: "abc"},
+			"client.server1": {User: "test", Password: "changed"},
+			"client.server3": {User: "test", Password: "baz"},
+			"client.server4": {User: "test", Password: "new"},
+		}}
+		convey.So(oldConfig.ChangedSections(newConfig), convey.ShouldResemble, []string{"client.server1", "client.server2"})
+		convey.So(oldConfig.ChangedSections(oldConfig), convey.ShouldBeEmpty)
+		convey.So((&Config{}).ChangedSections(newConfig), convey.ShouldBeEmpty)
+	})
+}
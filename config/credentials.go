@@ -0,0 +1,187 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// passwordCommandTimeout is the maximum duration of a password-command.
+	passwordCommandTimeout = 10 * time.Second
+	// defaultPasswordCommandTTL is how long the credentials of a
+	// password-command without expiry are cached, unless the section sets
+	// password-command-ttl.
+	defaultPasswordCommandTTL = time.Minute
+)
+
+// credentials are the credentials returned by a password-command.
+type credentials struct {
+	User     string    `json:"user"`
+	Password string    `json:"password"`
+	Expiry   time.Time `json:"expires_at"`
+}
+
+// credentialCache caches the credentials returned by password-commands until
+// their expiry, keyed by the command, the config section and the target.
+// Concurrent lookups of the same key share a single run of the command.
+var credentialCache = struct {
+	sync.Mutex
+	entries map[string]credentials
+	runs    singleflight.Group
+}{entries: map[string]credentials{}}
+
+// resolveCredentials returns the user and password for connecting to the
+// address with the section. A password-command takes precedence over a
+// password-file, which takes precedence over the password.
+func (m MySqlConfig) resolveCredentials(ctx context.Context, section, address string) (string, string, error) {
+	switch {
+	case m.PasswordCommand != "":
+		ttl, err := m.passwordCommandTTL()
+		if err != nil {
+			return "", "", err
+		}
+		creds, err := commandCredentials(ctx, m.PasswordCommand, section, address, ttl)
+		if err != nil {
+			return "", "", err
+		}
+		if creds.User == "" {
+			creds.User = m.User
+		}
+		return creds.User, creds.Password, nil
+	case m.PasswordFile != "":
+		// The file is read every time, so that rotated secrets are picked up.
+		password, err := os.ReadFile(m.PasswordFile)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to read password-file: %w", err)
+		}
+		return m.User, strings.TrimRight(string(password), "\r\n"), nil
+	default:
+		return m.User, m.Password, nil
+	}
+}
+
+// passwordCommandTTL returns how long the credentials of the
+// password-command are cached if they have no expiry.
+func (m MySqlConfig) passwordCommandTTL() (time.Duration, error) {
+	if m.PasswordCommandTTL == "" {
+		return defaultPasswordCommandTTL, nil
+	}
+	ttl, err := time.ParseDuration(m.PasswordCommandTTL)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse password-command-ttl: %w", err)
+	}
+	if ttl < 0 {
+		return 0, fmt.Errorf("password-command-ttl must not be negative: %s", m.PasswordCommandTTL)
+	}
+	return ttl, nil
+}
+
+// commandCredentials returns the credentials of the password-command for the
+// section and address, running the command unless cached credentials have
+// not expired yet. Credentials without expiry are cached for ttl.
+func commandCredentials(ctx context.Context, command, section, address string, ttl time.Duration) (credentials, error) {
+	key := strings.Join([]string{command, section, address}, "\x00")
+	credentialCache.Lock()
+	creds, ok := credentialCache.entries[key]
+	if ok && time.Now().After(creds.Expiry) {
+		delete(credentialCache.entries, key)
+		ok = false
+	}
+	credentialCache.Unlock()
+	if ok {
+		return creds, nil
+	}
+
+	// The command runs with the context of the first lookup, the others
+	// only wait for it as long as their own context allows.
+	result := credentialCache.runs.DoChan(key, func() (any, error) {
+		creds, err := runPasswordCommand(ctx, command, section, address)
+		if err != nil {
+			return credentials{}, err
+		}
+		now := time.Now()
+		if creds.Expiry.IsZero() && ttl > 0 {
+			creds.Expiry = now.Add(ttl)
+		}
+		// The expired credentials of other commands, sections and targets
+		// are removed when caching, as they may not be looked up again.
+		if now.Before(creds.Expiry) {
+			credentialCache.Lock()
+			for k, cached := range credentialCache.entries {
+				if now.After(cached.Expiry) {
+					delete(credentialCache.entries, k)
+				}
+			}
+			credentialCache.entries[key] = creds
+			credentialCache.Unlock()
+		}
+		return creds, nil
+	})
+	select {
+	case r := <-result:
+		if r.Err != nil {
+			return credentials{}, r.Err
+		}
+		return r.Val.(credentials), nil
+	case <-ctx.Done():
+		return credentials{}, ctx.Err()
+	}
+}
+
+// runPasswordCommand runs the password-command and parses the credentials it
+// writes to stdout as JSON. The command is split on whitespace and not run
+// in a shell. The section and address are passed in the environment. The
+// command is killed once ctx is done or it ran for passwordCommandTimeout.
+func runPasswordCommand(ctx context.Context, command, section, address string) (credentials, error) {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return credentials{}, errors.New("empty password-command")
+	}
+	ctx, cancel := context.WithTimeout(ctx, passwordCommandTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Env = append(os.Environ(),
+		"MYSQLD_EXPORTER_SECTION="+section,
+		"MYSQLD_EXPORTER_TARGET="+address,
+	)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return credentials{}, fmt.Errorf("password-command failed: %w: %s", err, msg)
+		}
+		return credentials{}, fmt.Errorf("password-command failed: %w", err)
+	}
+	var creds credentials
+	if err := json.Unmarshal(out, &creds); err != nil {
+		return credentials{}, fmt.Errorf("failed to parse output of password-command: %w", err)
+	}
+	if creds.Password == "" {
+		return credentials{}, errors.New("password-command returned no password")
+	}
+	return creds, nil
+}
@@ -0,0 +1,212 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/smartystreets/goconvey/convey"
+)
+
+// writePasswordCommand writes a shell script printing the output and counting
+// its runs in the file returned as second value.
+func writePasswordCommand(t *testing.T, output string) (string, string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("password-command test requires a shell")
+	}
+	dir := t.TempDir()
+	counter := filepath.Join(dir, "runs")
+	script := filepath.Join(dir, "credentials.sh")
+	content := "#!/bin/sh\necho run >> " + counter + "\necho \"" + output + "\"\n"
+	if err := os.WriteFile(script, []byte(content), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	return script, counter
+}
+
+func countRuns(t *testing.T, counter string) int {
+	t.Helper()
+	content, err := os.ReadFile(counter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Count(string(content), "run")
+}
+
+func TestPasswordFile(t *testing.T) {
+	convey.Convey("Password file is read on every DSN", t, func() {
+		filename := filepath.Join(t.TempDir(), "password")
+		convey.So(os.WriteFile(filename, []byte("first\n"), 0o600), convey.ShouldBeNil)
+		section := MySqlConfig{User: "exporter", Password: "ignored", PasswordFile: filename}
+
+		dsn, err := section.FormDSN("db1:3306", "client")
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(dsn, convey.ShouldEqual, "exporter:first@tcp(db1:3306)/")
+
+		convey.So(os.WriteFile(filename, []byte("second"), 0o600), convey.ShouldBeNil)
+		dsn, err = section.FormDSN("db1:3306", "client")
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(dsn, convey.ShouldEqual, "exporter:second@tcp(db1:3306)/")
+
+		convey.So(os.Remove(filename), convey.ShouldBeNil)
+		_, err = section.FormDSN("db1:3306", "client")
+		convey.So(err, convey.ShouldNotBeNil)
+	})
+}
+
+func TestPasswordCommand(t *testing.T) {
+	convey.Convey("Password command receives section and target", t, func() {
+		script, counter := writePasswordCommand(t, `{\"user\": \"$MYSQLD_EXPORTER_SECTION\", \"password\": \"$MYSQLD_EXPORTER_TARGET\"}`)
+		section := MySqlConfig{User: "exporter", PasswordCommand: script}
+
+		dsn, err := section.FormDSN("db1:3306", "client.orders")
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(dsn, convey.ShouldEqual, "client.orders:db1:3306@tcp(db1:3306)/")
+
+		// Credentials without expiry are cached for the password-command-ttl.
+		_, err = section.FormDSN("db1:3306", "client.orders")
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(countRuns(t, counter), convey.ShouldEqual, 1)
+
+		section.PasswordCommandTTL = "0s"
+		for range 2 {
+			_, err = section.FormDSN("db1:3306", "client.uncached")
+			convey.So(err, convey.ShouldBeNil)
+		}
+		convey.So(countRuns(t, counter), convey.ShouldEqual, 3)
+	})
+
+	convey.Convey("Concurrent lookups run the password command once", t, func() {
+		script, counter := writePasswordCommand(t, `{\"password\": \"secret\"}`)
+		// Let the lookups overlap.
+		content, err := os.ReadFile(script)
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(os.WriteFile(script, []byte(strings.Replace(string(content), "\n", "\nsleep 0.2\n", 1)), 0o700), convey.ShouldBeNil)
+		section := MySqlConfig{User: "exporter", PasswordCommand: script}
+
+		var wg sync.WaitGroup
+		errs := make(chan error, 5)
+		for range 5 {
+			wg.Go(func() {
+				_, err := section.FormDSN("db1:3306", "client")
+				errs <- err
+			})
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			convey.So(err, convey.ShouldBeNil)
+		}
+		convey.So(countRuns(t, counter), convey.ShouldEqual, 1)
+	})
+
+	convey.Convey("Password command is killed once the context is done", t, func() {
+		script, _ := writePasswordCommand(t, `{\"password\": \"secret\"}`)
+		content, err := os.ReadFile(script)
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(os.WriteFile(script, []byte(strings.Replace(string(content), "\n", "\nexec sleep 5\n", 1)), 0o700), convey.ShouldBeNil)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		_, err = MySqlConfig{User: "exporter", PasswordCommand: script}.FormDSNContext(ctx, "db1:3306", "client")
+		convey.So(err, convey.ShouldNotBeNil)
+		convey.So(time.Since(start), convey.ShouldBeLessThan, 2*time.Second)
+	})
+
+	convey.Convey("Password command credentials are cached until expiry", t, func() {
+		script, counter := writePasswordCommand(t, `{\"password\": \"secret\", \"expires_at\": \"2999-01-01T00:00:00Z\"}`)
+		section := MySqlConfig{User: "exporter", PasswordCommand: script}
+
+		for range 3 {
+			dsn, err := section.FormDSN("db1:3306", "client")
+			convey.So(err, convey.ShouldBeNil)
+			convey.So(dsn, convey.ShouldEqual, "exporter:secret@tcp(db1:3306)/")
+		}
+		convey.So(countRuns(t, counter), convey.ShouldEqual, 1)
+
+		// Each target runs the command.
+		_, err := section.FormDSN("db2:3306", "client")
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(countRuns(t, counter), convey.ShouldEqual, 2)
+	})
+
+	convey.Convey("Expired credentials are not cached", t, func() {
+		script, counter := writePasswordCommand(t, `{\"password\": \"secret\", \"expires_at\": \"2000-01-01T00:00:00Z\"}`)
+		section := MySqlConfig{User: "exporter", PasswordCommand: script}
+		for range 2 {
+			_, err := section.FormDSN("db1:3306", "client")
+			convey.So(err, convey.ShouldBeNil)
+		}
+		convey.So(countRuns(t, counter), convey.ShouldEqual, 2)
+	})
+
+	convey.Convey("Expired credentials of other targets are removed when caching", t, func() {
+		stale := strings.Join([]string{"/removed-command", "client", "db9:3306"}, "\x00")
+		credentialCache.Lock()
+		credentialCache.entries[stale] = credentials{Password: "old", Expiry: time.Now().Add(-time.Minute)}
+		credentialCache.Unlock()
+
+		script, _ := writePasswordCommand(t, `{\"password\": \"secret\", \"expires_at\": \"2999-01-01T00:00:00Z\"}`)
+		_, err := MySqlConfig{User: "exporter", PasswordCommand: script}.FormDSN("db1:3306", "client")
+		convey.So(err, convey.ShouldBeNil)
+
+		credentialCache.Lock()
+		defer credentialCache.Unlock()
+		convey.So(credentialCache.entries, convey.ShouldNotContainKey, stale)
+		convey.So(credentialCache.entries, convey.ShouldContainKey, strings.Join([]string{script, "client", "db1:3306"}, "\x00"))
+	})
+
+	convey.Convey("Invalid password command output", t, func() {
+		for _, output := range []string{"not json", `{\"user\": \"exporter\"}`} {
+			script, _ := writePasswordCommand(t, output)
+			_, err := MySqlConfig{User: "exporter", PasswordCommand: script}.FormDSN("db1:3306", "client")
+			convey.So(err, convey.ShouldNotBeNil)
+		}
+		_, err := MySqlConfig{User: "exporter", PasswordCommand: "/nonexistent"}.FormDSN("db1:3306", "client")
+		convey.So(err, convey.ShouldNotBeNil)
+	})
+}
+
+func TestValidateCredentials(t *testing.T) {
+	convey.Convey("Credential providers", t, func() {
+		convey.So(MySqlConfig{PasswordCommand: "/bin/true"}.validateConfig(), convey.ShouldBeNil)
+		convey.So(MySqlConfig{PasswordFile: "/run/secrets/password"}.validateConfig(), convey.ShouldNotBeNil)
+		convey.So(MySqlConfig{User: "exporter", PasswordFile: "/run/secrets/password", PasswordCommand: "/bin/true"}.validateConfig(), convey.ShouldNotBeNil)
+		convey.So(MySqlConfig{PasswordCommand: "/bin/true", PasswordCommandTTL: "5m"}.validateConfig(), convey.ShouldBeNil)
+		convey.So(MySqlConfig{PasswordCommand: "/bin/true", PasswordCommandTTL: "5"}.validateConfig(), convey.ShouldNotBeNil)
+		convey.So(MySqlConfig{PasswordCommand: "/bin/true", PasswordCommandTTL: "-1m"}.validateConfig(), convey.ShouldNotBeNil)
+	})
+}
+
+func TestFormSecondaryDSN(t *testing.T) {
+	convey.Convey("Secondary password DSN", t, func() {
+		section := MySqlConfig{User: "exporter", PasswordFile: "/nonexistent", SecondaryPassword: "old"}
+		dsn, err := section.FormSecondaryDSN("db1:3306", "client")
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(dsn, convey.ShouldEqual, "exporter:old@tcp(db1:3306)/")
+
+		dsn, err = MySqlConfig{User: "exporter", Password: "new"}.FormSecondaryDSN("db1:3306", "client")
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(dsn, convey.ShouldBeEmpty)
+	})
+}
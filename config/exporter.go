@@ -0,0 +1,369 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"maps"
+	"net"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/prometheus/common/model"
+	"go.yaml.in/yaml/v2"
+
+	"github.com/prometheus/mysqld_exporter/collector"
+)
+
+// sessionVariableRE matches the names of MySQL system variables.
+var sessionVariableRE = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// ExporterConfig is the YAML configuration file of the exporter. Its
+// settings take precedence over the flags.
+type ExporterConfig struct {
+	// Collectors enables or disables collectors by name, overriding their
+	// `--collect.<name>` flags.
+	Collectors map[string]bool `yaml:"collectors"`
+	// Options override the tunables of the collectors by the names of their
+	// flags without the `collect.` prefix.
+	Options map[string]string `yaml:"options"`
+	// Exporter overrides the `--exporter.*` flags.
+	Exporter ExporterSettings `yaml:"exporter"`
+	// Targets are the MySQL servers scraped by name.
+	Targets []Target `yaml:"targets"`
+	// Modules are named sets of collectors and options, selected with
+	// `/probe?module=<name>`.
+	Modules map[string]Module `yaml:"modules"`
+	// TargetAccess restricts the targets of requests.
+	TargetAccess TargetAccess `yaml:"target_access"`
+
+	// customQueries are loaded from the custom query file of the settings.
+	customQueries []collector.ScrapeCustomQuery
+}
+
+// ExporterSettings override the `--exporter.*` flags of the same name if
+// set.
+type ExporterSettings struct {
+	QueryTimeout          *model.Duration `yaml:"query_timeout"`
+	LockWaitTimeout       *int            `yaml:"lock_wait_timeout"`
+	EnableLockWaitTimeout *bool           `yaml:"enable_lock_wait_timeout"`
+	LogSlowFilter         *bool           `yaml:"log_slow_filter"`
+	MaxOpenConnections    *int            `yaml:"max_open_connections"`
+	ScrapeConcurrency     *int            `yaml:"scrape_concurrency"`
+	// ScraperPriorities are merged with the priorities of the flags.
+	ScraperPriorities map[string]int `yaml:"scraper_priorities"`
+	// ScraperMinIntervals are merged with the minimum intervals of the
+	// flags.
+	ScraperMinIntervals          map[string]model.Duration `yaml:"scraper_min_intervals"`
+	ConnectionIdleTimeout        *model.Duration           `yaml:"connection_idle_timeout"`
+	ScrapeErrorsIdleTimeout      *model.Duration           `yaml:"scrape_errors_idle_timeout"`
+	BackgroundScrapeInterval     *model.Duration           `yaml:"background_scrape_interval"`
+	BackgroundScrapeMaxStaleness *model.Duration           `yaml:"background_scrape_max_staleness"`
+	// CustomQueryFile overrides the `--collect.custom_query.file` flag, the
+	// custom queries are read again on every reload.
+	CustomQueryFile *string `yaml:"custom_query_file"`
+}
+
+// Target is a named MySQL server, scraped with `/probe?target=<name>`.
+type Target struct {
+	// Name identifies the target, it must be unique within the file.
+	Name string `yaml:"name"`
+	// Address of the server as host:port, exclusive with Socket.
+	Address string `yaml:"address"`
+	// Socket is the path of the unix socket of the server, exclusive with
+	// Address.
+	Socket string `yaml:"socket"`
+	// AuthModule is the section of the my.cnf with the credentials of the
+	// target, client if empty.
+	AuthModule string `yaml:"auth_module"`
+	// Collectors restricts the enabled collectors scraping the target, like
+	// the collect[] parameter. All enabled collectors are used if empty.
+	Collectors []string `yaml:"collectors"`
+	// Labels are added to all metrics of the target.
+	Labels map[string]string `yaml:"labels"`
+	// Module is the module used to scrape the target, if any.
+	Module string `yaml:"module"`
+	// ScrapeInterval scrapes the target in the background on this interval
+	// from the load of the config on, overriding the background scrape
+	// interval of the exporter settings.
+	ScrapeInterval model.Duration `yaml:"scrape_interval"`
+}
+
+// Module bundles the collectors and options of a scrape, like the modules
+// of the blackbox_exporter.
+type Module struct {
+	// Collectors restricts the enabled collectors, like the collect[]
+	// parameter. All enabled collectors are used if empty.
+	Collectors []string `yaml:"collectors"`
+	// Options override the tunables of the collectors by the names of their
+	// flags without the `collect.` prefix, e.g.
+	// `perf_schema.eventsstatements.limit`.
+	Options map[string]string `yaml:"options"`
+	// QueryTimeout overrides the per-scraper query timeout if set.
+	QueryTimeout model.Duration `yaml:"query_timeout"`
+	// LockWaitTimeout overrides the lock_wait_timeout of the connections,
+	// in seconds, if set.
+	LockWaitTimeout *int `yaml:"lock_wait_timeout"`
+	// SessionSettings are MySQL session variables set on the connections,
+	// by name. The values are SQL expressions, so strings must be quoted.
+	SessionSettings map[string]string `yaml:"session_settings"`
+}
+
+// CollectorOptions returns the options with the options of the module
+// applied.
+func (m Module) CollectorOptions(options collector.Options) (collector.Options, error) {
+	return ApplyOptions(options, m.Options)
+}
+
+// CollectorOptions returns the options with the options of the config
+// applied.
+func (c *ExporterConfig) CollectorOptions(options collector.Options) (collector.Options, error) {
+	return ApplyOptions(options, c.Options)
+}
+
+// ApplyOptions returns the options with the values set by option name, see
+// collector.Options.Set.
+func ApplyOptions(options collector.Options, values map[string]string) (collector.Options, error) {
+	for _, name := range slices.Sorted(maps.Keys(values)) {
+		if err := options.Set(name, values[name]); err != nil {
+			return options, err
+		}
+	}
+	return options, nil
+}
+
+// DSNTarget returns the target in the format of the target parameter of
+// MySqlConfig.FormDSN.
+func (t Target) DSNTarget() string {
+	if t.Socket != "" {
+		return "unix://" + t.Socket
+	}
+	return t.Address
+}
+
+// CustomQueries returns the scrapers of the custom query file of the
+// settings, and false if the settings have none.
+func (c *ExporterConfig) CustomQueries() ([]collector.ScrapeCustomQuery, bool) {
+	return c.customQueries, c.Exporter.CustomQueryFile != nil
+}
+
+// Target returns the target with the given name.
+func (c *ExporterConfig) Target(name string) (Target, bool) {
+	for _, t := range c.Targets {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return Target{}, false
+}
+
+// LoadExporterConfig reads and validates the exporter configuration file.
+// The collectors of targets must be among the given collector names.
+func LoadExporterConfig(filename string, collectors []string) (*ExporterConfig, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	cfg := &ExporterConfig{}
+	if err := yaml.UnmarshalStrict(content, cfg); err != nil {
+		return nil, fmt.Errorf("error parsing config file %q: %w", filename, err)
+	}
+	if file := cfg.Exporter.CustomQueryFile; file != nil && *file != "" {
+		if cfg.customQueries, err = collector.LoadCustomQueries(*file); err != nil {
+			return nil, fmt.Errorf("error in config file %q: %w", filename, err)
+		}
+		// The custom queries of the file are collectors too.
+		collectors = slices.Clone(collectors)
+		for _, query := range cfg.customQueries {
+			collectors = append(collectors, query.Name())
+		}
+	}
+	if err := cfg.validate(collectors); err != nil {
+		return nil, fmt.Errorf("error in config file %q: %w", filename, err)
+	}
+	return cfg, nil
+}
+
+func (c *ExporterConfig) validate(collectors []string) error {
+	for _, name := range slices.Sorted(maps.Keys(c.Collectors)) {
+		if !slices.Contains(collectors, name) {
+			return fmt.Errorf("unknown collector %q", name)
+		}
+	}
+	if _, err := c.CollectorOptions(collector.DefaultOptions()); err != nil {
+		return err
+	}
+	if err := c.Exporter.validate(collectors); err != nil {
+		return fmt.Errorf("exporter: %w", err)
+	}
+	if err := c.TargetAccess.validate(); err != nil {
+		return fmt.Errorf("target_access: %w", err)
+	}
+	for _, name := range slices.Sorted(maps.Keys(c.Modules)) {
+		if err := c.Modules[name].validate(collectors); err != nil {
+			return fmt.Errorf("module %q: %w", name, err)
+		}
+	}
+	names := map[string]bool{}
+	for i := range c.Targets {
+		t := &c.Targets[i]
+		if t.Name == "" {
+			return fmt.Errorf("target %d has no name", i)
+		}
+		if names[t.Name] {
+			return fmt.Errorf("duplicate target %q", t.Name)
+		}
+		names[t.Name] = true
+		switch {
+		case t.Address == "" && t.Socket == "":
+			return fmt.Errorf("target %q has neither address nor socket", t.Name)
+		case t.Address != "" && t.Socket != "":
+			return fmt.Errorf("target %q has both address and socket", t.Name)
+		case t.Address != "":
+			if _, _, err := net.SplitHostPort(t.Address); err != nil {
+				return fmt.Errorf("target %q has invalid address %q: %w", t.Name, t.Address, err)
+			}
+		}
+		for _, collector := range t.Collectors {
+			if !slices.Contains(collectors, collector) {
+				return fmt.Errorf("target %q has unknown collector %q", t.Name, collector)
+			}
+		}
+		if t.AuthModule == "" {
+			t.AuthModule = "client"
+		}
+		if _, ok := c.Modules[t.Module]; t.Module != "" && !ok {
+			return fmt.Errorf("target %q has unknown module %q", t.Name, t.Module)
+		}
+		for name := range t.Labels {
+			// The instance label is set by Prometheus to the name of the
+			// target.
+			if !model.LegacyValidation.IsValidLabelName(name) || strings.HasPrefix(name, model.ReservedLabelPrefix) || name == model.InstanceLabel {
+				return fmt.Errorf("target %q has invalid label name %q", t.Name, name)
+			}
+		}
+	}
+	return nil
+}
+
+func (s ExporterSettings) validate(collectors []string) error {
+	if s.QueryTimeout != nil && *s.QueryTimeout < 0 {
+		return fmt.Errorf("query_timeout must be >= 0: %s", *s.QueryTimeout)
+	}
+	if s.LockWaitTimeout != nil && *s.LockWaitTimeout < 0 {
+		return fmt.Errorf("lock_wait_timeout must be >= 0: %d", *s.LockWaitTimeout)
+	}
+	if s.MaxOpenConnections != nil && *s.MaxOpenConnections < 1 {
+		return fmt.Errorf("max_open_connections must be >= 1: %d", *s.MaxOpenConnections)
+	}
+	if s.ScrapeConcurrency != nil && *s.ScrapeConcurrency < 0 {
+		return fmt.Errorf("scrape_concurrency must be >= 0: %d", *s.ScrapeConcurrency)
+	}
+	for _, name := range slices.Sorted(maps.Keys(s.ScraperPriorities)) {
+		if !slices.Contains(collectors, name) {
+			return fmt.Errorf("scraper_priorities has unknown collector %q", name)
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(s.ScraperMinIntervals)) {
+		if !slices.Contains(collectors, name) {
+			return fmt.Errorf("scraper_min_intervals has unknown collector %q", name)
+		}
+		if s.ScraperMinIntervals[name] <= 0 {
+			return fmt.Errorf("scraper_min_intervals must be > 0: %s=%s", name, s.ScraperMinIntervals[name])
+		}
+	}
+	if s.BackgroundScrapeInterval != nil && s.BackgroundScrapeMaxStaleness != nil && *s.BackgroundScrapeInterval > 0 && *s.BackgroundScrapeMaxStaleness < *s.BackgroundScrapeInterval {
+		return fmt.Errorf("background_scrape_max_staleness must be >= background_scrape_interval: %s", *s.BackgroundScrapeMaxStaleness)
+	}
+	return nil
+}
+
+func (m Module) validate(collectors []string) error {
+	for _, collector := range m.Collectors {
+		if !slices.Contains(collectors, collector) {
+			return fmt.Errorf("unknown collector %q", collector)
+		}
+	}
+	if _, err := m.CollectorOptions(collector.DefaultOptions()); err != nil {
+		return err
+	}
+	if m.QueryTimeout < 0 {
+		return fmt.Errorf("query_timeout must be >= 0: %s", m.QueryTimeout)
+	}
+	if m.LockWaitTimeout != nil && *m.LockWaitTimeout < 1 {
+		return fmt.Errorf("lock_wait_timeout must be >= 1: %d", *m.LockWaitTimeout)
+	}
+	for name, value := range m.SessionSettings {
+		if !sessionVariableRE.MatchString(name) {
+			return fmt.Errorf("invalid session setting %q", name)
+		}
+		// Only the parameters the MySQL driver does not handle itself are
+		// set as session variables.
+		dsn, err := mysql.ParseDSN("/?" + name + "=" + url.QueryEscape(value))
+		if err != nil {
+			return fmt.Errorf("invalid session setting %q: %w", name, err)
+		}
+		if _, ok := dsn.Params[name]; !ok {
+			return fmt.Errorf("session setting %q is a parameter of the MySQL driver", name)
+		}
+	}
+	return nil
+}
+
+// ExporterConfigHandler holds the exporter configuration, which is replaced
+// as a whole on reloads.
+type ExporterConfigHandler struct {
+	sync.RWMutex
+	Config *ExporterConfig
+}
+
+func (ch *ExporterConfigHandler) GetConfig() *ExporterConfig {
+	ch.RLock()
+	defer ch.RUnlock()
+	return ch.Config
+}
+
+// LoadConfig loads and validates the configuration file without applying
+// it. An empty filename loads an empty configuration.
+func (ch *ExporterConfigHandler) LoadConfig(filename string, collectors []string) (*ExporterConfig, error) {
+	if filename == "" {
+		return &ExporterConfig{}, nil
+	}
+	return LoadExporterConfig(filename, collectors)
+}
+
+// SetConfig replaces the configuration.
+func (ch *ExporterConfigHandler) SetConfig(cfg *ExporterConfig) {
+	ch.Lock()
+	ch.Config = cfg
+	ch.Unlock()
+}
+
+// ReloadConfig loads the configuration file and applies it, keeping the
+// current configuration if it is invalid. An empty filename loads an empty
+// configuration. The result is reported in the config reload metrics.
+func (ch *ExporterConfigHandler) ReloadConfig(filename string, collectors []string) error {
+	cfg, err := ch.LoadConfig(filename, collectors)
+	reportReload(err)
+	if err != nil {
+		return err
+	}
+	ch.SetConfig(cfg)
+	return nil
+}
@@ -0,0 +1,215 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/common/promslog"
+	"github.com/smartystreets/goconvey/convey"
+
+	"github.com/prometheus/mysqld_exporter/collector"
+)
+
+var testCollectors = []string{"global_status", "info_schema.tables"}
+
+func TestLoadExporterConfig(t *testing.T) {
+	convey.Convey("Targets", t, func() {
+		cfg, err := LoadExporterConfig("testdata/exporter.yml", testCollectors)
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(cfg.Targets, convey.ShouldHaveLength, 2)
+
+		primary, ok := cfg.Target("primary")
+		convey.So(ok, convey.ShouldBeTrue)
+		convey.So(primary.DSNTarget(), convey.ShouldEqual, "db1.example.com:3306")
+		convey.So(primary.AuthModule, convey.ShouldEqual, "client.primary")
+		convey.So(primary.ScrapeInterval, convey.ShouldEqual, model.Duration(30*time.Second))
+		convey.So(primary.Labels, convey.ShouldResemble, map[string]string{"env": "production", "cluster": "orders", "shard": "1"})
+
+		local, ok := cfg.Target("local")
+		convey.So(ok, convey.ShouldBeTrue)
+		convey.So(local.DSNTarget(), convey.ShouldEqual, "unix:///run/mysqld/mysqld.sock")
+		convey.So(local.AuthModule, convey.ShouldEqual, "client")
+		convey.So(local.Collectors, convey.ShouldResemble, []string{"global_status", "info_schema.tables"})
+
+		_, ok = cfg.Target("unknown")
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+
+	convey.Convey("Modules", t, func() {
+		cfg, err := LoadExporterConfig("testdata/exporter.yml", testCollectors)
+		convey.So(err, convey.ShouldBeNil)
+		local, _ := cfg.Target("local")
+		convey.So(local.Module, convey.ShouldEqual, "statements")
+
+		module, ok := cfg.Modules["statements"]
+		convey.So(ok, convey.ShouldBeTrue)
+		convey.So(module.Collectors, convey.ShouldResemble, []string{"global_status"})
+		convey.So(time.Duration(module.QueryTimeout), convey.ShouldEqual, 5*time.Second)
+		convey.So(*module.LockWaitTimeout, convey.ShouldEqual, 1)
+		convey.So(module.SessionSettings, convey.ShouldResemble, map[string]string{"max_execution_time": "1000", "time_zone": "'+00:00'"})
+
+		options, err := module.CollectorOptions(collector.DefaultOptions())
+		convey.So(err, convey.ShouldBeNil)
+		want := collector.DefaultOptions()
+		want.PerfEventsStatementsLimit = 50
+		want.PerfEventsStatementsExcludeSchemas = []string{"sys", "test"}
+		convey.So(options, convey.ShouldResemble, want)
+	})
+
+	convey.Convey("Settings", t, func() {
+		cfg, err := LoadExporterConfig("testdata/exporter.yml", testCollectors)
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(cfg.Collectors, convey.ShouldResemble, map[string]bool{"info_schema.tables": true, "global_status": false})
+
+		options, err := cfg.CollectorOptions(collector.DefaultOptions())
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(options.TableSchemaDatabases, convey.ShouldEqual, "shop")
+
+		settings := cfg.Exporter
+		convey.So(time.Duration(*settings.QueryTimeout), convey.ShouldEqual, 10*time.Second)
+		convey.So(*settings.LockWaitTimeout, convey.ShouldEqual, 5)
+		convey.So(settings.EnableLockWaitTimeout, convey.ShouldBeNil)
+		convey.So(*settings.MaxOpenConnections, convey.ShouldEqual, 4)
+		convey.So(*settings.ScrapeConcurrency, convey.ShouldEqual, 2)
+		convey.So(settings.ScraperPriorities, convey.ShouldResemble, map[string]int{"info_schema.tables": -1})
+		convey.So(settings.ScraperMinIntervals, convey.ShouldResemble, map[string]model.Duration{"info_schema.tables": model.Duration(time.Hour)})
+		convey.So(time.Duration(*settings.ConnectionIdleTimeout), convey.ShouldEqual, 10*time.Minute)
+		convey.So(time.Duration(*settings.ScrapeErrorsIdleTimeout), convey.ShouldEqual, 2*time.Hour)
+		convey.So(time.Duration(*settings.BackgroundScrapeInterval), convey.ShouldEqual, 15*time.Second)
+		convey.So(time.Duration(*settings.BackgroundScrapeMaxStaleness), convey.ShouldEqual, time.Minute)
+		_, ok := cfg.CustomQueries()
+		convey.So(ok, convey.ShouldBeFalse)
+	})
+
+	convey.Convey("Custom queries", t, func() {
+		dir := t.TempDir()
+		queries := filepath.Join(dir, "queries.yml")
+		convey.So(os.WriteFile(queries, []byte("queries:\n  - name: jobs\n    query: SELECT COUNT(*) AS size FROM jobs\n    metrics:\n      - name: jobs\n        column: size\n"), 0o600), convey.ShouldBeNil)
+		filename := filepath.Join(dir, "exporter.yml")
+		content := "exporter:\n  custom_query_file: " + queries + "\n  scraper_min_intervals:\n    custom_query.jobs: 5m\ncollectors:\n  custom_query.jobs: false\n"
+		convey.So(os.WriteFile(filename, []byte(content), 0o600), convey.ShouldBeNil)
+
+		cfg, err := LoadExporterConfig(filename, testCollectors)
+		convey.So(err, convey.ShouldBeNil)
+		customQueries, ok := cfg.CustomQueries()
+		convey.So(ok, convey.ShouldBeTrue)
+		convey.So(customQueries, convey.ShouldHaveLength, 1)
+		convey.So(customQueries[0].Name(), convey.ShouldEqual, "custom_query.jobs")
+
+		convey.So(os.WriteFile(queries, []byte("queries: [{name: jobs}]\n"), 0o600), convey.ShouldBeNil)
+		_, err = LoadExporterConfig(filename, testCollectors)
+		convey.So(err, convey.ShouldNotBeNil)
+	})
+
+	convey.Convey("Target access", t, func() {
+		cfg, err := LoadExporterConfig("testdata/exporter.yml", testCollectors)
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(cfg.TargetAccess, convey.ShouldResemble, TargetAccess{
+			Allow:                      []string{"*.example.com", "10.20.0.0/16", "/run/mysqld/*.sock"},
+			Deny:                       []string{"169.254.0.0/16"},
+			RequireAuthModuleAllowlist: true,
+		})
+	})
+
+	convey.Convey("Invalid targets", t, func() {
+		for _, content := range []string{
+			"targets:\n  - address: db1:3306\n",
+			"targets:\n  - name: a\n    address: db1:3306\n  - name: a\n    address: db2:3306\n",
+			"targets:\n  - name: a\n",
+			"targets:\n  - name: a\n    address: db1:3306\n    socket: /tmp/mysql.sock\n",
+			"targets:\n  - name: a\n    address: db1\n",
+			"targets:\n  - name: a\n    address: db1:3306\n    collectors: [info_schema.foo]\n",
+			"targets:\n  - name: a\n    address: db1:3306\n    labels:\n      __name__: foo\n",
+			"targets:\n  - name: a\n    address: db1:3306\n    labels:\n      my-label: foo\n",
+			"targets:\n  - name: a\n    address: db1:3306\n    labels:\n      instance: foo\n",
+			"targets:\n  - name: a\n    host: db1:3306\n",
+			"targets:\n  - name: a\n    address: db1:3306\n    module: foo\n",
+			"modules:\n  foo:\n    collectors: [info_schema.foo]\n",
+			"modules:\n  foo:\n    options:\n      perf_schema.eventsstatements.foo: 1\n",
+			"modules:\n  foo:\n    options:\n      perf_schema.eventsstatements.limit: many\n",
+			"modules:\n  foo:\n    options:\n      heartbeat.table: \"x`; DROP TABLE y; --\"\n",
+			"modules:\n  foo:\n    query_timeout: -1s\n",
+			"modules:\n  foo:\n    lock_wait_timeout: 0\n",
+			"modules:\n  foo:\n    session_settings:\n      max-execution-time: 1000\n",
+			"modules:\n  foo:\n    session_settings:\n      tls: skip-verify\n",
+			"collectors:\n  info_schema.foo: true\n",
+			"options:\n  info_schema.tables.databases: [a, b]\n",
+			"options:\n  perf_schema.eventsstatements.limit: -1\n",
+			"exporter:\n  query_timeout: 1x\n",
+			"exporter:\n  lock_wait_timeout: -1\n",
+			"exporter:\n  max_open_connections: 0\n",
+			"exporter:\n  scrape_concurrency: -1\n",
+			"exporter:\n  scraper_priorities:\n    info_schema.foo: 1\n",
+			"exporter:\n  scraper_min_intervals:\n    info_schema.foo: 1h\n",
+			"exporter:\n  scraper_min_intervals:\n    info_schema.tables: 0s\n",
+			"exporter:\n  background_scrape_interval: 1m\n  background_scrape_max_staleness: 30s\n",
+			"exporter:\n  connection_idle_timeout: -1m\n",
+			"exporter:\n  custom_query_file: /nonexistent/queries.yml\n",
+			"exporter:\n  listen_address: :9104\n",
+			"target_access:\n  allow: [10.20.0.0/33]\n",
+			"target_access:\n  deny: [\"db[1\"]\n",
+		} {
+			filename := filepath.Join(t.TempDir(), "exporter.yml")
+			convey.So(os.WriteFile(filename, []byte(content), 0o600), convey.ShouldBeNil)
+			_, err := LoadExporterConfig(filename, testCollectors)
+			convey.So(err, convey.ShouldNotBeNil)
+		}
+	})
+}
+
+func TestReloadExporterConfig(t *testing.T) {
+	convey.Convey("Keep the config on errors", t, func() {
+		ch := ExporterConfigHandler{}
+		convey.So(ch.ReloadConfig("", testCollectors), convey.ShouldBeNil)
+		convey.So(ch.GetConfig().Targets, convey.ShouldBeEmpty)
+
+		convey.So(ch.ReloadConfig("testdata/exporter.yml", testCollectors), convey.ShouldBeNil)
+		convey.So(ch.GetConfig().Targets, convey.ShouldHaveLength, 2)
+		convey.So(testutil.ToFloat64(configReloadSuccess), convey.ShouldEqual, 1)
+
+		convey.So(ch.ReloadConfig("testdata/nonexistent.yml", testCollectors), convey.ShouldNotBeNil)
+		convey.So(ch.GetConfig().Targets, convey.ShouldHaveLength, 2)
+		convey.So(testutil.ToFloat64(configReloadSuccess), convey.ShouldEqual, 0)
+	})
+}
+
+func TestReloadConfigs(t *testing.T) {
+	convey.Convey("Apply both configs only if both are valid", t, func() {
+		exporterHandler := ExporterConfigHandler{Config: &ExporterConfig{}}
+		mysqlHandler := MySqlConfigHandler{Config: &Config{}}
+		logger := promslog.NewNopLogger()
+
+		err := ReloadConfigs(&exporterHandler, "testdata/exporter.yml", testCollectors, &mysqlHandler, "testdata/missing_user.cnf", "localhost:3306", "", false, logger)
+		convey.So(err, convey.ShouldNotBeNil)
+		convey.So(exporterHandler.GetConfig().Targets, convey.ShouldBeEmpty)
+		convey.So(mysqlHandler.GetConfig().Sections, convey.ShouldBeEmpty)
+		convey.So(testutil.ToFloat64(configReloadSuccess), convey.ShouldEqual, 0)
+
+		err = ReloadConfigs(&exporterHandler, "testdata/nonexistent.yml", testCollectors, &mysqlHandler, "testdata/client.cnf", "localhost:3306", "", false, logger)
+		convey.So(err, convey.ShouldNotBeNil)
+		convey.So(mysqlHandler.GetConfig().Sections, convey.ShouldBeEmpty)
+
+		err = ReloadConfigs(&exporterHandler, "testdata/exporter.yml", testCollectors, &mysqlHandler, "testdata/client.cnf", "localhost:3306", "", false, logger)
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(exporterHandler.GetConfig().Targets, convey.ShouldHaveLength, 2)
+		convey.So(mysqlHandler.GetConfig().Sections, convey.ShouldContainKey, "client")
+		convey.So(testutil.ToFloat64(configReloadSuccess), convey.ShouldEqual, 1)
+	})
+}
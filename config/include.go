@@ -0,0 +1,192 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"gopkg.in/ini.v1"
+)
+
+// maxIncludeDepth is the maximum nesting of !include and !includedir
+// directives, which also stops include cycles.
+const maxIncludeDepth = 10
+
+// location is the file and line a section or option was defined at.
+type location struct {
+	file string
+	line int
+}
+
+func (l location) String() string {
+	return fmt.Sprintf("%s:%d", l.file, l.line)
+}
+
+// optionFileSources are the parts of MySQL option files in the order they
+// are read, along with where their sections and options were defined last.
+type optionFileSources struct {
+	sources  []any
+	sections map[string]location
+	options  map[string]map[string]location
+}
+
+// loadOptionFile reads the MySQL option file and the files it includes with
+// !include and !includedir like the mysql client does: included files are
+// read at the position of the directive, so options after it take
+// precedence. A nonexistent option file is read as empty.
+func loadOptionFile(filename string) (*optionFileSources, error) {
+	s := &optionFileSources{
+		sections: map[string]location{},
+		options:  map[string]map[string]location{},
+	}
+	if _, err := os.Stat(filename); filename == "" || errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err := s.read(filename, 0); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// read reads the option file, splitting it into sources at include
+// directives.
+func (s *optionFileSources) read(filename string, depth int) error {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	var source bytes.Buffer
+	section := ini.DefaultSection
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		at := location{file: filename, line: lineNumber}
+
+		if directive, path, ok := parseIncludeDirective(trimmed); ok {
+			if depth >= maxIncludeDepth {
+				return fmt.Errorf("%s: includes nested more than %d levels", at, maxIncludeDepth)
+			}
+			// Relative paths are relative to the including file.
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(filepath.Dir(filename), path)
+			}
+			s.flush(&source)
+			if err := s.include(directive, path, depth+1); err != nil {
+				return fmt.Errorf("%s: %w", at, err)
+			}
+			// Continue with the section the directive was in.
+			if section != ini.DefaultSection {
+				fmt.Fprintf(&source, "[%s]\n", section)
+			}
+			continue
+		}
+
+		switch {
+		case trimmed == "" || trimmed[0] == '#' || trimmed[0] == ';':
+		case trimmed[0] == '[' && strings.HasSuffix(trimmed, "]"):
+			section = strings.TrimSpace(trimmed[1 : len(trimmed)-1])
+			if _, ok := s.sections[section]; !ok {
+				s.sections[section] = at
+			}
+		default:
+			name, _, _ := strings.Cut(trimmed, "=")
+			if s.options[section] == nil {
+				s.options[section] = map[string]location{}
+			}
+			s.options[section][strings.TrimSpace(name)] = at
+		}
+		source.WriteString(line)
+		source.WriteByte('\n')
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read %s: %w", filename, err)
+	}
+	s.flush(&source)
+	return nil
+}
+
+// parseIncludeDirective parses an !include or !includedir line.
+func parseIncludeDirective(line string) (string, string, bool) {
+	i := strings.IndexFunc(line, unicode.IsSpace)
+	if i < 0 {
+		return "", "", false
+	}
+	directive, path := line[:i], strings.TrimSpace(line[i:])
+	if (directive != "!include" && directive != "!includedir") || path == "" {
+		return "", "", false
+	}
+	return directive, path, true
+}
+
+// include reads the file of an !include directive, or the .cnf files in the
+// directory of an !includedir directive in the order of their names.
+func (s *optionFileSources) include(directive, path string, depth int) error {
+	if directive == "!include" {
+		return s.read(path, depth)
+	}
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".cnf" {
+			continue
+		}
+		if err := s.read(filepath.Join(path, entry.Name()), depth); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// flush adds the content of the buffer as a source.
+func (s *optionFileSources) flush(source *bytes.Buffer) {
+	if source.Len() > 0 {
+		s.sources = append(s.sources, bytes.Clone(source.Bytes()))
+		source.Reset()
+	}
+}
+
+// locate returns where the option of the section was defined, looking it up
+// in the parent sections like it is inherited from them, or where the
+// section was defined if option is empty. It returns an empty string if the
+// location is unknown, e.g. for options set by flags.
+func (s *optionFileSources) locate(section, option string) string {
+	if option == "" {
+		if at, ok := s.sections[section]; ok {
+			return at.String()
+		}
+		return ""
+	}
+	for name := section; ; {
+		if at, ok := s.options[name][option]; ok {
+			return at.String()
+		}
+		i := strings.LastIndex(name, ".")
+		if i < 0 {
+			return s.locate(section, "")
+		}
+		name = name[:i]
+	}
+}
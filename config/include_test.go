@@ -0,0 +1,122 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/prometheus/common/promslog"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+// writeOptionFiles writes the files to a temporary directory and returns its
+// path.
+func writeOptionFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		filename := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(filename), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filename, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestIncludeDirectives(t *testing.T) {
+	convey.Convey("Include files and directories", t, func() {
+		dir := writeOptionFiles(t, map[string]string{
+			"my.cnf":     "[client]\nuser = root\npassword = abc\n!include common.cnf\nhost = db1\n!includedir conf.d\n[client.orders]\nuser = orders\n",
+			"common.cnf": "[client]\nhost = common\nssl-ca = /etc/mysql/ca.pem\ntls-min-version = TLSv1.2\n",
+			// Read in the order of the names, ignoring other files.
+			"conf.d/10-replica.cnf": "[client.replica]\nuser = replica\nhost = replica1\n",
+			"conf.d/20-replica.cnf": "[client.replica]\nhost = replica2\n",
+			"conf.d/README":         "[client.readme]\nuser = readme\n",
+		})
+		c := MySqlConfigHandler{}
+		err := c.ReloadConfig(filepath.Join(dir, "my.cnf"), "localhost:3306", "", false, promslog.NewNopLogger())
+		convey.So(err, convey.ShouldBeNil)
+
+		cfg := c.GetConfig()
+		client := cfg.Sections["client"]
+		// Options after the directive take precedence over the included file.
+		convey.So(client.Host, convey.ShouldEqual, "db1")
+		convey.So(client.SslCa, convey.ShouldEqual, "/etc/mysql/ca.pem")
+
+		replica := cfg.Sections["client.replica"]
+		convey.So(replica.User, convey.ShouldEqual, "replica")
+		convey.So(replica.Host, convey.ShouldEqual, "replica2")
+		convey.So(cfg.Sections, convey.ShouldNotContainKey, "client.readme")
+
+		// Sections inherit the options of their parent section.
+		orders := cfg.Sections["client.orders"]
+		convey.So(orders.User, convey.ShouldEqual, "orders")
+		convey.So(orders.Password, convey.ShouldEqual, "abc")
+		convey.So(orders.Host, convey.ShouldEqual, "db1")
+		convey.So(orders.SslCa, convey.ShouldEqual, "/etc/mysql/ca.pem")
+		convey.So(orders.TlsMinVersion, convey.ShouldEqual, "TLSv1.2")
+	})
+
+	convey.Convey("Include errors report the directive", t, func() {
+		for content, want := range map[string]string{
+			"[client]\nuser = root\n!include missing.cnf\n":  "my.cnf:3: open ",
+			"[client]\nuser = root\n!includedir missing.d\n": "my.cnf:3: open ",
+			"[client]\nuser = root\n!include my.cnf\n":       "includes nested more than 10 levels",
+		} {
+			dir := writeOptionFiles(t, map[string]string{"my.cnf": content})
+			c := MySqlConfigHandler{}
+			err := c.ReloadConfig(filepath.Join(dir, "my.cnf"), "localhost:3306", "", false, promslog.NewNopLogger())
+			convey.So(err, convey.ShouldNotBeNil)
+			convey.So(err.Error(), convey.ShouldContainSubstring, want)
+		}
+	})
+}
+
+func TestOptionLocations(t *testing.T) {
+	convey.Convey("Locate sections and options", t, func() {
+		dir := writeOptionFiles(t, map[string]string{
+			"my.cnf":  "[client]\nuser = root\n!include tls.cnf\n[client.orders]\nhost = db1\n",
+			"tls.cnf": "# TLS\n[client]\ntls-min-version = TLSv1.4\n",
+		})
+		files, err := loadOptionFile(filepath.Join(dir, "my.cnf"))
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(files.locate("client", "user"), convey.ShouldEqual, filepath.Join(dir, "my.cnf")+":2")
+		convey.So(files.locate("client.orders", "host"), convey.ShouldEqual, filepath.Join(dir, "my.cnf")+":5")
+		// Inherited options are located in the parent section.
+		convey.So(files.locate("client.orders", "tls-min-version"), convey.ShouldEqual, filepath.Join(dir, "tls.cnf")+":3")
+		// Missing options are located at the section.
+		convey.So(files.locate("client.orders", "password"), convey.ShouldEqual, filepath.Join(dir, "my.cnf")+":4")
+		convey.So(files.locate("mysqldump", "user"), convey.ShouldBeEmpty)
+
+		var logs bytes.Buffer
+		c := MySqlConfigHandler{}
+		err = c.ReloadConfig(filepath.Join(dir, "my.cnf"), "localhost:3306", "", false, slog.New(slog.NewTextHandler(&logs, nil)))
+		// Both sections inherit the invalid TLS version.
+		convey.So(err, convey.ShouldNotBeNil)
+		convey.So(logs.String(), convey.ShouldContainSubstring, "location="+filepath.Join(dir, "tls.cnf")+":3")
+	})
+
+	convey.Convey("Nonexistent option file is empty", t, func() {
+		files, err := loadOptionFile(filepath.Join(t.TempDir(), "missing.cnf"))
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(files.sources, convey.ShouldBeEmpty)
+	})
+}
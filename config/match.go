@@ -0,0 +1,166 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"maps"
+	"net"
+	"net/netip"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// targetRule matches the targets of a match-target rule by host and port,
+// or by socket path.
+type targetRule struct {
+	// host is a glob pattern of the host name or address, unless prefix is
+	// valid. An empty host matches every host.
+	host   string
+	prefix netip.Prefix
+	// port is empty to match every port.
+	port string
+	// socket is a glob pattern of the path of unix socket targets.
+	socket string
+}
+
+// targetAddress is the host and port, or the socket path, of a target. The
+// addresses are those of the host, if known.
+type targetAddress struct {
+	host, port, socket string
+	addrs              []netip.Addr
+}
+
+// parseTargetAddress parses a host:port or unix:// target.
+func parseTargetAddress(target string) targetAddress {
+	if socket, ok := strings.CutPrefix(target, "unix://"); ok {
+		return targetAddress{socket: socket}
+	}
+	host, port, err := net.SplitHostPort(target)
+	if err != nil {
+		host, port = target, ""
+	}
+	t := targetAddress{host: host, port: port}
+	if addr, err := netip.ParseAddr(host); err == nil {
+		t.addrs = []netip.Addr{addr.Unmap()}
+	}
+	return t
+}
+
+// parseTargetRules parses the comma or space separated rules of
+// match-target, each a host glob pattern or CIDR, optionally followed by a
+// port, only a port, or a glob pattern of socket paths, e.g. `*.prod-eu.db`,
+// `10.20.0.0/16:3307`, `:3307` or `/run/mysqld/*.sock`.
+func parseTargetRules(rules string) ([]targetRule, error) {
+	var parsed []targetRule
+	for _, rule := range strings.FieldsFunc(rules, func(r rune) bool { return r == ',' || unicode.IsSpace(r) }) {
+		r, err := parseTargetRule(rule)
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, r)
+	}
+	return parsed, nil
+}
+
+func parseTargetRule(rule string) (targetRule, error) {
+	if socket, ok := strings.CutPrefix(rule, "unix://"); ok || strings.HasPrefix(rule, "/") {
+		if !ok {
+			socket = rule
+		}
+		if _, err := path.Match(socket, ""); err != nil {
+			return targetRule{}, fmt.Errorf("invalid socket pattern in rule %q: %w", rule, err)
+		}
+		return targetRule{socket: socket}, nil
+	}
+
+	r := targetRule{host: rule}
+	if host, port, err := net.SplitHostPort(rule); err == nil {
+		if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+			return targetRule{}, fmt.Errorf("invalid port in rule %q", rule)
+		}
+		r.host, r.port = host, port
+	}
+	if strings.Contains(r.host, "/") {
+		prefix, err := netip.ParsePrefix(r.host)
+		if err != nil {
+			return targetRule{}, fmt.Errorf("invalid CIDR in rule %q: %w", rule, err)
+		}
+		r.prefix = prefix.Masked()
+	} else if _, err := path.Match(r.host, ""); err != nil {
+		return targetRule{}, fmt.Errorf("invalid host pattern in rule %q: %w", rule, err)
+	}
+	return r, nil
+}
+
+// matches returns whether the rule matches the target. A CIDR matches a
+// host name if it contains all of its addresses, or any of them if anyAddr
+// is set.
+func (r targetRule) matches(t targetAddress, anyAddr bool) bool {
+	if r.socket != "" || t.socket != "" {
+		matched, _ := path.Match(r.socket, t.socket)
+		return r.socket != "" && t.socket != "" && matched
+	}
+	if r.port != "" && r.port != t.port {
+		return false
+	}
+	if r.prefix.IsValid() {
+		contains := func(addr netip.Addr) bool { return r.prefix.Contains(addr) }
+		if anyAddr {
+			return slices.ContainsFunc(t.addrs, contains)
+		}
+		return len(t.addrs) > 0 && !slices.ContainsFunc(t.addrs, func(addr netip.Addr) bool { return !contains(addr) })
+	}
+	matched, _ := path.Match(strings.ToLower(r.host), strings.ToLower(t.host))
+	return r.host == "" || matched
+}
+
+// matchesTarget returns whether any of the rules matches the target.
+func matchesTarget(rules []targetRule, t targetAddress, anyAddr bool) bool {
+	return slices.ContainsFunc(rules, func(r targetRule) bool { return r.matches(t, anyAddr) })
+}
+
+// MatchAuthModule returns the section whose match-target rules match the
+// target. It returns an empty name if no section has match-target
+// rules, and an error if none or several sections match the target.
+func (c *Config) MatchAuthModule(target string) (string, error) {
+	address := parseTargetAddress(target)
+
+	var matched []string
+	hasRules := false
+	for _, name := range slices.Sorted(maps.Keys(c.Sections)) {
+		section := c.Sections[name]
+		if section.MatchTarget == "" {
+			continue
+		}
+		hasRules = true
+		// The rules were validated when the config was loaded.
+		rules, _ := parseTargetRules(section.MatchTarget)
+		if matchesTarget(rules, address, false) {
+			matched = append(matched, name)
+		}
+	}
+	switch {
+	case !hasRules:
+		return "", nil
+	case len(matched) == 0:
+		return "", fmt.Errorf("target %s does not match the match-target of any config section, set auth_module", target)
+	case len(matched) > 1:
+		return "", fmt.Errorf("target %s matches the match-target of several config sections [%s], set auth_module", target, strings.Join(matched, "], ["))
+	}
+	return matched[0], nil
+}
@@ -0,0 +1,85 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/prometheus/common/promslog"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+func TestMatchAuthModule(t *testing.T) {
+	convey.Convey("Match targets to sections", t, func() {
+		dir := writeOptionFiles(t, map[string]string{
+			"my.cnf": `[client]
+user = root
+[client.eu]
+user = eu
+match-target = *.prod-eu.db, 10.20.*
+[client.eu.reporting]
+user = reporting
+[client.us]
+user = us
+match-target = 10.30.0.0/16 [fd00::/8]:3308
+[client.replicas]
+user = replicas
+match-target = :3307
+`,
+		})
+		c := MySqlConfigHandler{}
+		err := c.ReloadConfig(filepath.Join(dir, "my.cnf"), "localhost:3306", "", false, promslog.NewNopLogger())
+		convey.So(err, convey.ShouldBeNil)
+		cfg := c.GetConfig()
+		// The rules are not inherited by child sections.
+		convey.So(cfg.Sections["client.eu.reporting"].MatchTarget, convey.ShouldBeEmpty)
+
+		for target, want := range map[string]string{
+			"db1.PROD-EU.db:3306":  "client.eu",
+			"10.20.1.2:3306":       "client.eu",
+			"10.30.255.1:3306":     "client.us",
+			"[fd00::1]:3308":       "client.us",
+			"db1.prod-us.db:3307":  "client.replicas",
+			"[::ffff:10.30.0.1]:1": "client.us",
+		} {
+			got, err := cfg.MatchAuthModule(target)
+			convey.So(err, convey.ShouldBeNil)
+			convey.So(got, convey.ShouldEqual, want)
+		}
+
+		_, err = cfg.MatchAuthModule("db1.prod-us.db:3306")
+		convey.So(err, convey.ShouldNotBeNil)
+		convey.So(err.Error(), convey.ShouldContainSubstring, "does not match")
+
+		_, err = cfg.MatchAuthModule("10.20.1.2:3307")
+		convey.So(err, convey.ShouldNotBeNil)
+		convey.So(err.Error(), convey.ShouldContainSubstring, "[client.eu], [client.replicas]")
+	})
+
+	convey.Convey("Sections without rules match nothing", t, func() {
+		cfg := &Config{Sections: map[string]MySqlConfig{"client": {User: "root"}}}
+		got, err := cfg.MatchAuthModule("db1:3306")
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(got, convey.ShouldBeEmpty)
+	})
+
+	convey.Convey("Invalid rules", t, func() {
+		for _, rules := range []string{"10.20.0.0/33", "db[1.example", "*.db:port", ":0"} {
+			option, err := MySqlConfig{User: "root", MatchTarget: rules}.validateOptions()
+			convey.So(err, convey.ShouldNotBeNil)
+			convey.So(option, convey.ShouldEqual, "match-target")
+		}
+	})
+}
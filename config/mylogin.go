@@ -0,0 +1,154 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bytes"
+	"crypto/aes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/ini.v1"
+)
+
+// Layout of the login path file written by mysql_config_editor: 4 unused
+// bytes, the 20 byte key, and the lines of the option file, each encrypted
+// with AES-128-ECB and prefixed with its little endian 4 byte length.
+const (
+	loginPathUnusedLength = 4
+	loginPathKeyLength    = 20
+	loginPathHeaderLength = loginPathUnusedLength + loginPathKeyLength
+)
+
+// readLoginPathFile reads and decrypts the login path file. Like the mysql
+// client, it refuses world-writable files.
+func readLoginPathFile(filename string) ([]byte, error) {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return nil, err
+	}
+	if info.Mode().Perm()&0o002 != 0 {
+		return nil, fmt.Errorf("login path file %s is world-writable", filename)
+	}
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := decryptLoginPath(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt login path file %s: %w", filename, err)
+	}
+	return plaintext, nil
+}
+
+// decryptLoginPath decrypts the content of a login path file.
+func decryptLoginPath(content []byte) ([]byte, error) {
+	if len(content) < loginPathHeaderLength {
+		return nil, errors.New("file too short")
+	}
+	// The AES key is the 20 byte key folded to 16 bytes.
+	key := make([]byte, aes.BlockSize)
+	for i, b := range content[loginPathUnusedLength:loginPathHeaderLength] {
+		key[i%aes.BlockSize] ^= b
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	var plaintext bytes.Buffer
+	for rest := content[loginPathHeaderLength:]; len(rest) > 0; {
+		if len(rest) < 4 {
+			return nil, errors.New("truncated line length")
+		}
+		length := int(binary.LittleEndian.Uint32(rest))
+		rest = rest[4:]
+		if length == 0 || length%aes.BlockSize != 0 || length > len(rest) {
+			return nil, fmt.Errorf("invalid line length %d", length)
+		}
+		line := make([]byte, length)
+		for i := 0; i < length; i += aes.BlockSize {
+			block.Decrypt(line[i:i+aes.BlockSize], rest[i:i+aes.BlockSize])
+		}
+		rest = rest[length:]
+
+		// Remove the PKCS#7 padding.
+		padding := int(line[length-1])
+		if padding == 0 || padding > aes.BlockSize || !bytes.Equal(line[length-padding:], bytes.Repeat([]byte{byte(padding)}, padding)) {
+			return nil, errors.New("invalid padding, wrong key")
+		}
+		plaintext.Write(line[:length-padding])
+	}
+	return plaintext.Bytes(), nil
+}
+
+// loginPathSection returns the name of the section of the MySQL config a
+// login path is merged into. The client login path is merged into the client
+// section, every other login path into a child section of it, so that it
+// inherits the client options like the mysql client does for --login-path.
+func loginPathSection(loginPath string) string {
+	if loginPath == "client" {
+		return "client"
+	}
+	return "client." + loginPath
+}
+
+// loginPathValuePrefix marks the values merged from the login path file, so
+// that mapLoginPathValue passes them on verbatim. Environment variables are
+// only expanded in the option files, the login path file is written by
+// mysql_config_editor and its passwords may contain `$`.
+const loginPathValuePrefix = "\x00login-path\x00"
+
+// mapLoginPathValue returns the values merged from the login path file
+// verbatim and maps the other values with mapper.
+func mapLoginPathValue(mapper func(string) string) func(string) string {
+	return func(value string) string {
+		if literal, ok := strings.CutPrefix(value, loginPathValuePrefix); ok {
+			return literal
+		}
+		return mapper(value)
+	}
+}
+
+// mergeLoginPaths merges the options of the login paths in the login path
+// file into cfg. As the mysql client reads the login path file after the
+// other option files, its options take precedence. The values must be read
+// with mapLoginPathValue.
+func mergeLoginPaths(cfg *ini.File, filename string) error {
+	plaintext, err := readLoginPathFile(filename)
+	if err != nil {
+		return err
+	}
+	loginPaths, err := ini.LoadSources(opts, plaintext)
+	if err != nil {
+		return fmt.Errorf("failed to parse login path file %s: %w", filename, err)
+	}
+	for _, loginPath := range loginPaths.Sections() {
+		if loginPath.Name() == ini.DefaultSection {
+			continue
+		}
+		section := cfg.Section(loginPathSection(loginPath.Name()))
+		for _, key := range loginPath.Keys() {
+			// Key would return the key of the parent section if the
+			// section does not have it, NewKey sets it in the section.
+			if _, err := section.NewKey(key.Name(), loginPathValuePrefix+key.Value()); err != nil {
+				return fmt.Errorf("failed to merge login path %s: %w", loginPath.Name(), err)
+			}
+		}
+	}
+	return nil
+}
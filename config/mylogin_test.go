@@ -0,0 +1,137 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bytes"
+	"crypto/aes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/common/promslog"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+// encryptLoginPath encrypts the option file like mysql_config_editor.
+func encryptLoginPath(t *testing.T, plaintext string) []byte {
+	t.Helper()
+	content := make([]byte, loginPathHeaderLength)
+	copy(content[loginPathUnusedLength:], "0123456789abcdefghij")
+	key := make([]byte, aes.BlockSize)
+	for i, b := range content[loginPathUnusedLength:] {
+		key[i%aes.BlockSize] ^= b
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range strings.SplitAfter(plaintext, "\n") {
+		if line == "" {
+			continue
+		}
+		padding := aes.BlockSize - len(line)%aes.BlockSize
+		padded := append([]byte(line), bytes.Repeat([]byte{byte(padding)}, padding)...)
+		content = binary.LittleEndian.AppendUint32(content, uint32(len(padded)))
+		for i := 0; i < len(padded); i += aes.BlockSize {
+			block.Encrypt(padded[i:i+aes.BlockSize], padded[i:i+aes.BlockSize])
+		}
+		content = append(content, padded...)
+	}
+	return content
+}
+
+func writeLoginPathFile(t *testing.T, plaintext string, perm os.FileMode) string {
+	t.Helper()
+	filename := filepath.Join(t.TempDir(), ".mylogin.cnf")
+	if err := os.WriteFile(filename, encryptLoginPath(t, plaintext), perm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(filename, perm); err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
+
+func TestDecryptLoginPath(t *testing.T) {
+	convey.Convey("Decrypt login path file", t, func() {
+		plaintext := "[client]\nuser = \"exporter\"\npassword = \"a long secret password\"\n"
+		got, err := decryptLoginPath(encryptLoginPath(t, plaintext))
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(string(got), convey.ShouldEqual, plaintext)
+	})
+
+	convey.Convey("Reject invalid files", t, func() {
+		content := encryptLoginPath(t, "[client]\nuser = \"exporter\"\n")
+		_, err := decryptLoginPath(content[:10])
+		convey.So(err, convey.ShouldNotBeNil)
+		_, err = decryptLoginPath(content[:len(content)-1])
+		convey.So(err, convey.ShouldNotBeNil)
+
+		// A different key fails to remove the padding.
+		content[loginPathUnusedLength] ^= 0xff
+		_, err = decryptLoginPath(content)
+		convey.So(err, convey.ShouldNotBeNil)
+	})
+}
+
+func TestLoginPathFile(t *testing.T) {
+	convey.Convey("Merge login paths", t, func() {
+		c := MySqlConfigHandler{
+			LoginPathFile: writeLoginPathFile(t, strings.Join([]string{
+				"[client]",
+				`password = "loginpass"`,
+				"[replica]",
+				`user = "replicauser"`,
+				`host = "replica.example.com"`,
+				"port = 3307",
+				"",
+			}, "\n"), 0o600),
+		}
+		err := c.ReloadConfig("testdata/client.cnf", "localhost:3306", "", false, promslog.NewNopLogger())
+		convey.So(err, convey.ShouldBeNil)
+
+		cfg := c.GetConfig()
+		// The login path takes precedence over the my.cnf.
+		convey.So(cfg.Sections["client"].User, convey.ShouldEqual, "root")
+		convey.So(cfg.Sections["client"].Password, convey.ShouldEqual, "loginpass")
+		// Other login paths inherit from the client section.
+		replica := cfg.Sections["client.replica"]
+		convey.So(replica.User, convey.ShouldEqual, "replicauser")
+		convey.So(replica.Host, convey.ShouldEqual, "replica.example.com")
+		convey.So(replica.Port, convey.ShouldEqual, 3307)
+		convey.So(replica.Password, convey.ShouldEqual, "loginpass")
+	})
+
+	convey.Convey("Login path values are not expanded", t, func() {
+		t.Setenv("HOME_PASS", "expanded")
+		c := MySqlConfigHandler{
+			LoginPathFile: writeLoginPathFile(t, "[client]\npassword = \"pa$$w0rd$HOME_PASS\"\n", 0o600),
+		}
+		err := c.ReloadConfig("testdata/client.cnf", "localhost:3306", "", false, promslog.NewNopLogger())
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(c.GetConfig().Sections["client"].Password, convey.ShouldEqual, "pa$$w0rd$HOME_PASS")
+	})
+
+	convey.Convey("Refuse world-writable login path file", t, func() {
+		c := MySqlConfigHandler{
+			LoginPathFile: writeLoginPathFile(t, "[client]\nuser = \"exporter\"\n", 0o666),
+		}
+		err := c.ReloadConfig("testdata/client.cnf", "localhost:3306", "", false, promslog.NewNopLogger())
+		convey.So(err, convey.ShouldNotBeNil)
+		convey.So(err.Error(), convey.ShouldContainSubstring, "world-writable")
+	})
+}
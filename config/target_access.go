@@ -0,0 +1,140 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"slices"
+	"strings"
+)
+
+// Reasons of TargetRejectedError.
+const (
+	TargetDenied               = "denied"
+	TargetNotAllowed           = "not_allowed"
+	TargetNotAllowedAuthModule = "not_allowed_auth_module"
+	TargetUnresolved           = "unresolved"
+)
+
+// lookupNetIP resolves host names to check them against CIDR rules.
+var lookupNetIP = net.DefaultResolver.LookupNetIP
+
+// TargetAccess restricts the targets requested with `/probe?target=` and
+// `/metrics?target=`, so that the exporter does not send the credentials of
+// its auth modules to any server. The rules have the syntax of the
+// match-target option of the my.cnf.
+type TargetAccess struct {
+	// Allow are the rules of the allowed targets, all targets are allowed
+	// if empty.
+	Allow []string `yaml:"allow"`
+	// Deny are the rules of the rejected targets, taking precedence over
+	// Allow.
+	Deny []string `yaml:"deny"`
+	// RequireAuthModuleAllowlist rejects the targets of auth modules
+	// without allow-target.
+	RequireAuthModuleAllowlist bool `yaml:"require_auth_module_allowlist"`
+}
+
+// TargetRejectedError is returned for targets which are not allowed.
+type TargetRejectedError struct {
+	Target     string
+	AuthModule string
+	// Reason is one of TargetDenied, TargetNotAllowed,
+	// TargetNotAllowedAuthModule and TargetUnresolved.
+	Reason string
+}
+
+func (e *TargetRejectedError) Error() string {
+	switch e.Reason {
+	case TargetDenied:
+		return fmt.Sprintf("target %s is denied", e.Target)
+	case TargetNotAllowed:
+		return fmt.Sprintf("target %s is not allowed", e.Target)
+	case TargetUnresolved:
+		return fmt.Sprintf("target %s could not be resolved to check it against the CIDR rules", e.Target)
+	default:
+		return fmt.Sprintf("target %s is not allowed for auth module [%s]", e.Target, e.AuthModule)
+	}
+}
+
+func (a TargetAccess) validate() error {
+	for _, rule := range slices.Concat(a.Allow, a.Deny) {
+		if _, err := parseTargetRule(rule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckTarget returns a *TargetRejectedError if the target requested with
+// the auth module is not allowed, either by the access rules or by the
+// allow-target rules of the section of the auth module. Host names are
+// resolved if there are CIDR rules, a CIDR denies a host name if it
+// contains any of its addresses and allows it if it contains all of them.
+// Host names which can not be resolved are rejected. The addresses a host
+// name was resolved to are returned, connections to the target must only be
+// made to them, so that the host name can not resolve to other addresses
+// in the meantime.
+func (a TargetAccess) CheckTarget(ctx context.Context, target, authModule string, section MySqlConfig) ([]netip.Addr, error) {
+	// The rules were validated when the configs were loaded.
+	parse := func(rules []string) []targetRule {
+		parsed, _ := parseTargetRules(strings.Join(rules, ","))
+		return parsed
+	}
+	allow, deny := parse(a.Allow), parse(a.Deny)
+	sectionAllow := parse([]string{section.AllowTarget})
+
+	rejected := &TargetRejectedError{Target: target, AuthModule: authModule}
+	address := parseTargetAddress(target)
+	var resolved []netip.Addr
+	if address.socket == "" && len(address.addrs) == 0 && hasCIDR(allow, deny, sectionAllow) {
+		addrs, err := lookupNetIP(ctx, "ip", address.host)
+		if err != nil || len(addrs) == 0 {
+			rejected.Reason = TargetUnresolved
+			return nil, rejected
+		}
+		for _, addr := range addrs {
+			resolved = append(resolved, addr.Unmap())
+		}
+		address.addrs = resolved
+	}
+
+	switch {
+	case matchesTarget(deny, address, true):
+		rejected.Reason = TargetDenied
+	case len(allow) > 0 && !matchesTarget(allow, address, false):
+		rejected.Reason = TargetNotAllowed
+	case len(sectionAllow) == 0 && a.RequireAuthModuleAllowlist,
+		len(sectionAllow) > 0 && !matchesTarget(sectionAllow, address, false):
+		rejected.Reason = TargetNotAllowedAuthModule
+	default:
+		return resolved, nil
+	}
+	return nil, rejected
+}
+
+// hasCIDR returns whether any of the rules is a CIDR.
+func hasCIDR(rules ...[]targetRule) bool {
+	for _, rs := range rules {
+		for _, r := range rs {
+			if r.prefix.IsValid() {
+				return true
+			}
+		}
+	}
+	return false
+}
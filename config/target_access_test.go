@@ -0,0 +1,105 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"testing"
+
+	"github.com/smartystreets/goconvey/convey"
+)
+
+// checkTargetReason returns the reason the target is rejected, or an empty
+// string if it is allowed.
+func checkTargetReason(access TargetAccess, target string, section MySqlConfig) string {
+	_, err := access.CheckTarget(context.Background(), target, "client", section)
+	var rejected *TargetRejectedError
+	if errors.As(err, &rejected) {
+		return rejected.Reason
+	}
+	return ""
+}
+
+func TestCheckTarget(t *testing.T) {
+	defaultLookupNetIP := lookupNetIP
+	lookupNetIP = func(_ context.Context, _, host string) ([]netip.Addr, error) {
+		switch host {
+		case "db1.example.com":
+			return []netip.Addr{netip.MustParseAddr("10.20.1.1")}, nil
+		case "db1.PROD.db":
+			return []netip.Addr{netip.MustParseAddr("10.40.1.1")}, nil
+		case "metadata.example.com":
+			return []netip.Addr{netip.MustParseAddr("10.20.1.2"), netip.MustParseAddr("::ffff:169.254.169.254")}, nil
+		}
+		return nil, errors.New("no such host")
+	}
+	defer func() { lookupNetIP = defaultLookupNetIP }()
+
+	convey.Convey("Allow and deny rules", t, func() {
+		access := TargetAccess{
+			Allow: []string{"10.20.0.0/16", "*.prod.db", "/run/mysqld/*.sock"},
+			Deny:  []string{"169.254.0.0/16", "10.20.99.0/24"},
+		}
+		for target, want := range map[string]string{
+			"10.20.1.1:3306":                 "",
+			"db1.example.com:3306":           "",
+			"db1.PROD.db:3306":               "",
+			"unix:///run/mysqld/mysqld.sock": "",
+			"10.20.99.1:3306":                TargetDenied,
+			"metadata.example.com:3306":      TargetDenied,
+			"169.254.169.254:80":             TargetDenied,
+			"10.30.1.1:3306":                 TargetNotAllowed,
+			"attacker.example:3306":          TargetUnresolved,
+			"unix:///tmp/mysql.sock":         TargetNotAllowed,
+		} {
+			convey.So(checkTargetReason(access, target, MySqlConfig{}), convey.ShouldEqual, want)
+		}
+	})
+
+	convey.Convey("Auth module allowlists", t, func() {
+		section := MySqlConfig{AllowTarget: "*.example.com:3306"}
+		convey.So(checkTargetReason(TargetAccess{}, "db1.example.com:3306", section), convey.ShouldBeEmpty)
+		convey.So(checkTargetReason(TargetAccess{}, "db1.example.com:3307", section), convey.ShouldEqual, TargetNotAllowedAuthModule)
+		convey.So(checkTargetReason(TargetAccess{}, "db1:3306", MySqlConfig{}), convey.ShouldBeEmpty)
+
+		required := TargetAccess{RequireAuthModuleAllowlist: true}
+		convey.So(checkTargetReason(required, "db1.example.com:3306", section), convey.ShouldBeEmpty)
+		convey.So(checkTargetReason(required, "db1:3306", MySqlConfig{}), convey.ShouldEqual, TargetNotAllowedAuthModule)
+
+		_, err := required.CheckTarget(context.Background(), "db1:3306", "client.orders", MySqlConfig{})
+		convey.So(err.Error(), convey.ShouldEqual, "target db1:3306 is not allowed for auth module [client.orders]")
+	})
+
+	convey.Convey("Host names which can not be resolved are rejected with CIDR rules", t, func() {
+		convey.So(checkTargetReason(TargetAccess{Deny: []string{"169.254.0.0/16"}}, "rebind.example:3306", MySqlConfig{}), convey.ShouldEqual, TargetUnresolved)
+		convey.So(checkTargetReason(TargetAccess{Allow: []string{"*.example:3306"}}, "rebind.example:3306", MySqlConfig{}), convey.ShouldBeEmpty)
+		convey.So(checkTargetReason(TargetAccess{}, "rebind.example:3306", MySqlConfig{AllowTarget: "10.0.0.0/8"}), convey.ShouldEqual, TargetUnresolved)
+	})
+
+	convey.Convey("The resolved addresses are returned", t, func() {
+		access := TargetAccess{Deny: []string{"169.254.0.0/16"}}
+		addrs, err := access.CheckTarget(context.Background(), "db1.example.com:3306", "client", MySqlConfig{})
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(addrs, convey.ShouldResemble, []netip.Addr{netip.MustParseAddr("10.20.1.1")})
+
+		addrs, err = access.CheckTarget(context.Background(), "10.20.1.1:3306", "client", MySqlConfig{})
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(addrs, convey.ShouldBeNil)
+		addrs, err = TargetAccess{Allow: []string{"*.example.com"}}.CheckTarget(context.Background(), "db1.example.com:3306", "client", MySqlConfig{})
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(addrs, convey.ShouldBeNil)
+	})
+}
@@ -0,0 +1,320 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"bytes"
+	"cmp"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/mysqld_exporter/collector"
+)
+
+// Values of ssl-mode, with the same semantics as for the mysql client.
+const (
+	sslModeDisabled       = "DISABLED"
+	sslModePreferred      = "PREFERRED"
+	sslModeRequired       = "REQUIRED"
+	sslModeVerifyCA       = "VERIFY_CA"
+	sslModeVerifyIdentity = "VERIFY_IDENTITY"
+)
+
+var sslModes = []string{sslModeDisabled, sslModePreferred, sslModeRequired, sslModeVerifyCA, sslModeVerifyIdentity}
+
+var tlsClientCertNotAfter = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: "mysql",
+	Subsystem: "exporter",
+	Name:      "tls_client_cert_not_after_seconds",
+	Help:      "Expiry of the TLS client certificate presented to the MySQL server, in seconds since the epoch.",
+}, []string{"auth_module"})
+
+// tlsFiles caches the TLS material parsed from files until the files change,
+// so that new connections use rotated certificates without a config reload.
+var tlsFiles = struct {
+	sync.Mutex
+	entries map[string]*tlsFile
+}{entries: map[string]*tlsFile{}}
+
+type tlsFile struct {
+	versions []fileVersion
+	value    any
+}
+
+// fileVersion identifies the content of a file by its modification time and
+// size.
+type fileVersion struct {
+	modTime time.Time
+	size    int64
+}
+
+// openSSLCipherNames maps the OpenSSL names of the cipher suites up to TLS
+// 1.2 supported by Go, as used by the mysql client for ssl-cipher, to their
+// IANA names.
+var openSSLCipherNames = map[string]string{
+	"ECDHE-ECDSA-AES128-GCM-SHA256": "TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256",
+	"ECDHE-RSA-AES128-GCM-SHA256":   "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256",
+	"ECDHE-ECDSA-AES256-GCM-SHA384": "TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384",
+	"ECDHE-RSA-AES256-GCM-SHA384":   "TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384",
+	"ECDHE-ECDSA-CHACHA20-POLY1305": "TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256",
+	"ECDHE-RSA-CHACHA20-POLY1305":   "TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256",
+	"ECDHE-ECDSA-AES128-SHA":        "TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA",
+	"ECDHE-RSA-AES128-SHA":          "TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA",
+	"ECDHE-ECDSA-AES256-SHA":        "TLS_ECDHE_ECDSA_WITH_AES_256_CBC_SHA",
+	"ECDHE-RSA-AES256-SHA":          "TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA",
+}
+
+// sslMode returns the ssl-mode of the section. Without ssl-mode, the custom
+// TLS configuration verifies the server like the tls=true DSN parameter,
+// unless verification is disabled.
+func (m MySqlConfig) sslMode() string {
+	switch {
+	case m.SslMode != "":
+		return strings.ToUpper(m.SslMode)
+	case m.TlsInsecureSkipVerify:
+		return sslModeRequired
+	default:
+		return sslModeVerifyIdentity
+	}
+}
+
+// parseCipherList parses the colon separated cipher suites of ssl-cipher, by
+// their OpenSSL or IANA names. Only secure cipher suites are supported.
+func parseCipherList(list string) ([]uint16, error) {
+	var ids []uint16
+	for name := range strings.SplitSeq(list, ":") {
+		if ianaName, ok := openSSLCipherNames[name]; ok {
+			name = ianaName
+		}
+		i := slices.IndexFunc(tls.CipherSuites(), func(s *tls.CipherSuite) bool {
+			return s.Name == name && slices.Contains(s.SupportedVersions, tls.VersionTLS12)
+		})
+		if i < 0 {
+			return nil, fmt.Errorf("unsupported cipher %q", name)
+		}
+		ids = append(ids, tls.CipherSuites()[i].ID)
+	}
+	return ids, nil
+}
+
+// parseTLS13CipherSuites parses the colon separated TLS 1.3 cipher suites of
+// tls-ciphersuites.
+func parseTLS13CipherSuites(list string) ([]uint16, error) {
+	var ids []uint16
+	for name := range strings.SplitSeq(list, ":") {
+		i := slices.IndexFunc(tls.CipherSuites(), func(s *tls.CipherSuite) bool {
+			return s.Name == name && slices.Equal(s.SupportedVersions, []uint16{tls.VersionTLS13})
+		})
+		if i < 0 {
+			return nil, fmt.Errorf("unsupported TLS 1.3 cipher suite %q", name)
+		}
+		ids = append(ids, tls.CipherSuites()[i].ID)
+	}
+	return ids, nil
+}
+
+// loadTLSFiles returns the value parsed from the files, parsing them again
+// only once one of them changed.
+func loadTLSFiles[T any](parse func() (T, error), filenames ...string) (T, error) {
+	var zero T
+	versions := make([]fileVersion, 0, len(filenames))
+	for _, filename := range filenames {
+		info, err := os.Stat(filename)
+		if err != nil {
+			return zero, err
+		}
+		versions = append(versions, fileVersion{modTime: info.ModTime(), size: info.Size()})
+	}
+
+	key := fmt.Sprintf("%T:%s", zero, strings.Join(filenames, ":"))
+	tlsFiles.Lock()
+	defer tlsFiles.Unlock()
+	if entry, ok := tlsFiles.entries[key]; ok && slices.Equal(entry.versions, versions) {
+		return entry.value.(T), nil
+	}
+	value, err := parse()
+	if err != nil {
+		return zero, err
+	}
+	tlsFiles.entries[key] = &tlsFile{versions: versions, value: value}
+	return value, nil
+}
+
+// rootCAs returns the CA certificates of ssl-ca, or nil to verify the server
+// against the system roots.
+func (m MySqlConfig) rootCAs() (*x509.CertPool, error) {
+	if m.SslCa == "" {
+		return nil, nil
+	}
+	return loadTLSFiles(func() (*x509.CertPool, error) {
+		pemCA, err := os.ReadFile(m.SslCa)
+		if err != nil {
+			return nil, err
+		}
+		caBundle := x509.NewCertPool()
+		if ok := caBundle.AppendCertsFromPEM(pemCA); !ok {
+			return nil, fmt.Errorf("failed parse pem-encoded CA certificates from %s", m.SslCa)
+		}
+		return caBundle, nil
+	}, m.SslCa)
+}
+
+// clientCertificate returns the keypair of ssl-cert and ssl-key, updating the
+// expiry metric of the auth module.
+func (m MySqlConfig) clientCertificate(authModule string) (*tls.Certificate, error) {
+	keypair, err := loadTLSFiles(func() (*tls.Certificate, error) {
+		keypair, err := tls.LoadX509KeyPair(m.SslCert, m.SslKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse pem-encoded SSL cert %s or SSL key %s: %w",
+				m.SslCert, m.SslKey, err)
+		}
+		return &keypair, nil
+	}, m.SslCert, m.SslKey)
+	if err != nil {
+		return nil, err
+	}
+	tlsClientCertNotAfter.WithLabelValues(authModule).Set(float64(keypair.Leaf.NotAfter.Unix()))
+	return keypair, nil
+}
+
+// revocationLists returns the certificate revocation lists of ssl-crl.
+func (m MySqlConfig) revocationLists() ([]*x509.RevocationList, error) {
+	if m.SslCrl == "" {
+		return nil, nil
+	}
+	return loadTLSFiles(func() ([]*x509.RevocationList, error) {
+		return loadCRLs(m.SslCrl)
+	}, m.SslCrl)
+}
+
+// loadCRLs reads the certificate revocation lists of the file, either PEM
+// encoded or a single DER encoded list.
+func loadCRLs(filename string) ([]*x509.RevocationList, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var crls []*x509.RevocationList
+	for rest := content; ; {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "X509 CRL" {
+			continue
+		}
+		crl, err := x509.ParseRevocationList(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse CRL from %s: %w", filename, err)
+		}
+		crls = append(crls, crl)
+	}
+	if len(crls) == 0 {
+		crl, err := x509.ParseRevocationList(content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse CRL from %s: %w", filename, err)
+		}
+		crls = append(crls, crl)
+	}
+	return crls, nil
+}
+
+// checkRevoked returns an error if a certificate of the chains was revoked by
+// a CRL signed by its issuer.
+func checkRevoked(chains [][]*x509.Certificate, crls []*x509.RevocationList) error {
+	for _, chain := range chains {
+		for i := 0; i < len(chain)-1; i++ {
+			cert, issuer := chain[i], chain[i+1]
+			for _, crl := range crls {
+				if !bytes.Equal(crl.RawIssuer, cert.RawIssuer) || crl.CheckSignatureFrom(issuer) != nil {
+					continue
+				}
+				for _, entry := range crl.RevokedCertificateEntries {
+					if entry.SerialNumber.Cmp(cert.SerialNumber) == 0 {
+						return fmt.Errorf("certificate %q was revoked", cert.Subject)
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// verifyConnection returns the function verifying the server once the
+// handshake completed. The server is verified here rather than by Go, with
+// the CA certificates and CRLs as they are at the time of the connection:
+// the certificate chain for VERIFY_CA, along with the server name for
+// VERIFY_IDENTITY, which is the server name of the connection unless
+// serverName is set. It also rejects TLS 1.3 cipher suites not in
+// tls13Suites, as Go does not allow configuring them. Its errors are
+// collector.TLSVerificationError, to be counted as TLS errors.
+func (m MySqlConfig) verifyConnection(serverName string, tls13Suites []uint16) func(tls.ConnectionState) error {
+	verify := m.verifyConnectionState(serverName, tls13Suites)
+	return func(cs tls.ConnectionState) error {
+		if err := verify(cs); err != nil {
+			return &collector.TLSVerificationError{Err: err}
+		}
+		return nil
+	}
+}
+
+// verifyConnectionState returns the verification of verifyConnection,
+// returning its errors as they are.
+func (m MySqlConfig) verifyConnectionState(serverName string, tls13Suites []uint16) func(tls.ConnectionState) error {
+	mode := m.sslMode()
+	return func(cs tls.ConnectionState) error {
+		if cs.Version == tls.VersionTLS13 && tls13Suites != nil && !slices.Contains(tls13Suites, cs.CipherSuite) {
+			return fmt.Errorf("TLS 1.3 cipher suite %s is not allowed by tls-ciphersuites", tls.CipherSuiteName(cs.CipherSuite))
+		}
+		if mode != sslModeVerifyCA && mode != sslModeVerifyIdentity {
+			return nil
+		}
+		if len(cs.PeerCertificates) == 0 {
+			return errors.New("server did not present a certificate")
+		}
+		opts := x509.VerifyOptions{Intermediates: x509.NewCertPool()}
+		if mode == sslModeVerifyIdentity {
+			opts.DNSName = cmp.Or(serverName, cs.ServerName)
+			if opts.DNSName == "" {
+				return errors.New("no server name to verify the certificate against, set tls-server-name")
+			}
+		}
+		for _, cert := range cs.PeerCertificates[1:] {
+			opts.Intermediates.AddCert(cert)
+		}
+		var err error
+		if opts.Roots, err = m.rootCAs(); err != nil {
+			return err
+		}
+		chains, err := cs.PeerCertificates[0].Verify(opts)
+		if err != nil {
+			return err
+		}
+		crls, err := m.revocationLists()
+		if err != nil {
+			return err
+		}
+		return checkRevoked(chains, crls)
+	}
+}
@@ -0,0 +1,386 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/mysqld_exporter/collector"
+	"github.com/smartystreets/goconvey/convey"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCA(t *testing.T, name string) testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testCA{cert: cert, key: key}
+}
+
+// issue returns a server certificate for the DNS name.
+func (ca testCA) issue(t *testing.T, serial int64, dnsName string) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: dnsName},
+		DNSNames:     []string{dnsName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+// writeCRL writes a CRL revoking the serial numbers and returns its path.
+func (ca testCA) writeCRL(t *testing.T, serials ...int64) string {
+	t.Helper()
+	var entries []x509.RevocationListEntry
+	for _, serial := range serials {
+		entries = append(entries, x509.RevocationListEntry{SerialNumber: big.NewInt(serial), RevocationTime: time.Now()})
+	}
+	der, err := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+		Number:                    big.NewInt(1),
+		ThisUpdate:                time.Now().Add(-time.Hour),
+		NextUpdate:                time.Now().Add(time.Hour),
+		RevokedCertificateEntries: entries,
+	}, ca.cert, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	filename := filepath.Join(t.TempDir(), "crl.pem")
+	if err := os.WriteFile(filename, pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
+
+func (ca testCA) writeCert(t *testing.T) string {
+	t.Helper()
+	filename := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(filename, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.cert.Raw}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
+
+// writeKeyPair writes the certificate and its key and returns their paths.
+func writeKeyPair(t *testing.T, dir string, cert tls.Certificate) (string, string) {
+	t.Helper()
+	key, err := x509.MarshalPKCS8PrivateKey(cert.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: key}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+// touch sets the modification time of the files to a later time, as files
+// rewritten within the same test may keep their modification time.
+func touch(t *testing.T, filenames ...string) {
+	t.Helper()
+	later := time.Now().Add(time.Minute)
+	for _, filename := range filenames {
+		if err := os.Chtimes(filename, later, later); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// handshake connects with the TLS configuration of the section to a server
+// presenting the certificate, with the server name of the connection set to
+// the host like the collector does.
+func handshake(t *testing.T, section MySqlConfig, serverCert tls.Certificate, host string) error {
+	t.Helper()
+	cfg, err := section.tlsConfig("client")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.ServerName = host
+	return dial(t, cfg, &tls.Config{Certificates: []tls.Certificate{serverCert}})
+}
+
+// dial connects with the client TLS configuration to a server with the
+// server TLS configuration.
+func dial(t *testing.T, cfg, serverCfg *tls.Config) error {
+	t.Helper()
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", serverCfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_ = conn.(*tls.Conn).Handshake()
+	}()
+	conn, err := tls.Dial("tcp", listener.Addr().String(), cfg)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+func TestSSLMode(t *testing.T) {
+	ca := newTestCA(t, "test CA")
+	caFile := ca.writeCert(t)
+	serverCert := ca.issue(t, 2, "mysql.example")
+	untrustedCert := newTestCA(t, "other CA").issue(t, 2, "mysql.example")
+
+	convey.Convey("ssl-mode verification matrix", t, func() {
+		for _, tc := range []struct {
+			mode    string
+			cert    tls.Certificate
+			host    string
+			success bool
+		}{
+			{sslModePreferred, untrustedCert, "db1.example", true},
+			{sslModeRequired, untrustedCert, "db1.example", true},
+			{sslModeVerifyCA, serverCert, "db1.example", true},
+			{sslModeVerifyCA, untrustedCert, "mysql.example", false},
+			{sslModeVerifyIdentity, serverCert, "mysql.example", true},
+			{sslModeVerifyIdentity, serverCert, "db1.example", false},
+			{sslModeVerifyIdentity, untrustedCert, "mysql.example", false},
+		} {
+			err := handshake(t, MySqlConfig{SslMode: tc.mode, SslCa: caFile}, tc.cert, tc.host)
+			convey.So(err == nil, convey.ShouldEqual, tc.success)
+		}
+	})
+
+	convey.Convey("Revoked certificates are rejected", t, func() {
+		for _, mode := range []string{sslModeVerifyCA, sslModeVerifyIdentity} {
+			section := MySqlConfig{SslMode: mode, SslCa: caFile, SslCrl: ca.writeCRL(t, 2)}
+			err := handshake(t, section, serverCert, "mysql.example")
+			convey.So(err, convey.ShouldNotBeNil)
+			convey.So(err.Error(), convey.ShouldContainSubstring, "revoked")
+			convey.So(isTLSVerificationError(err), convey.ShouldBeTrue)
+
+			section.SslCrl = ca.writeCRL(t, 3)
+			convey.So(handshake(t, section, serverCert, "mysql.example"), convey.ShouldBeNil)
+		}
+	})
+
+	convey.Convey("Cipher restrictions", t, func() {
+		section := MySqlConfig{
+			SslMode:       sslModeRequired,
+			SslCipher:     "ECDHE-ECDSA-AES256-GCM-SHA384:TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256",
+			TlsMaxVersion: "TLSv1.2",
+		}
+		cfg, err := section.tlsConfig("client")
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(cfg.CipherSuites, convey.ShouldResemble, []uint16{
+			tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,
+		})
+		convey.So(handshake(t, section, serverCert, "mysql.example"), convey.ShouldBeNil)
+
+		// TLS 1.3 cipher suites are checked after the handshake.
+		verify := MySqlConfig{SslMode: sslModeRequired}.verifyConnection("", []uint16{tls.TLS_CHACHA20_POLY1305_SHA256})
+		convey.So(verify(tls.ConnectionState{Version: tls.VersionTLS13, CipherSuite: tls.TLS_CHACHA20_POLY1305_SHA256}), convey.ShouldBeNil)
+		err = verify(tls.ConnectionState{Version: tls.VersionTLS13, CipherSuite: tls.TLS_AES_128_GCM_SHA256})
+		convey.So(err, convey.ShouldNotBeNil)
+		convey.So(isTLSVerificationError(err), convey.ShouldBeTrue)
+		convey.So(verify(tls.ConnectionState{Version: tls.VersionTLS12, CipherSuite: tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256}), convey.ShouldBeNil)
+	})
+}
+
+func TestTLSReload(t *testing.T) {
+	ca := newTestCA(t, "test CA")
+	dir := t.TempDir()
+
+	convey.Convey("Rotated client certificates are presented", t, func() {
+		certFile, keyFile := writeKeyPair(t, dir, ca.issue(t, 10, "exporter"))
+		section := MySqlConfig{SslMode: sslModeRequired, SslCert: certFile, SslKey: keyFile}
+		cfg, err := section.tlsConfig("client.rotation")
+		convey.So(err, convey.ShouldBeNil)
+
+		// The server verifies the client certificate after the client
+		// completed the handshake.
+		presented := make(chan int64, 2)
+		serverCfg := &tls.Config{
+			Certificates: []tls.Certificate{ca.issue(t, 2, "mysql.example")},
+			ClientAuth:   tls.RequireAnyClientCert,
+			VerifyConnection: func(cs tls.ConnectionState) error {
+				presented <- cs.PeerCertificates[0].SerialNumber.Int64()
+				return nil
+			},
+		}
+		convey.So(dial(t, cfg, serverCfg), convey.ShouldBeNil)
+		convey.So(<-presented, convey.ShouldEqual, 10)
+
+		rotated := ca.issue(t, 11, "exporter")
+		writeKeyPair(t, dir, rotated)
+		touch(t, certFile, keyFile)
+		convey.So(dial(t, cfg, serverCfg), convey.ShouldBeNil)
+		convey.So(<-presented, convey.ShouldEqual, 11)
+
+		leaf, err := x509.ParseCertificate(rotated.Certificate[0])
+		convey.So(err, convey.ShouldBeNil)
+		notAfter := testutil.ToFloat64(tlsClientCertNotAfter.WithLabelValues("client.rotation"))
+		convey.So(notAfter, convey.ShouldEqual, float64(leaf.NotAfter.Unix()))
+	})
+
+	convey.Convey("Rotated CA certificates verify the server", t, func() {
+		caFile := ca.writeCert(t)
+		section := MySqlConfig{SslMode: sslModeVerifyIdentity, SslCa: caFile}
+		cfg, err := section.tlsConfig("client")
+		convey.So(err, convey.ShouldBeNil)
+		cfg.ServerName = "mysql.example"
+		oldCert := ca.issue(t, 2, "mysql.example")
+		convey.So(dial(t, cfg, &tls.Config{Certificates: []tls.Certificate{oldCert}}), convey.ShouldBeNil)
+
+		newCA := newTestCA(t, "new CA")
+		newCert := newCA.issue(t, 2, "mysql.example")
+		convey.So(dial(t, cfg, &tls.Config{Certificates: []tls.Certificate{newCert}}), convey.ShouldNotBeNil)
+		content, err := os.ReadFile(newCA.writeCert(t))
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(os.WriteFile(caFile, content, 0o600), convey.ShouldBeNil)
+		touch(t, caFile)
+		convey.So(dial(t, cfg, &tls.Config{Certificates: []tls.Certificate{newCert}}), convey.ShouldBeNil)
+		convey.So(dial(t, cfg, &tls.Config{Certificates: []tls.Certificate{oldCert}}), convey.ShouldNotBeNil)
+	})
+
+	convey.Convey("Targets share the TLS configuration of the section", t, func() {
+		section := MySqlConfig{User: "usr", Password: "pwd", SslCa: ca.writeCert(t)}
+		for _, target := range []string{"db1:3306", "db2:3306"} {
+			dsn, err := section.FormDSN(target, "client.shared")
+			convey.So(err, convey.ShouldBeNil)
+			convey.So(dsn, convey.ShouldEqual, "usr:pwd@tcp("+target+")/?tls=client.shared")
+		}
+		parsed, err := mysql.ParseDSN("usr:pwd@tcp(db1:3306)/?tls=client.shared%40db1")
+		convey.So(err, convey.ShouldNotBeNil)
+		convey.So(parsed, convey.ShouldBeNil)
+
+		// The server name of the connection is verified.
+		verify := MySqlConfig{SslMode: sslModeVerifyIdentity, SslCa: ca.writeCert(t)}.verifyConnection("", nil)
+		serverCert, err := x509.ParseCertificate(ca.issue(t, 2, "mysql.example").Certificate[0])
+		convey.So(err, convey.ShouldBeNil)
+		convey.So(verify(tls.ConnectionState{ServerName: "mysql.example", PeerCertificates: []*x509.Certificate{serverCert}}), convey.ShouldBeNil)
+		convey.So(verify(tls.ConnectionState{ServerName: "db1", PeerCertificates: []*x509.Certificate{serverCert}}), convey.ShouldNotBeNil)
+		err = verify(tls.ConnectionState{PeerCertificates: []*x509.Certificate{serverCert}})
+		convey.So(err, convey.ShouldNotBeNil)
+		convey.So(err.Error(), convey.ShouldContainSubstring, "no server name")
+		convey.So(isTLSVerificationError(err), convey.ShouldBeTrue)
+
+		err = verify(tls.ConnectionState{ServerName: "mysql.example"})
+		convey.So(err, convey.ShouldNotBeNil)
+		convey.So(err.Error(), convey.ShouldContainSubstring, "did not present a certificate")
+		convey.So(isTLSVerificationError(err), convey.ShouldBeTrue)
+	})
+}
+
+// isTLSVerificationError returns whether the collectors count err as a TLS
+// error.
+func isTLSVerificationError(err error) bool {
+	var verifyErr *collector.TLSVerificationError
+	return errors.As(err, &verifyErr)
+}
+
+func TestSSLModeDSN(t *testing.T) {
+	convey.Convey("DSN of ssl-mode", t, func() {
+		for mode, want := range map[string]string{
+			"disabled":  "usr:pwd@tcp(server3:3306)/?tls=false",
+			"PREFERRED": "usr:pwd@tcp(server3:3306)/?allowFallbackToPlaintext=true&tls=client_ssl_mode",
+			"REQUIRED":  "usr:pwd@tcp(server3:3306)/?tls=client_ssl_mode",
+		} {
+			// The ssl-mode takes precedence over disabling verification.
+			section := MySqlConfig{User: "usr", Password: "pwd", SslMode: mode, TlsInsecureSkipVerify: true}
+			dsn, err := section.FormDSN("server3:3306", "client_ssl_mode")
+			convey.So(err, convey.ShouldBeNil)
+			convey.So(dsn, convey.ShouldEqual, want)
+		}
+	})
+}
+
+func TestValidateSSLOptions(t *testing.T) {
+	convey.Convey("Valid TLS options", t, func() {
+		for _, section := range []MySqlConfig{
+			{User: "usr", SslMode: "verify_ca", SslCa: "ca.pem", SslCrl: "crl.pem"},
+			{User: "usr", SslMode: "REQUIRED", SslCipher: "ECDHE-RSA-AES128-GCM-SHA256", TlsCiphersuites: "TLS_AES_256_GCM_SHA384:TLS_CHACHA20_POLY1305_SHA256"},
+			{User: "usr", SslCa: "ca.pem", SslCrl: "crl.pem"},
+		} {
+			convey.So(section.validateConfig(), convey.ShouldBeNil)
+		}
+	})
+
+	convey.Convey("Invalid TLS options", t, func() {
+		for section, option := range map[MySqlConfig]string{
+			{User: "usr", SslMode: "VERIFY"}:                                     "ssl-mode",
+			{User: "usr", SslMode: "REQUIRED", Tls: "true"}:                      "ssl-mode",
+			{User: "usr", SslMode: "VERIFY_IDENTITY"}:                            "ssl-mode",
+			{User: "usr", SslMode: "REQUIRED", SslCrl: "crl.pem"}:                "ssl-crl",
+			{User: "usr", SslCrl: "crl.pem", TlsInsecureSkipVerify: true}:        "ssl-crl",
+			{User: "usr", SslCipher: "ECDHE-RSA-AES128-GCM-SHA256:RC4-SHA"}:      "ssl-cipher",
+			{User: "usr", SslCipher: "TLS_AES_128_GCM_SHA256"}:                   "ssl-cipher",
+			{User: "usr", TlsCiphersuites: "TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA"}: "tls-ciphersuites",
+		} {
+			got, err := section.validateOptions()
+			convey.So(err, convey.ShouldNotBeNil)
+			convey.So(got, convey.ShouldEqual, option)
+		}
+	})
+}
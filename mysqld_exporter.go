@@ -14,21 +14,26 @@
 package main
 
 import (
+	"cmp"
 	"context"
 	"fmt"
 	"log/slog"
 	"maps"
 	"net/http"
 	"os"
+	"os/signal"
 	"slices"
 	"strconv"
 	"strings"
+	"sync/atomic"
+	"syscall"
 	"time"
 
 	"github.com/alecthomas/kingpin/v2"
 	"github.com/prometheus/client_golang/prometheus"
 	versioncollector "github.com/prometheus/client_golang/prometheus/collectors/version"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/common/model"
 	"github.com/prometheus/common/promslog"
 	"github.com/prometheus/common/promslog/flag"
 	"github.com/prometheus/common/version"
@@ -36,6 +41,7 @@ import (
 	webflag "github.com/prometheus/exporter-toolkit/web/kingpinflag"
 
 	"github.com/prometheus/mysqld_exporter/collector"
+	collectorflag "github.com/prometheus/mysqld_exporter/collector/kingpinflag"
 	"github.com/prometheus/mysqld_exporter/config"
 )
 
@@ -52,6 +58,10 @@ var (
 		"config.my-cnf",
 		"Path to .my.cnf file to read MySQL credentials from.",
 	).Default(".my.cnf").String()
+	configMyLoginCnf = kingpin.Flag(
+		"config.mylogin-cnf",
+		"Path to a .mylogin.cnf file written by mysql_config_editor to read MySQL credentials from. Its login paths take precedence over config.my-cnf.",
+	).Default("").String()
 	mysqldAddress = kingpin.Flag(
 		"mysqld.address",
 		"Address to use for connecting to MySQL",
@@ -82,52 +92,110 @@ var (
 	).Default("0").Int()
 	exporterMaxOpenConns = kingpin.Flag(
 		"exporter.max_open_connections",
-		"Maximum number of open connections to the database per scrape. Must be >= 1.",
+		"Maximum number of open connections to the database per target. Must be >= 1.",
 	).Default("2").Int()
-	toolkitFlags = webflag.AddFlags(kingpin.CommandLine, ":9104")
-	c            = config.MySqlConfigHandler{
+	exporterConnectionIdleTimeout = kingpin.Flag(
+		"exporter.connection_idle_timeout",
+		"Keep the connections to a target open between scrapes and close them once the target was not scraped for this duration. 0 opens new connections on every scrape.",
+	).Default("5m").Duration()
+	exporterScrapeErrorsIdleTimeout = kingpin.Flag(
+		"exporter.scrape_errors_idle_timeout",
+		"Forget the error counts of a target once it was not scraped for this duration. 0 keeps the counts of all targets.",
+	).Default("1h").Duration()
+	exporterBackgroundScrapeInterval = kingpin.Flag(
+		"exporter.background_scrape_interval",
+		"Scrape targets in the background on this interval and serve the result of the last scrape on requests. 0 scrapes targets on every request.",
+	).Default("0").Duration()
+	exporterBackgroundScrapeMaxStaleness = kingpin.Flag(
+		"exporter.background_scrape_max_staleness",
+		"Maximum age of background scrape results served on requests. Targets not requested for this duration are no longer scraped in the background.",
+	).Default("5m").Duration()
+	exporterScraperMinInterval = kingpin.Flag(
+		"exporter.scraper_min_interval",
+		"Minimum interval between runs of a scraper per target, as <scraper>=<duration>, e.g. info_schema.tables=1h. Within the interval the last metrics of the scraper are served. Can be repeated.",
+	).PlaceHolder("SCRAPER=DURATION").StringMap()
+	exporterScrapeConcurrency = kingpin.Flag(
+		"exporter.scrape_concurrency",
+		"Maximum number of scrapers running at the same time within a scrape. 0 uses exporter.max_open_connections.",
+	).Default("0").Int()
+	exporterScraperPriority = kingpin.Flag(
+		"exporter.scraper_priority",
+		"Priority of a scraper, as <scraper>=<priority>, e.g. info_schema.tables=-1. Scrapers with a higher priority run first, scrapers with a negative priority are cancelled first when the scrape is about to time out. Can be repeated.",
+	).PlaceHolder("SCRAPER=PRIORITY").StringMap()
+	configFile = kingpin.Flag(
+		"config.file",
+		"Path to a YAML file configuring the exporter, e.g. with named targets.",
+	).Default("").String()
+	customQueryFile = kingpin.Flag(
+		"collect.custom_query.file",
+		"Path to a YAML file defining custom queries, each collected by a custom_query.<name> collector.",
+	).Default("").String()
+	toolkitFlags   = webflag.AddFlags(kingpin.CommandLine, ":9104")
+	collectOptions = collectorflag.AddFlags(kingpin.CommandLine)
+	c              = config.MySqlConfigHandler{
 		Config: &config.Config{},
 	}
+	exporterConfig = config.ExporterConfigHandler{
+		Config: &config.ExporterConfig{},
+	}
+	// collectorNames are the names of all collectors, which the exporter
+	// config may refer to.
+	collectorNames []string
+	// instanceCache keeps the connections to the targets open between
+	// scrapes, unless the connection idle timeout is 0.
+	instanceCache *collector.InstanceCache
+	// backgroundScrapes scrapes the targets in the background, it holds nil
+	// when targets are scraped on every request. It is replaced when the
+	// background scrape settings change on a reload.
+	backgroundScrapes atomic.Pointer[backgroundScraper]
+	// scraperCache holds the metrics of scrapers with a minimum interval.
+	scraperCache *collector.ScraperCache
+	// scraperMinIntervals are the minimum intervals of the scrapers set by
+	// flag.
+	scraperMinIntervals map[string]time.Duration
+	// scrapeErrors counts the errors of the scrapers across requests, it is
+	// nil when the errors are counted per request.
+	scrapeErrors *collector.ScrapeErrors
+	// credentialFallbacks remembers the targets accepting only the
+	// secondary password across requests.
+	credentialFallbacks = collector.NewCredentialFallbacks()
+	// scraperPriorities overrides the default priorities of the scrapers.
+	scraperPriorities map[string]int
 )
 
-// scrapers lists all possible collection methods and if they should be enabled by default.
-var scrapers = map[collector.Scraper]bool{
-	collector.ScrapeGlobalStatus{}:                        true,
-	collector.ScrapeGlobalVariables{}:                     true,
-	collector.ScrapeSlaveStatus{}:                         true,
-	collector.ScrapeProcesslist{}:                         false,
-	collector.ScrapeUser{}:                                false,
-	collector.ScrapeTableSchema{}:                         false,
-	collector.ScrapeInfoSchemaInnodbTablespaces{}:         false,
-	collector.ScrapeInnodbMetrics{}:                       false,
-	collector.ScrapeAutoIncrementColumns{}:                false,
-	collector.ScrapeBinlogSize{}:                          false,
-	collector.ScrapePerfTableIOWaits{}:                    false,
-	collector.ScrapePerfIndexIOWaits{}:                    false,
-	collector.ScrapePerfTableLockWaits{}:                  false,
-	collector.ScrapePerfEventsStatements{}:                false,
-	collector.ScrapePerfEventsStatementsSum{}:             false,
-	collector.ScrapePerfEventsWaits{}:                     false,
-	collector.ScrapePerfFileEvents{}:                      false,
-	collector.ScrapePerfFileInstances{}:                   false,
-	collector.ScrapePerfMemoryEvents{}:                    false,
-	collector.ScrapePerfReplicationGroupMembers{}:         false,
-	collector.ScrapePerfReplicationGroupMemberStats{}:     false,
-	collector.ScrapePerfReplicationApplierStatsByWorker{}: false,
-	collector.ScrapeSysUserSummary{}:                      false,
-	collector.ScrapeUserStat{}:                            false,
-	collector.ScrapeClientStat{}:                          false,
-	collector.ScrapeTableStat{}:                           false,
-	collector.ScrapeSchemaStat{}:                          false,
-	collector.ScrapeInnodbCmp{}:                           true,
-	collector.ScrapeInnodbCmpMem{}:                        true,
-	collector.ScrapeQueryResponseTime{}:                   true,
-	collector.ScrapeEngineTokudbStatus{}:                  false,
-	collector.ScrapeEngineInnodbStatus{}:                  false,
-	collector.ScrapeHeartbeat{}:                           false,
-	collector.ScrapeSlaveHosts{}:                          false,
-	collector.ScrapeReplicaHost{}:                         false,
-	collector.ScrapeRocksDBPerfContext{}:                  false,
+// scraperSet holds all scrapers and whether their flag enables them.
+type scraperSet struct {
+	all           []collector.Scraper
+	enabledByFlag map[string]bool
+	// custom are the names of the custom queries of the flag, which are
+	// replaced by the custom queries of the exporter config if it has any.
+	custom map[string]bool
+}
+
+// enabled returns the scrapers enabled by their flag, unless the exporter
+// config enables or disables them.
+func (s scraperSet) enabled(cfg *config.ExporterConfig) []collector.Scraper {
+	all := s.all
+	if customQueries, ok := cfg.CustomQueries(); ok {
+		all = slices.DeleteFunc(slices.Clone(all), func(scraper collector.Scraper) bool {
+			return s.custom[scraper.Name()]
+		})
+		for _, query := range customQueries {
+			all = append(all, query)
+		}
+	}
+	var enabled []collector.Scraper
+	for _, scraper := range all {
+		on, ok := cfg.Collectors[scraper.Name()]
+		if !ok {
+			_, custom := scraper.(collector.ScrapeCustomQuery)
+			on = s.enabledByFlag[scraper.Name()] || custom
+		}
+		if on {
+			enabled = append(enabled, scraper)
+		}
+	}
+	return enabled
 }
 
 func filterScrapers(scrapers []collector.Scraper, collectParams []string) []collector.Scraper {
@@ -182,7 +250,224 @@ func init() {
 	prometheus.MustRegister(versioncollector.NewCollector("mysqld_exporter"))
 }
 
-func newHandler(scrapers []collector.Scraper, logger *slog.Logger) http.HandlerFunc {
+// exporterOpts returns the options for scraping a target using the given
+// auth module and module, which may be empty. The option parameters of the
+// request take precedence over the options of the module.
+func exporterOpts(authModule, moduleName, target string, optionParams map[string]string) []collector.ExporterOpt {
+	cfg := exporterConfig.GetConfig()
+	settings := cfg.Exporter
+	// The options of the config are validated when loading it.
+	options, _ := cfg.CollectorOptions(*collectOptions)
+	queryTimeout := time.Duration(*exporterQueryTimeout) * time.Second
+	if settings.QueryTimeout != nil {
+		queryTimeout = time.Duration(*settings.QueryTimeout)
+	}
+	enableLockTimeout := *cmp.Or(settings.EnableLockWaitTimeout, enableExporterLockTimeout)
+	lockTimeout := *cmp.Or(settings.LockWaitTimeout, exporterLockTimeout)
+	priorities := map[string]int{}
+	maps.Copy(priorities, scraperPriorities)
+	maps.Copy(priorities, settings.ScraperPriorities)
+
+	var sessionSettings map[string]string
+	if module, ok := cfg.Modules[moduleName]; ok {
+		options, _ = module.CollectorOptions(options)
+		if module.QueryTimeout > 0 {
+			queryTimeout = time.Duration(module.QueryTimeout)
+		}
+		if module.LockWaitTimeout != nil {
+			enableLockTimeout, lockTimeout = true, *module.LockWaitTimeout
+		}
+		sessionSettings = module.SessionSettings
+	}
+	// The option parameters are validated when handling the request.
+	options, _ = config.ApplyOptions(options, optionParams)
+
+	opts := []collector.ExporterOpt{
+		collector.EnableLockWaitTimeout(enableLockTimeout),
+		collector.SetLockWaitTimeout(lockTimeout),
+		collector.SetSlowLogFilter(*cmp.Or(settings.LogSlowFilter, slowLogFilter)),
+		collector.SetQueryTimeout(queryTimeout),
+		collector.SetMaxOpenConns(*cmp.Or(settings.MaxOpenConnections, exporterMaxOpenConns)),
+		collector.SetOptions(options),
+		collector.SetScrapeConcurrency(*cmp.Or(settings.ScrapeConcurrency, exporterScrapeConcurrency)),
+		collector.SetScraperPriorities(priorities),
+		collector.SetSessionSettings(sessionSettings),
+	}
+	if instanceCache != nil && durationSetting(settings.ConnectionIdleTimeout, *exporterConnectionIdleTimeout) > 0 {
+		opts = append(opts, collector.SetInstanceCache(instanceCache, authModule))
+	}
+	if scrapeErrors != nil {
+		opts = append(opts, collector.SetScrapeErrors(scrapeErrors, authModule))
+	}
+	if cfgsection, ok := c.GetConfig().Sections[authModule]; ok {
+		// The DSN only differs in the password from the DSN of the
+		// target, which was formed successfully.
+		if dsn, _ := cfgsection.FormSecondaryDSN(target, authModule); dsn != "" {
+			opts = append(opts, collector.SetFallbackDSN(dsn, credentialFallbacks))
+		}
+	}
+	if scraperCache != nil {
+		opts = append(opts, collector.SetScraperCache(scraperCache))
+	}
+	return opts
+}
+
+// newTargetCollector returns a function creating the collector for scraping
+// the target. The DSN and the scrapers are determined anew for every scrape
+// so that reloads of the config are taken into account. If check is set,
+// the target is checked against the access rules anew for every scrape, as
+// its host name may resolve to other addresses since it was requested.
+func newTargetCollector(authModule, module, target string, check bool, collectParams []string, optionParams map[string]string, scrapers func() []collector.Scraper, logger *slog.Logger) newCollectorFunc {
+	return func(ctx context.Context) (prometheus.Collector, error) {
+		cfgsection, ok := c.GetConfig().Sections[authModule]
+		if !ok {
+			return nil, fmt.Errorf("could not find config section [%s]", authModule)
+		}
+		if check {
+			var err error
+			if ctx, err = targetContext(ctx, target, authModule, cfgsection); err != nil {
+				return nil, err
+			}
+		}
+		dsn, err := cfgsection.FormDSNContext(ctx, target, authModule)
+		if err != nil {
+			return nil, err
+		}
+		return collector.New(ctx, dsn, filterScrapers(scrapers(), collectParams), logger, exporterOpts(authModule, module, target, optionParams)...), nil
+	}
+}
+
+// targetGatherer returns the gatherer for the metrics of the target, which
+// either scrapes the target when gathering or serves the result of the last
+// background scrape. The background scrapes check the target against the
+// access rules if check is set.
+func targetGatherer(ctx context.Context, dsn, authModule, module, target string, check bool, collectParams []string, optionParams map[string]string, scrapers func() []collector.Scraper, logger *slog.Logger) prometheus.Gatherer {
+	if b := backgroundScrapes.Load(); b != nil {
+		g, ok := b.gatherer(
+			ctx,
+			newBackgroundTarget(authModule, module, target, collectParams, optionParams),
+			newTargetCollector(authModule, module, target, check, collectParams, optionParams, scrapers, logger),
+		)
+		if ok {
+			return g
+		}
+	}
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collector.New(ctx, dsn, filterScrapers(scrapers(), collectParams), logger, exporterOpts(authModule, module, target, optionParams)...))
+	return registry
+}
+
+// durationSetting returns the duration of the exporter config if it is set
+// and the duration of the flag otherwise.
+func durationSetting(setting *model.Duration, flag time.Duration) time.Duration {
+	if setting != nil {
+		return time.Duration(*setting)
+	}
+	return flag
+}
+
+// applySettings applies the settings of the exporter config which are kept
+// between scrapes, taking precedence over their flags. It is called whenever
+// the exporter config was loaded.
+func applySettings(scrapers func() []collector.Scraper, logger *slog.Logger) {
+	settings := exporterConfig.GetConfig().Exporter
+
+	intervals := make(map[string]time.Duration, len(scraperMinIntervals)+len(settings.ScraperMinIntervals))
+	maps.Copy(intervals, scraperMinIntervals)
+	for name, interval := range settings.ScraperMinIntervals {
+		intervals[name] = time.Duration(interval)
+	}
+	scraperCache.SetIntervals(intervals)
+
+	connectionIdleTimeout := durationSetting(settings.ConnectionIdleTimeout, *exporterConnectionIdleTimeout)
+	instanceCache.SetIdleTimeout(connectionIdleTimeout)
+	if connectionIdleTimeout <= 0 {
+		// The connections are no longer reused.
+		instanceCache.Close()
+	}
+	scrapeErrors.SetIdleTimeout(durationSetting(settings.ScrapeErrorsIdleTimeout, *exporterScrapeErrorsIdleTimeout))
+
+	interval := durationSetting(settings.BackgroundScrapeInterval, *exporterBackgroundScrapeInterval)
+	maxStaleness := durationSetting(settings.BackgroundScrapeMaxStaleness, *exporterBackgroundScrapeMaxStaleness)
+	if interval > 0 && maxStaleness < interval {
+		logger.Warn("Background scrape max staleness is lower than the interval, using the interval", "interval", interval, "max_staleness", maxStaleness)
+		maxStaleness = interval
+	}
+	targets := configuredTargets(exporterConfig.GetConfig(), interval, scrapers, logger)
+	old := backgroundScrapes.Load()
+	b := old
+	switch {
+	case interval <= 0 && len(targets) == 0:
+		b = nil
+	case old == nil || old.interval != interval || old.maxStaleness != maxStaleness:
+		b = newBackgroundScraper(interval, maxStaleness, logger)
+	}
+	if b != nil {
+		b.sync(targets)
+	}
+	if b == old {
+		return
+	}
+	if interval > 0 {
+		logger.Info("Scraping targets in the background", "interval", interval, "max_staleness", maxStaleness)
+	} else if b != nil {
+		logger.Info("Scraping the targets of the config with a scrape interval in the background", "targets", len(targets))
+	} else {
+		logger.Info("Scraping targets on every request")
+	}
+	backgroundScrapes.Store(b)
+	if old != nil {
+		old.stop()
+	}
+}
+
+// configuredTargets returns the targets scraped in the background from the
+// load of the config on: the named targets of the config, with their scrape
+// interval or the given interval, and the target of the metrics path if the
+// interval is set. The named targets are scraped as requested from /probe
+// without further parameters.
+func configuredTargets(cfg *config.ExporterConfig, interval time.Duration, scrapers func() []collector.Scraper, logger *slog.Logger) map[backgroundTarget]configuredTarget {
+	targets := map[backgroundTarget]configuredTarget{}
+	if interval > 0 {
+		targets[newBackgroundTarget("client", "", "", nil, nil)] = configuredTarget{
+			interval:     interval,
+			newCollector: newTargetCollector("client", "", "", false, nil, nil, scrapers, logger),
+		}
+	}
+	for _, t := range cfg.Targets {
+		targetInterval := cmp.Or(time.Duration(t.ScrapeInterval), interval)
+		if targetInterval <= 0 {
+			continue
+		}
+		collectParams := t.Collectors
+		if len(collectParams) == 0 && t.Module != "" {
+			collectParams = cfg.Modules[t.Module].Collectors
+		}
+		targets[newBackgroundTarget(t.AuthModule, t.Module, t.DSNTarget(), collectParams, nil)] = configuredTarget{
+			interval:     targetInterval,
+			newCollector: newTargetCollector(t.AuthModule, t.Module, t.DSNTarget(), false, collectParams, nil, scrapers, logger),
+		}
+	}
+	return targets
+}
+
+// reloadConfig reloads the exporter and MySQL config files and closes the
+// cached connections of the sections which were changed. The files are only
+// applied if both are valid.
+func reloadConfig(scrapers func() []collector.Scraper, logger *slog.Logger) error {
+	oldConfig := c.GetConfig()
+	if err := config.ReloadConfigs(&exporterConfig, *configFile, collectorNames, &c, *configMycnf, *mysqldAddress, *mysqldUser, *tlsInsecureSkipVerify, logger); err != nil {
+		return err
+	}
+	for _, section := range oldConfig.ChangedSections(c.GetConfig()) {
+		logger.Debug("Closing connections of reloaded config section", "section", section)
+		instanceCache.Invalidate(section)
+	}
+	applySettings(scrapers, logger)
+	return nil
+}
+
+func newHandler(scrapers func() []collector.Scraper, logger *slog.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const authModule string = "client"
 		var dsn string
@@ -198,14 +483,13 @@ func newHandler(scrapers []collector.Scraper, logger *slog.Logger) http.HandlerF
 		if !ok {
 			logger.Error(fmt.Sprintf("Failed to parse section [%s] from config file", authModule), "err", err)
 		}
-		if dsn, err = cfgsection.FormDSN(target, authModule); err != nil {
-			logger.Error(fmt.Sprintf("Failed to form dsn from section [%s]", authModule), "err", err)
-		}
-
-		collect := q["collect[]"]
-
 		// Use request context for cancellation when connection gets closed.
 		ctx := r.Context()
+		if target != "" {
+			if ctx, ok = checkTarget(w, r, target, authModule, cfgsection, logger); !ok {
+				return
+			}
+		}
 		// If a timeout is configured via the Prometheus header, add it to the context.
 		timeoutSeconds, err := getScrapeTimeoutSeconds(r, *timeoutOffset)
 		if err != nil {
@@ -219,22 +503,15 @@ func newHandler(scrapers []collector.Scraper, logger *slog.Logger) http.HandlerF
 			// Overwrite request with timeout context.
 			r = r.WithContext(ctx)
 		}
+		if dsn, err = cfgsection.FormDSNContext(ctx, target, authModule); err != nil {
+			logger.Error(fmt.Sprintf("Failed to form dsn from section [%s]", authModule), "err", err)
+		}
 
-		filteredScrapers := filterScrapers(scrapers, collect)
-
-		registry := prometheus.NewRegistry()
-
-		registry.MustRegister(collector.New(ctx, dsn, filteredScrapers, logger,
-			collector.EnableLockWaitTimeout(*enableExporterLockTimeout),
-			collector.SetLockWaitTimeout(*exporterLockTimeout),
-			collector.SetSlowLogFilter(*slowLogFilter),
-			collector.SetQueryTimeout(time.Duration(*exporterQueryTimeout)*time.Second),
-			collector.SetMaxOpenConns(*exporterMaxOpenConns),
-		))
+		collect := q["collect[]"]
 
 		gatherers := prometheus.Gatherers{
 			prometheus.DefaultGatherer,
-			registry,
+			targetGatherer(ctx, dsn, authModule, "", target, target != "", collect, nil, scrapers, logger),
 		}
 		// Delegate http serving to Prometheus client library, which will call collector.Collect.
 		h := promhttp.HandlerFor(gatherers, promhttp.HandlerOpts{})
@@ -242,27 +519,82 @@ func newHandler(scrapers []collector.Scraper, logger *slog.Logger) http.HandlerF
 	}
 }
 
-func validateExporterFlags(maxOpenConns, queryTimeout int) error {
+func validateExporterFlags(maxOpenConns, scrapeConcurrency, queryTimeout int, backgroundScrapeInterval, backgroundScrapeMaxStaleness time.Duration) error {
 	if maxOpenConns < 1 {
 		return fmt.Errorf("invalid value for --exporter.max_open_connections, must be >= 1: %d", maxOpenConns)
 	}
+	if scrapeConcurrency < 0 {
+		return fmt.Errorf("invalid value for --exporter.scrape_concurrency, must be >= 0: %d", scrapeConcurrency)
+	}
 	if queryTimeout < 0 {
 		return fmt.Errorf("invalid value for --exporter.query_timeout, must be >= 0: %d", queryTimeout)
 	}
+	if backgroundScrapeInterval < 0 {
+		return fmt.Errorf("invalid value for --exporter.background_scrape_interval, must be >= 0: %s", backgroundScrapeInterval)
+	}
+	if backgroundScrapeInterval > 0 && backgroundScrapeMaxStaleness < backgroundScrapeInterval {
+		return fmt.Errorf("invalid value for --exporter.background_scrape_max_staleness, must be >= --exporter.background_scrape_interval: %s", backgroundScrapeMaxStaleness)
+	}
 	return nil
 }
 
+// parseScraperMinIntervals parses the minimum intervals of the scrapers
+// given as scraper name and duration.
+func parseScraperMinIntervals(minIntervals map[string]string, scrapers []collector.Scraper) (map[string]time.Duration, error) {
+	known := make(map[string]bool, len(scrapers))
+	for _, scraper := range scrapers {
+		known[scraper.Name()] = true
+	}
+	intervals := make(map[string]time.Duration, len(minIntervals))
+	for name, value := range minIntervals {
+		if !known[name] {
+			return nil, fmt.Errorf("invalid value for --exporter.scraper_min_interval, unknown scraper: %s", name)
+		}
+		interval, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for --exporter.scraper_min_interval, %s: %w", name, err)
+		}
+		if interval <= 0 {
+			return nil, fmt.Errorf("invalid value for --exporter.scraper_min_interval, must be > 0: %s=%s", name, value)
+		}
+		intervals[name] = interval
+	}
+	return intervals, nil
+}
+
+// parseScraperPriorities parses the priorities of the scrapers given as
+// flags, rejecting unknown scrapers and values which are not integers.
+func parseScraperPriorities(priorities map[string]string, scrapers []collector.Scraper) (map[string]int, error) {
+	known := make(map[string]bool, len(scrapers))
+	for _, scraper := range scrapers {
+		known[scraper.Name()] = true
+	}
+	parsed := make(map[string]int, len(priorities))
+	for name, value := range priorities {
+		if !known[name] {
+			return nil, fmt.Errorf("invalid value for --exporter.scraper_priority, unknown scraper: %s", name)
+		}
+		priority, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for --exporter.scraper_priority, %s: %w", name, err)
+		}
+		parsed[name] = priority
+	}
+	return parsed, nil
+}
+
 func main() {
 	// Sort scrapers by name so that flag registration and processing happen
 	// in a deterministic order, as map iteration order is undefined.
-	sortedScrapers := slices.SortedFunc(maps.Keys(scrapers), func(a, b collector.Scraper) int {
+	registeredScrapers := collector.Scrapers()
+	sortedScrapers := slices.SortedFunc(maps.Keys(registeredScrapers), func(a, b collector.Scraper) int {
 		return strings.Compare(a.Name(), b.Name())
 	})
 	// Generate ON/OFF flags for all scrapers.
 	scraperFlags := map[collector.Scraper]*bool{}
 	for _, scraper := range sortedScrapers {
 		defaultOn := "false"
-		if scrapers[scraper] {
+		if registeredScrapers[scraper] {
 			defaultOn = "true"
 		}
 
@@ -285,25 +617,76 @@ func main() {
 	logger.Info("Starting mysqld_exporter", "version", version.Info())
 	logger.Info("Build context", "build_context", version.BuildContext())
 
-	if err := validateExporterFlags(*exporterMaxOpenConns, *exporterQueryTimeout); err != nil {
+	if err := validateExporterFlags(*exporterMaxOpenConns, *exporterScrapeConcurrency, *exporterQueryTimeout, *exporterBackgroundScrapeInterval, *exporterBackgroundScrapeMaxStaleness); err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+	if err := collectOptions.Validate(); err != nil {
 		logger.Error(err.Error())
 		os.Exit(1)
 	}
 
+	var customScrapers []collector.Scraper
+	if *customQueryFile != "" {
+		customQueries, err := collector.LoadCustomQueries(*customQueryFile)
+		if err != nil {
+			logger.Error("Error loading custom queries", "file", *customQueryFile, "err", err)
+			os.Exit(1)
+		}
+		for _, query := range customQueries {
+			customScrapers = append(customScrapers, query)
+		}
+	}
+
+	allScrapers := append(sortedScrapers, customScrapers...)
+	for _, scraper := range allScrapers {
+		collectorNames = append(collectorNames, scraper.Name())
+	}
 	var err error
+	scraperMinIntervals, err = parseScraperMinIntervals(*exporterScraperMinInterval, allScrapers)
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+	scraperPriorities, err = parseScraperPriorities(*exporterScraperPriority, allScrapers)
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+	scraperCache = collector.NewScraperCache(scraperMinIntervals)
+
+	if err = exporterConfig.ReloadConfig(*configFile, collectorNames); err != nil {
+		logger.Error("Error loading exporter config", "file", *configFile, "err", err)
+		os.Exit(1)
+	}
+
+	c.LoginPathFile = *configMyLoginCnf
 	if err = c.ReloadConfig(*configMycnf, *mysqldAddress, *mysqldUser, *tlsInsecureSkipVerify, logger); err != nil {
 		logger.Info("Error parsing host config", "file", *configMycnf, "err", err)
 		os.Exit(1)
 	}
 
-	// Register only scrapers enabled by flag.
-	enabledScrapers := []collector.Scraper{}
-	for _, scraper := range sortedScrapers {
-		if *scraperFlags[scraper] {
-			logger.Info("Scraper enabled", "scraper", scraper.Name())
-			enabledScrapers = append(enabledScrapers, scraper)
-		}
+	instanceCache = collector.NewInstanceCache(*exporterConnectionIdleTimeout)
+	prometheus.MustRegister(instanceCache)
+	scrapeErrors = collector.NewScrapeErrors(*exporterScrapeErrorsIdleTimeout)
+
+	// Scrapers are enabled by flag unless the exporter config overrides it,
+	// custom queries are always enabled.
+	scrapers := scraperSet{all: allScrapers, enabledByFlag: map[string]bool{}, custom: map[string]bool{}}
+	for scraper, enabled := range scraperFlags {
+		scrapers.enabledByFlag[scraper.Name()] = *enabled
+	}
+	for _, scraper := range customScrapers {
+		scrapers.custom[scraper.Name()] = true
+	}
+	enabledScrapers := func() []collector.Scraper {
+		return scrapers.enabled(exporterConfig.GetConfig())
+	}
+	for _, scraper := range enabledScrapers() {
+		logger.Info("Scraper enabled", "scraper", scraper.Name())
 	}
+	applySettings(enabledScrapers, logger)
+
 	handlerFunc := newHandler(enabledScrapers, logger)
 	http.Handle(*metricsPath, promhttp.InstrumentMetricHandler(prometheus.DefaultRegisterer, handlerFunc))
 	if *metricsPath != "/" && *metricsPath != "" {
@@ -326,13 +709,25 @@ func main() {
 		http.Handle("/", landingPage)
 	}
 	http.HandleFunc("/probe", handleProbe(enabledScrapers, logger))
+	http.HandleFunc("/sd", handleSD(logger))
 	http.HandleFunc("/-/reload", func(w http.ResponseWriter, r *http.Request) {
-		if err = c.ReloadConfig(*configMycnf, *mysqldAddress, *mysqldUser, *tlsInsecureSkipVerify, logger); err != nil {
-			logger.Warn("Error reloading host config", "file", *configMycnf, "error", err)
+		if err := reloadConfig(enabledScrapers, logger); err != nil {
+			logger.Warn("Error reloading config", "error", err)
 			return
 		}
 		_, _ = w.Write([]byte(`ok`))
 	})
+	go func() {
+		hup := make(chan os.Signal, 1)
+		signal.Notify(hup, syscall.SIGHUP)
+		for range hup {
+			if err := reloadConfig(enabledScrapers, logger); err != nil {
+				logger.Warn("Error reloading config", "error", err)
+				continue
+			}
+			logger.Info("Reloaded config")
+		}
+	}()
 	srv := &http.Server{}
 	if err := web.ListenAndServe(srv, toolkitFlags, logger); err != nil {
 		logger.Error("Error starting HTTP server", "err", err)
@@ -22,6 +22,7 @@ import (
 	"net/url"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"reflect"
 	"runtime"
 	"strings"
@@ -31,6 +32,7 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/prometheus/mysqld_exporter/collector"
+	"github.com/prometheus/mysqld_exporter/config"
 )
 
 // bin stores information about path of executable and attached port
@@ -105,22 +107,30 @@ func TestBin(t *testing.T) {
 
 func TestValidateExporterFlags(t *testing.T) {
 	tests := []struct {
-		name         string
-		maxOpenConns int
-		queryTimeout int
-		wantErr      bool
+		name                     string
+		maxOpenConns             int
+		scrapeConcurrency        int
+		queryTimeout             int
+		backgroundScrapeInterval time.Duration
+		maxStaleness             time.Duration
+		wantErr                  bool
 	}{
-		{name: "defaults", maxOpenConns: 2},
+		{name: "defaults", maxOpenConns: 2, maxStaleness: 5 * time.Minute},
 		{name: "disabled query timeout", maxOpenConns: 2, queryTimeout: 0},
 		{name: "positive query timeout", maxOpenConns: 2, queryTimeout: 1},
 		{name: "zero max open connections", maxOpenConns: 0, wantErr: true},
 		{name: "negative max open connections", maxOpenConns: -1, wantErr: true},
+		{name: "scrape concurrency", maxOpenConns: 2, scrapeConcurrency: 4},
+		{name: "negative scrape concurrency", maxOpenConns: 2, scrapeConcurrency: -1, wantErr: true},
 		{name: "negative query timeout", maxOpenConns: 2, queryTimeout: -1, wantErr: true},
+		{name: "background scrapes", maxOpenConns: 2, backgroundScrapeInterval: 15 * time.Second, maxStaleness: time.Minute},
+		{name: "negative background scrape interval", maxOpenConns: 2, backgroundScrapeInterval: -time.Second, wantErr: true},
+		{name: "staleness below background scrape interval", maxOpenConns: 2, backgroundScrapeInterval: time.Minute, maxStaleness: time.Second, wantErr: true},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := validateExporterFlags(tt.maxOpenConns, tt.queryTimeout)
+			err := validateExporterFlags(tt.maxOpenConns, tt.scrapeConcurrency, tt.queryTimeout, tt.backgroundScrapeInterval, tt.maxStaleness)
 			if (err != nil) != tt.wantErr {
 				t.Fatalf("validateExporterFlags() error = %v, wantErr %v", err, tt.wantErr)
 			}
@@ -128,6 +138,61 @@ func TestValidateExporterFlags(t *testing.T) {
 	}
 }
 
+func TestParseScraperMinIntervals(t *testing.T) {
+	scrapers := []collector.Scraper{collector.ScrapeGlobalStatus{}, collector.ScrapeTableSchema{}}
+	tests := []struct {
+		name    string
+		raw     map[string]string
+		want    map[string]time.Duration
+		wantErr bool
+	}{
+		{name: "none", raw: map[string]string{}, want: map[string]time.Duration{}},
+		{name: "valid", raw: map[string]string{"info_schema.tables": "1h"}, want: map[string]time.Duration{"info_schema.tables": time.Hour}},
+		{name: "unknown scraper", raw: map[string]string{"info_schema.foo": "1h"}, wantErr: true},
+		{name: "invalid duration", raw: map[string]string{"info_schema.tables": "1x"}, wantErr: true},
+		{name: "zero duration", raw: map[string]string{"info_schema.tables": "0s"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseScraperMinIntervals(tt.raw, scrapers)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseScraperMinIntervals() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, got); !tt.wantErr && diff != "" {
+				t.Fatalf("parseScraperMinIntervals() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseScraperPriorities(t *testing.T) {
+	scrapers := []collector.Scraper{collector.ScrapeGlobalStatus{}, collector.ScrapeTableSchema{}}
+	tests := []struct {
+		name    string
+		raw     map[string]string
+		want    map[string]int
+		wantErr bool
+	}{
+		{name: "none", raw: map[string]string{}, want: map[string]int{}},
+		{name: "valid", raw: map[string]string{"info_schema.tables": "-1", "global_status": "20"}, want: map[string]int{"info_schema.tables": -1, "global_status": 20}},
+		{name: "unknown scraper", raw: map[string]string{"info_schema.foo": "1"}, wantErr: true},
+		{name: "invalid priority", raw: map[string]string{"info_schema.tables": "high"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseScraperPriorities(tt.raw, scrapers)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseScraperPriorities() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, got); !tt.wantErr && diff != "" {
+				t.Fatalf("parseScraperPriorities() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
 func testLanding(t *testing.T, data bin) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -432,3 +497,66 @@ func Test_getScrapeTimeoutSeconds(t *testing.T) {
 		})
 	}
 }
+
+func TestScraperSetEnabled(t *testing.T) {
+	scrapers := scraperSet{
+		all: []collector.Scraper{collector.ScrapeGlobalStatus{}, collector.ScrapeGlobalVariables{}, collector.ScrapeTableSchema{}},
+		enabledByFlag: map[string]bool{
+			"global_status":    true,
+			"global_variables": true,
+		},
+	}
+	names := func(scrapers []collector.Scraper) []string {
+		var names []string
+		for _, scraper := range scrapers {
+			names = append(names, scraper.Name())
+		}
+		return names
+	}
+
+	got := names(scrapers.enabled(&config.ExporterConfig{}))
+	if diff := cmp.Diff([]string{"global_status", "global_variables"}, got); diff != "" {
+		t.Fatalf("enabled() mismatch without config (-want +got):\n%s", diff)
+	}
+	got = names(scrapers.enabled(&config.ExporterConfig{Collectors: map[string]bool{"global_variables": false, "info_schema.tables": true}}))
+	if diff := cmp.Diff([]string{"global_status", "info_schema.tables"}, got); diff != "" {
+		t.Fatalf("enabled() mismatch with config (-want +got):\n%s", diff)
+	}
+
+	dir := t.TempDir()
+	writeQueries := func(name, query string) string {
+		filename := filepath.Join(dir, name+".yml")
+		content := "queries:\n  - name: " + query + "\n    query: SELECT 1 AS value\n    metrics:\n      - name: value\n        column: value\n"
+		if err := os.WriteFile(filename, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return filename
+	}
+	flagQueries, err := collector.LoadCustomQueries(writeQueries("flag", "old"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	scrapers.custom = map[string]bool{}
+	for _, query := range flagQueries {
+		scrapers.all = append(scrapers.all, query)
+		scrapers.custom[query.Name()] = true
+	}
+	got = names(scrapers.enabled(&config.ExporterConfig{}))
+	if diff := cmp.Diff([]string{"global_status", "global_variables", "custom_query.old"}, got); diff != "" {
+		t.Fatalf("enabled() mismatch with flag custom queries (-want +got):\n%s", diff)
+	}
+
+	configFile := filepath.Join(dir, "exporter.yml")
+	content := "exporter:\n  custom_query_file: " + writeQueries("config", "new") + "\n"
+	if err := os.WriteFile(configFile, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.LoadExporterConfig(configFile, collectorNames)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = names(scrapers.enabled(cfg))
+	if diff := cmp.Diff([]string{"global_status", "global_variables", "custom_query.new"}, got); diff != "" {
+		t.Fatalf("enabled() mismatch with config custom queries (-want +got):\n%s", diff)
+	}
+}
@@ -14,19 +14,96 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/go-sql-driver/mysql"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
 	"github.com/prometheus/mysqld_exporter/collector"
+	"github.com/prometheus/mysqld_exporter/config"
 )
 
-func handleProbe(scrapers []collector.Scraper, logger *slog.Logger) http.HandlerFunc {
+var targetRejections = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "mysql",
+	Subsystem: "exporter",
+	Name:      "target_rejections_total",
+	Help:      "Requests rejected as their target is not allowed by the target_access of the config file or the allow-target of the auth module, by reason.",
+}, []string{"reason"})
+
+func init() {
+	for _, reason := range []string{config.TargetDenied, config.TargetNotAllowed, config.TargetNotAllowedAuthModule, config.TargetUnresolved} {
+		targetRejections.WithLabelValues(reason)
+	}
+	prometheus.MustRegister(targetRejections)
+}
+
+// checkTarget returns whether the target requested with the auth module is
+// allowed, responding with an error otherwise. The returned context restricts
+// the connections to the target to the addresses it was checked with.
+func checkTarget(w http.ResponseWriter, r *http.Request, target, authModule string, section config.MySqlConfig, logger *slog.Logger) (context.Context, bool) {
+	ctx, err := targetContext(r.Context(), target, authModule, section)
+	var rejected *config.TargetRejectedError
+	if errors.As(err, &rejected) {
+		logger.Warn("Rejected request for target", "target", target, "auth_module", authModule, "reason", rejected.Reason, "remote_addr", r.RemoteAddr)
+		targetRejections.WithLabelValues(rejected.Reason).Inc()
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return nil, false
+	}
+	return ctx, true
+}
+
+// targetContext checks the target requested with the auth module against the
+// access rules, returning a context restricting the connections to the
+// target to the addresses its host name was resolved to, if any.
+func targetContext(ctx context.Context, target, authModule string, section config.MySqlConfig) (context.Context, error) {
+	addrs, err := exporterConfig.GetConfig().TargetAccess.CheckTarget(ctx, target, authModule, section)
+	if err != nil {
+		return nil, err
+	}
+	if addrs != nil {
+		ctx = collector.WithTargetAddrs(ctx, addrs)
+	}
+	return ctx, nil
+}
+
+// parseOptionParams returns the collector options set by the parameters of
+// the request, by name, e.g. `perf_schema.eventsstatements.limit=50`. Only
+// numeric and boolean options can be set by requests, the options naming
+// databases, tables or patterns are only set by flags and modules.
+func parseOptionParams(params url.Values) (map[string]string, error) {
+	requestOptions := collector.RequestOptionNames()
+	optionParams := map[string]string{}
+	for _, name := range collector.OptionNames() {
+		if !params.Has(name) {
+			continue
+		}
+		if !slices.Contains(requestOptions, name) {
+			return nil, fmt.Errorf("option %q can not be set by request parameters", name)
+		}
+		optionParams[name] = params.Get(name)
+	}
+	if _, err := config.ApplyOptions(collector.DefaultOptions(), optionParams); err != nil {
+		return nil, err
+	}
+	return optionParams, nil
+}
+
+func handleProbe(scrapers func() []collector.Scraper, logger *slog.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
 		ctx := r.Context()
 		params := r.URL.Query()
 		target := params.Get("target")
@@ -41,20 +118,67 @@ func handleProbe(scrapers []collector.Scraper, logger *slog.Logger) http.Handler
 			authModule = "client"
 		}
 
+		module := params.Get("module")
+		optionParams, err := parseOptionParams(params)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// Resolve the targets configured by name.
+		exporterCfg := exporterConfig.GetConfig()
+		var labels map[string]string
+		t, named := exporterCfg.Target(target)
+		if named {
+			target = t.DSNTarget()
+			if !params.Has("auth_module") {
+				authModule = t.AuthModule
+			}
+			if module == "" {
+				module = t.Module
+			}
+			if len(collectParams) == 0 {
+				collectParams = t.Collectors
+			}
+			labels = t.Labels
+		}
+		if module != "" {
+			m, ok := exporterCfg.Modules[module]
+			if !ok {
+				http.Error(w, fmt.Sprintf("Unknown module %q", module), http.StatusBadRequest)
+				return
+			}
+			if len(collectParams) == 0 {
+				collectParams = m.Collectors
+			}
+		}
+
 		cfg := c.GetConfig()
+		// Select the auth module of other targets by their match-target.
+		if !params.Has("auth_module") && !named {
+			matched, err := cfg.MatchAuthModule(target)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if matched != "" {
+				authModule = matched
+			}
+		}
 		cfgsection, ok := cfg.Sections[authModule]
 		if !ok {
 			logger.Error(fmt.Sprintf("Could not find section [%s] from config file", authModule))
 			http.Error(w, fmt.Sprintf("Could not find config section [%s]", authModule), http.StatusBadRequest)
 			return
 		}
-		dsn, err := cfgsection.FormDSN(target, authModule)
-		if err != nil {
-			logger.Error(fmt.Sprintf("Failed to form dsn from section [%s]", authModule), "err", err)
-			http.Error(w, fmt.Sprintf("Error forming dsn from config section [%s]", authModule), http.StatusBadRequest)
-			return
+		// Named targets are configured along with the access rules, unless
+		// they are requested with the credentials of another auth module.
+		check := !named || authModule != t.AuthModule
+		if check {
+			if ctx, ok = checkTarget(w, r, target, authModule, cfgsection, logger); !ok {
+				return
+			}
 		}
-
 		// If a timeout is configured via the Prometheus header, add it to the context.
 		timeoutSeconds, err := getScrapeTimeoutSeconds(r, *timeoutOffset)
 		if err != nil {
@@ -68,19 +192,116 @@ func handleProbe(scrapers []collector.Scraper, logger *slog.Logger) http.Handler
 			// Overwrite request with timeout context.
 			r = r.WithContext(ctx)
 		}
+		// A password-command is killed once the scrape times out.
+		dsn, err := cfgsection.FormDSNContext(ctx, target, authModule)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Failed to form dsn from section [%s]", authModule), "err", err)
+			http.Error(w, fmt.Sprintf("Error forming dsn from config section [%s]", authModule), http.StatusBadRequest)
+			return
+		}
+
+		if params.Get("debug") == "true" {
+			var trace bytes.Buffer
+			t := collector.NewTrace(&trace)
+			t.Printf("Probing target %s with auth module [%s]", target, authModule)
+			t.Printf("DSN: %s", redactDSN(dsn))
+			dnsLookup, dnsErr := lookupTarget(ctx, target, t)
+			registry := prometheus.NewRegistry()
+			opts := append(exporterOpts(authModule, module, target, optionParams), collector.SetTrace(t))
+			registry.MustRegister(collector.New(ctx, dsn, filterScrapers(scrapers(), collectParams), logger, opts...))
+			families, err := labeledGatherer(probeGatherer(registry, start, dnsLookup, dnsErr), labels).Gather()
+			if err != nil {
+				t.Printf("Gathering metrics failed: %s", err)
+			}
+			writeProbeDebug(w, &trace, families)
+			return
+		}
 
-		filteredScrapers := filterScrapers(scrapers, collectParams)
+		dnsLookup, dnsErr := lookupTarget(ctx, target, nil)
+		gatherer := labeledGatherer(probeGatherer(targetGatherer(ctx, dsn, authModule, module, target, check, collectParams, optionParams, scrapers, logger), start, dnsLookup, dnsErr), labels)
 
-		registry := prometheus.NewRegistry()
-		registry.MustRegister(collector.New(ctx, dsn, filteredScrapers, logger,
-			collector.EnableLockWaitTimeout(*enableExporterLockTimeout),
-			collector.SetLockWaitTimeout(*exporterLockTimeout),
-			collector.SetSlowLogFilter(*slowLogFilter),
-			collector.SetQueryTimeout(time.Duration(*exporterQueryTimeout)*time.Second),
-			collector.SetMaxOpenConns(*exporterMaxOpenConns),
-		))
-
-		h := promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+		h := promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{})
 		h.ServeHTTP(w, r)
 	}
 }
+
+// lookupTarget resolves the host name of the target, returning how long it
+// took. Addresses and unix sockets are not resolved.
+func lookupTarget(ctx context.Context, target string, trace *collector.Trace) (time.Duration, error) {
+	host, _, err := net.SplitHostPort(target)
+	if err != nil || strings.HasPrefix(target, "unix://") {
+		return 0, nil
+	}
+	if _, err := netip.ParseAddr(host); err == nil {
+		return 0, nil
+	}
+	start := time.Now()
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	duration := time.Since(start)
+	if err != nil {
+		trace.Printf("Resolving %s failed after %s: %s", host, duration, err)
+		return duration, err
+	}
+	trace.Printf("Resolved %s to %v in %s", host, addrs, duration)
+	return duration, nil
+}
+
+// probeGatherer adds the probe_* metrics of the blackbox_exporter to the
+// metrics of the target. The probe succeeds if the target is up and its
+// host name was resolved.
+func probeGatherer(g prometheus.Gatherer, start time.Time, dnsLookup time.Duration, dnsErr error) prometheus.Gatherer {
+	return prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+		families, err := g.Gather()
+		success := 0.0
+		for _, mf := range families {
+			if mf.GetName() == "mysql_up" && len(mf.Metric) > 0 && mf.Metric[0].GetGauge().GetValue() == 1 && dnsErr == nil {
+				success = 1
+			}
+		}
+
+		registry := prometheus.NewRegistry()
+		registry.MustRegister(
+			prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+				Name: "probe_success",
+				Help: "Whether the probe of the target succeeded.",
+			}, func() float64 { return success }),
+			prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+				Name: "probe_duration_seconds",
+				Help: "How long the probe of the target took to complete in seconds.",
+			}, func() float64 { return time.Since(start).Seconds() }),
+			prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+				Name: "probe_dns_lookup_time_seconds",
+				Help: "How long the resolution of the host name of the target took in seconds.",
+			}, func() float64 { return dnsLookup.Seconds() }),
+		)
+		probeFamilies, probeErr := registry.Gather()
+		// The gathered families may be shared, e.g. by background scrapes.
+		families = slices.Concat(families, probeFamilies)
+		slices.SortFunc(families, func(a, b *dto.MetricFamily) int { return strings.Compare(a.GetName(), b.GetName()) })
+		return families, errors.Join(err, probeErr)
+	})
+}
+
+// redactDSN returns the DSN with its password redacted.
+func redactDSN(dsn string) string {
+	cfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return "<invalid DSN>"
+	}
+	if cfg.Passwd != "" {
+		cfg.Passwd = "<secret>"
+	}
+	return cfg.FormatDSN()
+}
+
+// writeProbeDebug writes the trace of a probe and the metrics it gathered
+// in plain text.
+func writeProbeDebug(w http.ResponseWriter, trace *bytes.Buffer, families []*dto.MetricFamily) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintf(w, "Trace:\n%s\nMetrics:\n", trace)
+	for _, mf := range families {
+		if _, err := expfmt.MetricFamilyToText(w, mf); err != nil {
+			fmt.Fprintf(w, "# Error writing %s: %s\n", mf.GetName(), err)
+		}
+	}
+}
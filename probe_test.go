@@ -0,0 +1,205 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/common/promslog"
+
+	"github.com/prometheus/mysqld_exporter/collector"
+	"github.com/prometheus/mysqld_exporter/config"
+)
+
+func TestParseOptionParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		want    map[string]string
+		wantErr bool
+	}{
+		{name: "none", query: "target=db1:3306&collect[]=global_status", want: map[string]string{}},
+		{
+			name:  "options",
+			query: "target=db1:3306&perf_schema.eventsstatements.limit=50&heartbeat.utc=true",
+			want:  map[string]string{"perf_schema.eventsstatements.limit": "50", "heartbeat.utc": "true"},
+		},
+		{name: "invalid number", query: "perf_schema.eventsstatements.limit=many", wantErr: true},
+		{name: "negative number", query: "perf_schema.eventsstatements.limit=-1", wantErr: true},
+		{name: "invalid bool", query: "heartbeat.utc=maybe", wantErr: true},
+		{name: "table", query: "heartbeat.table=heartbeat", wantErr: true},
+		{name: "databases", query: "info_schema.tables.databases=shop", wantErr: true},
+		{name: "schemas", query: "perf_schema.eventsstatements.exclude_schemas=sys", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := parseOptionParams(params)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseOptionParams() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, got); !tt.wantErr && diff != "" {
+				t.Fatalf("parseOptionParams() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestHandleProbeOptionParams(t *testing.T) {
+	rec := httptest.NewRecorder()
+	handleProbe(nil, promslog.NewNopLogger())(rec, httptest.NewRequest("GET", "http://exporter:9104/probe?target=db1:3306&heartbeat.table=x%60%3BDROP%20TABLE%20y%3B--", nil))
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), `option "heartbeat.table" can not be set by request parameters`) {
+		t.Fatalf("unexpected response %d %q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestHandleProbeMatchTarget(t *testing.T) {
+	c.Config = &config.Config{Sections: map[string]config.MySqlConfig{
+		"client":    {User: "root"},
+		"client.eu": {User: "eu", MatchTarget: "*.prod-eu.db"},
+		"client.dc": {User: "dc", MatchTarget: "db1.*"},
+	}}
+	defer func() { c.Config = &config.Config{} }()
+
+	for query, want := range map[string]string{
+		"target=db2.prod-us.db:3306": "does not match the match-target of any config section",
+		"target=db1.prod-eu.db:3306": "matches the match-target of several config sections [client.dc], [client.eu]",
+	} {
+		rec := httptest.NewRecorder()
+		handleProbe(nil, promslog.NewNopLogger())(rec, httptest.NewRequest("GET", "http://exporter:9104/probe?"+query, nil))
+		if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), want) {
+			t.Fatalf("%s: unexpected response %d %q", query, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestHandleProbeTargetAccess(t *testing.T) {
+	c.Config = &config.Config{Sections: map[string]config.MySqlConfig{
+		"client":        {User: "root"},
+		"client.orders": {User: "orders", AllowTarget: "*.orders.db"},
+		"client.net":    {User: "net", AllowTarget: "192.0.2.0/24"},
+	}}
+	exporterConfig.Config = &config.ExporterConfig{
+		TargetAccess: config.TargetAccess{Deny: []string{"*.attacker.example"}},
+		Targets:      []config.Target{{Name: "shop", Address: "db1.shop.db:3306", AuthModule: "client"}},
+	}
+	defer func() {
+		c.Config = &config.Config{}
+		exporterConfig.Config = &config.ExporterConfig{}
+	}()
+
+	for _, tc := range []struct {
+		handler http.HandlerFunc
+		query   string
+		reason  string
+	}{
+		{handleProbe(nil, promslog.NewNopLogger()), "target=db1.attacker.example:3306", config.TargetDenied},
+		{handleProbe(nil, promslog.NewNopLogger()), "target=db1.shop.db:3306&auth_module=client.orders", config.TargetNotAllowedAuthModule},
+		{handleProbe(nil, promslog.NewNopLogger()), "target=db1.invalid:3306&auth_module=client.net", config.TargetUnresolved},
+		// Named targets are checked with the auth modules of other targets.
+		{handleProbe(nil, promslog.NewNopLogger()), "target=shop&auth_module=client.orders", config.TargetNotAllowedAuthModule},
+		{newHandler(nil, promslog.NewNopLogger()), "target=db1.attacker.example:3306", config.TargetDenied},
+	} {
+		before := testutil.ToFloat64(targetRejections.WithLabelValues(tc.reason))
+		rec := httptest.NewRecorder()
+		tc.handler(rec, httptest.NewRequest("GET", "http://exporter:9104/probe?"+tc.query, nil))
+		if rec.Code != http.StatusForbidden {
+			t.Fatalf("%s: unexpected response %d %q", tc.query, rec.Code, rec.Body.String())
+		}
+		if got := testutil.ToFloat64(targetRejections.WithLabelValues(tc.reason)); got != before+1 {
+			t.Fatalf("%s: expected rejection to be counted as %s", tc.query, tc.reason)
+		}
+	}
+}
+
+func TestProbeGatherer(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		up     float64
+		dnsErr error
+		want   float64
+	}{
+		{name: "up", up: 1, want: 1},
+		{name: "down", up: 0, want: 0},
+		{name: "dns failure", up: 1, dnsErr: errors.New("no such host"), want: 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			registry := prometheus.NewRegistry()
+			registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{Name: "mysql_up"}, func() float64 { return tc.up }))
+
+			families, err := probeGatherer(registry, time.Now(), time.Millisecond, tc.dnsErr).Gather()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, mf := range families {
+				names = append(names, mf.GetName())
+				if mf.GetName() == "probe_success" && mf.Metric[0].GetGauge().GetValue() != tc.want {
+					t.Fatalf("expected probe_success %v, got %v", tc.want, mf.Metric[0].GetGauge().GetValue())
+				}
+			}
+			want := []string{"mysql_up", "probe_dns_lookup_time_seconds", "probe_duration_seconds", "probe_success"}
+			if diff := cmp.Diff(want, names); diff != "" {
+				t.Fatalf("probeGatherer() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRedactDSN(t *testing.T) {
+	for dsn, want := range map[string]string{
+		"root:s3cret@tcp(db1:3306)/?tls=skip-verify": "root:<secret>@tcp(db1:3306)/?tls=skip-verify",
+		"root@unix(/run/mysqld/mysqld.sock)/":        "root@unix(/run/mysqld/mysqld.sock)/",
+		"root:s3cret@tcp(db1:3306":                   "<invalid DSN>",
+	} {
+		if got := redactDSN(dsn); got != want {
+			t.Fatalf("redactDSN(%q) = %q, want %q", dsn, got, want)
+		}
+	}
+}
+
+func TestHandleProbeDebug(t *testing.T) {
+	c.Config = &config.Config{Sections: map[string]config.MySqlConfig{
+		"client": {User: "root", Password: "s3cret"},
+	}}
+	defer func() { c.Config = &config.Config{} }()
+
+	rec := httptest.NewRecorder()
+	handleProbe(func() []collector.Scraper { return nil }, promslog.NewNopLogger())(rec, httptest.NewRequest("GET", "http://exporter:9104/probe?target=127.0.0.1:1&debug=true", nil))
+	body := rec.Body.String()
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "text/plain; charset=utf-8" {
+		t.Fatalf("unexpected response %d %q", rec.Code, body)
+	}
+	for _, want := range []string{"Trace:\n", "Probing target 127.0.0.1:1 with auth module [client]", "root:<secret>@tcp(127.0.0.1:1)", "Opening connection failed", "Metrics:\n", "mysql_up 0\n", "probe_success 0\n"} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected %q in response:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, "s3cret") {
+		t.Fatalf("password leaked in response:\n%s", body)
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"log/slog"
+	"maps"
+	"net/http"
+
+	"github.com/prometheus/mysqld_exporter/config"
+)
+
+// sdTargetGroup is a target group of the Prometheus HTTP service discovery.
+type sdTargetGroup struct {
+	Targets []string          `json:"targets"`
+	Labels  map[string]string `json:"labels"`
+}
+
+// sdTargetGroups returns a target group for each configured target, which
+// makes Prometheus scrape the target through the probe endpoint of the
+// exporter at the given address, along with the static labels of the target.
+func sdTargetGroups(cfg *config.ExporterConfig, address string) []sdTargetGroup {
+	groups := make([]sdTargetGroup, 0, len(cfg.Targets))
+	for _, t := range cfg.Targets {
+		labels := maps.Clone(t.Labels)
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		labels["instance"] = t.Name
+		labels["__metrics_path__"] = "/probe"
+		labels["__param_target"] = t.Name
+		labels["__param_auth_module"] = t.AuthModule
+		if t.Module != "" {
+			labels["__param_module"] = t.Module
+		}
+		groups = append(groups, sdTargetGroup{
+			Targets: []string{address},
+			Labels:  labels,
+		})
+	}
+	return groups
+}
+
+// handleSD serves the configured targets in the format of the Prometheus
+// HTTP service discovery. The targets point to the host of the request.
+func handleSD(logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(sdTargetGroups(exporterConfig.GetConfig(), r.Host)); err != nil {
+			logger.Error("Error encoding service discovery response", "err", err)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/prometheus/common/promslog"
+
+	"github.com/prometheus/mysqld_exporter/config"
+)
+
+func TestHandleSD(t *testing.T) {
+	exporterConfig.Config = &config.ExporterConfig{Targets: []config.Target{
+		{Name: "orders-primary", Address: "db1:3306", AuthModule: "client.orders", Labels: map[string]string{"env": "production"}},
+		{Name: "local", Socket: "/run/mysqld/mysqld.sock", AuthModule: "client", Module: "minimal"},
+	}}
+	defer func() { exporterConfig.Config = &config.ExporterConfig{} }()
+
+	rec := httptest.NewRecorder()
+	handleSD(promslog.NewNopLogger())(rec, httptest.NewRequest("GET", "http://exporter:9104/sd", nil))
+
+	if got := rec.Header().Get("Content-Type"); got != "application/json" {
+		t.Fatalf("unexpected content type %q", got)
+	}
+	want := `[{"targets":["exporter:9104"],"labels":{"__metrics_path__":"/probe","__param_auth_module":"client.orders","__param_target":"orders-primary","env":"production","instance":"orders-primary"}},` +
+		`{"targets":["exporter:9104"],"labels":{"__metrics_path__":"/probe","__param_auth_module":"client","__param_module":"minimal","__param_target":"local","instance":"local"}}]` + "\n"
+	if diff := cmp.Diff(want, rec.Body.String()); diff != "" {
+		t.Fatalf("unexpected response (-want +got):\n%s", diff)
+	}
+}
+
+func TestHandleSDEmpty(t *testing.T) {
+	rec := httptest.NewRecorder()
+	handleSD(promslog.NewNopLogger())(rec, httptest.NewRequest("GET", "http://exporter:9104/sd", nil))
+	if got := rec.Body.String(); got != "[]\n" {
+		t.Fatalf("expected an empty list, got %q", got)
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// labeledGatherer returns a gatherer adding the labels to all metrics of g.
+// Labels already present on a metric keep their value.
+func labeledGatherer(g prometheus.Gatherer, labels map[string]string) prometheus.Gatherer {
+	if len(labels) == 0 {
+		return g
+	}
+	return prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+		families, err := g.Gather()
+		labeled := make([]*dto.MetricFamily, 0, len(families))
+		for _, mf := range families {
+			// The gathered families may be shared, e.g. by background
+			// scrapes, so they are copied instead of modified.
+			metrics := make([]*dto.Metric, 0, len(mf.Metric))
+			for _, m := range mf.Metric {
+				metrics = append(metrics, &dto.Metric{
+					Label:       addLabels(m.Label, labels),
+					Gauge:       m.Gauge,
+					Counter:     m.Counter,
+					Summary:     m.Summary,
+					Untyped:     m.Untyped,
+					Histogram:   m.Histogram,
+					TimestampMs: m.TimestampMs,
+				})
+			}
+			labeled = append(labeled, &dto.MetricFamily{
+				Name:   mf.Name,
+				Help:   mf.Help,
+				Type:   mf.Type,
+				Unit:   mf.Unit,
+				Metric: metrics,
+			})
+		}
+		return labeled, err
+	})
+}
+
+// addLabels returns the label pairs with the labels not among them added,
+// sorted by name.
+func addLabels(pairs []*dto.LabelPair, labels map[string]string) []*dto.LabelPair {
+	result := slices.Clone(pairs)
+	for name, value := range labels {
+		if slices.ContainsFunc(pairs, func(p *dto.LabelPair) bool { return p.GetName() == name }) {
+			continue
+		}
+		result = append(result, &dto.LabelPair{Name: &name, Value: &value})
+	}
+	slices.SortFunc(result, func(a, b *dto.LabelPair) int {
+		return strings.Compare(a.GetName(), b.GetName())
+	})
+	return result
+}
@@ -0,0 +1,53 @@
+// Copyright 2026 The Prometheus Authors
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestLabeledGatherer(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	up := prometheus.NewGauge(prometheus.GaugeOpts{Name: "mysql_up", Help: "Whether the MySQL server is up."})
+	up.Set(1)
+	info := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "mysql_custom_info", Help: "Custom info."}, []string{"env", "version"})
+	info.WithLabelValues("staging", "8.0.36").Set(1)
+	registry.MustRegister(up, info)
+
+	g := labeledGatherer(registry, map[string]string{"env": "production", "cluster": "orders"})
+	expected := `
+# HELP mysql_custom_info Custom info.
+# TYPE mysql_custom_info gauge
+mysql_custom_info{cluster="orders",env="staging",version="8.0.36"} 1
+# HELP mysql_up Whether the MySQL server is up.
+# TYPE mysql_up gauge
+mysql_up{cluster="orders",env="production"} 1
+`
+	if err := testutil.GatherAndCompare(g, strings.NewReader(expected)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Gathering again does not add the labels twice.
+	if err := testutil.GatherAndCompare(g, strings.NewReader(expected)); err != nil {
+		t.Fatal(err)
+	}
+
+	if labeledGatherer(registry, nil) != prometheus.Gatherer(registry) {
+		t.Fatal("expected the gatherer to be returned unchanged without labels")
+	}
+}